@@ -0,0 +1,24 @@
+package ioc
+
+import (
+	"github.com/IBM/sarama"
+	"github.com/to404hanga/online_judge_controller/constants"
+	"github.com/to404hanga/online_judge_controller/event"
+	"github.com/to404hanga/online_judge_controller/service"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+func InitConsumers(client sarama.Client, submissionSvc service.SubmissionService, rankingSvc service.RankingService, l loggerv2.Logger) []event.Consumer {
+	group, err := sarama.NewConsumerGroupFromClient(constants.ControllerConsumerGroup, client)
+	if err != nil {
+		panic(err)
+	}
+	rankingGroup, err := sarama.NewConsumerGroupFromClient(constants.ControllerRankingConsumerGroup, client)
+	if err != nil {
+		panic(err)
+	}
+	return []event.Consumer{
+		event.NewSaramaConsumer(group, []string{constants.JudgeReportTopic}, submissionSvc.HandleJudgeReportMessage, l),
+		event.NewSaramaConsumer(rankingGroup, []string{constants.JudgeReportTopic}, rankingSvc.HandleJudgeReportMessage, l),
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"log"
 	"net"
 	"os"
+	"time"
 
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 	"github.com/to404hanga/online_judge_controller/config"
+	"github.com/to404hanga/online_judge_controller/event"
 	"github.com/to404hanga/online_judge_controller/pkg/gintool"
 	"github.com/to404hanga/online_judge_controller/web"
 	"github.com/to404hanga/online_judge_controller/web/jwt"
@@ -19,7 +22,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func InitGinServer(l loggerv2.Logger, jwtHandler jwt.Handler, db *gorm.DB, competitionHandler *web.CompetitionHandler, problemHandler *web.ProblemHandler, submissionHandler *web.SubmissionHandler, healthHandler *web.HealthHandler, userHandler *web.UserHandler) *web.GinServer {
+func InitGinServer(l loggerv2.Logger, jwtHandler jwt.Handler, db *gorm.DB, rdb redis.Cmdable, competitionHandler *web.CompetitionHandler, problemHandler *web.ProblemHandler, submissionHandler *web.SubmissionHandler, healthHandler *web.HealthHandler, userHandler *web.UserHandler, practiceHandler *web.PracticeHandler, consumers []event.Consumer) *web.GinServer {
 	var cfg config.GinConfig
 	err := viper.UnmarshalKey(cfg.Key(), &cfg)
 	if err != nil {
@@ -32,6 +35,8 @@ func InitGinServer(l loggerv2.Logger, jwtHandler jwt.Handler, db *gorm.DB, compe
 		addr = addrEnv
 	}
 
+	gintool.SetIdempotencyStore(rdb, time.Duration(cfg.IdempotencyTTL)*time.Minute)
+
 	jwtBuilder := middleware.NewJWTMiddlewareBuilder(jwtHandler, db, l, cfg.CheckCompetitionPath)
 
 	engine := gin.Default()
@@ -47,10 +52,12 @@ func InitGinServer(l loggerv2.Logger, jwtHandler jwt.Handler, db *gorm.DB, compe
 	submissionHandler.Register(engine)
 	healthHandler.Register(engine)
 	userHandler.Register(engine)
+	practiceHandler.Register(engine)
 
 	return &web.GinServer{
-		Engine: engine,
-		Addr:   addr,
+		Engine:    engine,
+		Addr:      addr,
+		Consumers: consumers,
 	}
 }
 
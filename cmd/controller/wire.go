@@ -26,7 +26,12 @@ func BuildDependency() *web.GinServer {
 		service.NewUserService,
 		service.NewProblemService,
 		service.NewSubmissionService,
+		service.NewCompetitionLifecycleService,
+		service.NewCompetitionTemplateService,
 		ioc.InitRankingService,
+		service.NewPlagiarismService,
+		service.NewLanguageService,
+		service.NewPracticeService,
 
 		web.NewCompetitionHandler,
 		web.NewHealthHandler,
@@ -35,7 +40,9 @@ func BuildDependency() *web.GinServer {
 		// commonioc.InitSubmissionHandler,
 		web.NewSubmissionHandler,
 		web.NewUserHandler,
+		web.NewPracticeHandler,
 
+		ioc.InitConsumers,
 		ioc.InitGinServer,
 	)
 	return &web.GinServer{}
@@ -24,16 +24,23 @@ func BuildDependency() *web.GinServer {
 	competitionService := service.NewCompetitionService(db, cmdable, logger)
 	rankingService := ioc2.InitRankingService(db, cmdable, logger)
 	userService := service.NewUserService(db, cmdable, logger)
-	competitionHandler := web.NewCompetitionHandler(competitionService, rankingService, userService, handler, logger)
+	competitionLifecycleService := service.NewCompetitionLifecycleService(db, cmdable, competitionService, rankingService, logger)
+	competitionTemplateService := service.NewCompetitionTemplateService(db, cmdable, logger)
+	plagiarismService := service.NewPlagiarismService(db, rankingService, logger)
+	competitionHandler := web.NewCompetitionHandler(competitionService, competitionLifecycleService, competitionTemplateService, rankingService, plagiarismService, userService, handler, logger)
 	problemService := service.NewProblemService(db, cmdable, logger)
 	problemHandler := web.NewProblemHandler(problemService, userService, logger)
 	client := ioc.InitKafka()
 	syncProducer := ioc.InitSyncProducer(client)
 	producer := event.NewSaramaProducer(syncProducer)
 	submissionService := service.NewSubmissionService(db, cmdable, producer, logger)
-	submissionHandler := web.NewSubmissionHandler(submissionService, competitionService, logger)
+	languageService := service.NewLanguageService(db, cmdable, logger)
+	submissionHandler := web.NewSubmissionHandler(submissionService, competitionService, competitionLifecycleService, languageService, logger)
 	healthHandler := web.NewHealthHandler(logger)
 	userHandler := web.NewUserHandler(logger, userService, competitionService)
-	ginServer := ioc2.InitGinServer(logger, handler, db, competitionHandler, problemHandler, submissionHandler, healthHandler, userHandler)
+	practiceService := service.NewPracticeService(db, cmdable, logger)
+	practiceHandler := web.NewPracticeHandler(practiceService, submissionService, languageService, logger)
+	v := ioc2.InitConsumers(client, submissionService, rankingService, logger)
+	ginServer := ioc2.InitGinServer(logger, handler, db, cmdable, competitionHandler, problemHandler, submissionHandler, healthHandler, userHandler, practiceHandler, v)
 	return ginServer
 }
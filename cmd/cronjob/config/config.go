@@ -15,3 +15,14 @@ type SubmissionCleanerConfig struct {
 func (SubmissionCleanerConfig) Key() string {
 	return "submissionCleaner"
 }
+
+type PlagiarismDetectorConfig struct {
+	BaseCronJobConfig `yaml:",inline" mapstructure:",squash"`
+
+	TimeRange int     `yaml:"timeRange" mapstructure:"timeRange"` // 单位: 小时, 查重在该时间范围内结束的比赛
+	Threshold float64 `yaml:"threshold" mapstructure:"threshold"` // 相似度阈值, 为 0 时使用默认值
+}
+
+func (PlagiarismDetectorConfig) Key() string {
+	return "plagiarismDetector"
+}
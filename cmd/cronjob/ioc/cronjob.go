@@ -6,12 +6,15 @@ import (
 	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
 )
 
-func InitScheduler(l loggerv2.Logger, problemSvc service.ProblemService, submissionSvc service.SubmissionService) *job.CronScheduler {
+func InitScheduler(l loggerv2.Logger, problemSvc service.ProblemService, submissionSvc service.SubmissionService, plagiarismSvc service.PlagiarismService) *job.CronScheduler {
 	scheduler := job.NewCronScheduler(l)
 
 	if err := scheduler.AddJob(InitSubmissionCleaner(submissionSvc, l)); err != nil {
 		panic(err)
 	}
+	if err := scheduler.AddJob(InitPlagiarismDetector(plagiarismSvc, l)); err != nil {
+		panic(err)
+	}
 
 	return scheduler
 }
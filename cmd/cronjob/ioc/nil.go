@@ -3,6 +3,7 @@ package ioc
 import (
 	"github.com/redis/go-redis/v9"
 	"github.com/to404hanga/online_judge_controller/event"
+	"github.com/to404hanga/online_judge_controller/service"
 )
 
 func InitNilRedis() redis.Cmdable {
@@ -12,3 +13,8 @@ func InitNilRedis() redis.Cmdable {
 func InitNilKafka() event.Producer {
 	return nil
 }
+
+// InitNilRankingService 定时任务不修改排行榜, 查重服务中依赖排行榜的取消资格功能只在控制器中使用
+func InitNilRankingService() service.RankingService {
+	return nil
+}
@@ -0,0 +1,36 @@
+package ioc
+
+import (
+	"log"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/to404hanga/online_judge_controller/cmd/cronjob/config"
+	"github.com/to404hanga/online_judge_controller/job"
+	"github.com/to404hanga/online_judge_controller/job/plagiarism"
+	"github.com/to404hanga/online_judge_controller/service"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+func InitPlagiarismDetector(plagiarismSvc service.PlagiarismService, l loggerv2.Logger) *job.JobConfig {
+	var cfg config.PlagiarismDetectorConfig
+	err := viper.UnmarshalKey(cfg.Key(), &cfg)
+	if err != nil {
+		log.Panicf("unmarshal plagiarism detector config fail, err: %v", err)
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = service.DefaultPlagiarismThreshold
+	}
+	log.Printf("plagiarismDetector config loaded: cronExpr=%q enabled=%v timeout_ms=%d timeRange_hours=%d threshold=%.2f", cfg.CronExpr, cfg.Enabled, cfg.Timeout, cfg.TimeRange, cfg.Threshold)
+
+	d := plagiarism.NewPlagiarismDetector(plagiarismSvc, l, time.Duration(cfg.TimeRange)*time.Hour, cfg.Threshold)
+	jbCfg := &job.JobConfig{
+		Name:        "代码查重",
+		CronExpr:    cfg.CronExpr,
+		JobFunc:     d.RunDetection,
+		Description: "对最近结束的比赛执行代码查重",
+		Enabled:     cfg.Enabled,
+		Timeout:     time.Duration(cfg.Timeout) * time.Millisecond,
+	}
+	return jbCfg
+}
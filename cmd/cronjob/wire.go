@@ -16,8 +16,10 @@ func InitScheduler() *job.CronScheduler {
 		commonioc.InitLogger,
 		ioc.InitNilRedis,
 		ioc.InitNilKafka,
+		ioc.InitNilRankingService,
 		service.NewProblemService,
 		service.NewSubmissionService,
+		service.NewPlagiarismService,
 		ioc.InitScheduler,
 	)
 	return &job.CronScheduler{}
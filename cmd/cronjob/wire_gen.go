@@ -22,6 +22,8 @@ func InitScheduler() *job.CronScheduler {
 	problemService := service.NewProblemService(db, cmdable, logger)
 	producer := ioc2.InitNilKafka()
 	submissionService := service.NewSubmissionService(db, cmdable, producer, logger)
-	cronScheduler := ioc2.InitScheduler(logger, problemService, submissionService)
+	rankingService := ioc2.InitNilRankingService()
+	plagiarismService := service.NewPlagiarismService(db, rankingService, logger)
+	cronScheduler := ioc2.InitScheduler(logger, problemService, submissionService, plagiarismService)
 	return cronScheduler
 }
@@ -18,6 +18,7 @@ func (LoggerConfig) Key() string {
 type GinConfig struct {
 	Addr                 string   `yaml:"addr"`                 // 服务地址
 	CheckCompetitionPath []string `yaml:"checkCompetitionPath"` // 需要检查比赛 token 的路径
+	IdempotencyTTL       int      `yaml:"idempotencyTTL"`       // Idempotency-Key 的有效期, 单位: 分钟, 0 表示使用默认值
 }
 
 func (GinConfig) Key() string {
@@ -19,9 +19,35 @@ const (
 	RemoveCompetitionProblemPath            = "/RemoveCompetitionProblem"            // 删除比赛题目
 	EnableCompetitionProblemPath            = "/EnableCompetitionProblem"            // 启用比赛题目
 	DisableCompetitionProblemPath           = "/DisableCompetitionProblem"           // 禁用比赛题目
+	ReorderCompetitionProblemPath           = "/ReorderCompetitionProblem"           // 调整比赛题目顺序
+	DisqualifyCompetitionUserPath           = "/DisqualifyCompetitionUser"           // 取消选手比赛资格
+	ReinstateCompetitionUserPath            = "/ReinstateCompetitionUser"            // 恢复选手比赛资格
+	GetCompetitionDisqualificationListPath  = "/GetCompetitionDisqualificationList"  // 获取比赛取消资格记录
+	OverrideSubmissionResultPath            = "/OverrideSubmissionResult"            // 人工改判提交结果
+	AddCompetitionTimeAdjustmentPath        = "/AddCompetitionTimeAdjustment"        // 调整选手罚时
+	GetCompetitionAdjustmentListPath        = "/GetCompetitionAdjustmentList"        // 获取人工改判与罚时调整记录
+	GetCompetitionRankingHistoryPath        = "/GetCompetitionRankingHistory"        // 获取比赛任意时刻的历史排行榜
+	GetCompetitionRankTrendPath             = "/GetCompetitionRankTrend"             // 获取选手排名变化曲线
+	GetCompetitionRankingDiffPath           = "/GetCompetitionRankingDiff"           // 获取排行榜增量更新
+	RankingEventPath                        = "/RankingEvent"                        // 排行榜增量更新推送
+	CreateCompetitionAnnouncementPath       = "/CreateCompetitionAnnouncement"       // 发布比赛公告
+	GetCompetitionAnnouncementListPath      = "/GetCompetitionAnnouncementList"      // 获取比赛公告列表
+	LiveScoreboardPath                      = "/LiveScoreboard"                      // 实时排行榜 WebSocket
+	GetPublicCompetitionRankingListPath     = "/GetPublicCompetitionRankingList"     // 获取公开排行榜, 无需比赛 token
+	GetCompetitionProblemStatisticsPath     = "/GetCompetitionProblemStatistics"     // 获取比赛各题实时统计
+	UserGetCompetitionProblemStatisticsPath = "/UserGetCompetitionProblemStatistics" // 选手获取比赛各题统计
+	RebuildCompetitionProblemStatisticsPath = "/RebuildCompetitionProblemStatistics" // 重新计算比赛各题统计
+	GetCompetitionFirstBloodListPath        = "/GetCompetitionFirstBloodList"        // 获取比赛各题首个通过者
+	FirstBloodEventPath                     = "/FirstBloodEvent"                     // 首个通过者变化推送
+	CreatePlagiarismTaskPath                = "/CreatePlagiarismTask"                // 创建比赛题目查重任务
+	GetPlagiarismTaskListPath               = "/GetPlagiarismTaskList"               // 获取比赛查重任务列表
+	GetPlagiarismPairListPath               = "/GetPlagiarismPairList"               // 获取查重任务中的可疑代码对
+	GetPlagiarismPairDiffPath               = "/GetPlagiarismPairDiff"               // 获取可疑代码对的左右对照
+	ReviewPlagiarismPairPath                = "/ReviewPlagiarismPair"                // 复核可疑代码对
+	GetCompetitionUpsolveRankingListPath    = "/GetCompetitionUpsolveRankingList"    // 获取包含赛后补题的排行榜
 	StartCompetitionPath                    = "/StartCompetition"                    // 开始比赛
 	GetCompetitionRankingListPath           = "/GetCompetitionRankingList"           // 获取比赛排名列表
-	GetCompetitionFastestSolverListPath     = "/GetCompetitionFastestSolverList"     // 获取比赛各个题目最快通过提交的用户列表
+	GetCompetitionFastestSolverListPath     = "/GetCompetitionFastestSolverList"     // 获取比赛各个题目最快通过提交的用户列表, 已弃用, 请使用 GetCompetitionFirstBloodListPath
 	ExportCompetitionDataPath               = "/ExportCompetitionData"               // 导出比赛数据
 	InitRankingPath                         = "/InitRanking"                         // 初始化比赛排名
 	UpdateScorePath                         = "/UpdateScore"                         // 更新比赛用户分数, 仅内部测试用, 后续 release 版本移除
@@ -33,22 +59,61 @@ const (
 	UserGetCompetitionProblemDetailPath     = "/UserGetCompetitionProblemDetail"     // 用户获取比赛题目详情
 	CheckUserCompetitionProblemAcceptedPath = "/CheckUserCompetitionProblemAccepted" // 检查用户比赛题目是否已通过
 	TimeEventPath                           = "/TimeEvent"                           // 比赛时间事件
+	TransitCompetitionPath                  = "/TransitCompetition"                  // 比赛状态流转
+	GetCompetitionTransitionListPath        = "/GetCompetitionTransitionList"        // 获取比赛状态流转记录
+	CloneCompetitionPath                    = "/CloneCompetition"                    // 复制比赛
+	GetCompetitionSettingPath               = "/GetCompetitionSetting"               // 获取比赛设置
+	UpdateCompetitionSettingPath            = "/UpdateCompetitionSetting"            // 更新比赛设置
+	SaveCompetitionTemplatePath             = "/SaveCompetitionTemplate"             // 将比赛保存为模板
+	GetCompetitionTemplateListPath          = "/GetCompetitionTemplateList"          // 获取比赛模板列表
+	DeleteCompetitionTemplatePath           = "/DeleteCompetitionTemplate"           // 删除比赛模板
+	CreateCompetitionFromTemplatePath       = "/CreateCompetitionFromTemplate"       // 根据模板创建比赛
 )
 
 const (
-	SubmitCompetitionProblemPath = "/SubmitCompetitionProblem" // 提交比赛题目
-	GetLatestSubmissionPath      = "/GetLatestSubmission"      // 获取最新提交
+	SubmitCompetitionProblemPath              = "/SubmitCompetitionProblem"              // 提交比赛题目
+	GetLatestSubmissionPath                   = "/GetLatestSubmission"                   // 获取最新提交
+	GetSubmissionListPath                     = "/GetSubmissionList"                     // 管理员查询提交列表
+	GetSubmissionDetailPath                   = "/GetSubmissionDetail"                   // 管理员查看提交详情
+	DownloadSubmissionCodePath                = "/DownloadSubmissionCode"                // 管理员下载提交源代码
+	UserGetSubmissionListPath                 = "/UserGetSubmissionList"                 // 选手查询自己在比赛中的提交列表
+	UserGetSubmissionDetailPath               = "/UserGetSubmissionDetail"               // 选手查看自己的提交详情
+	GetLanguageListPath                       = "/GetLanguageList"                       // 获取语言注册表
+	SaveLanguagePath                          = "/SaveLanguage"                          // 新增或覆盖语言定义
+	GetCompetitionProblemSubmitSettingPath    = "/GetCompetitionProblemSubmitSetting"    // 获取比赛题目的提交限制
+	UpdateCompetitionProblemSubmitSettingPath = "/UpdateCompetitionProblemSubmitSetting" // 更新比赛题目的提交限制
+	UserGetSubmitLimitPath                    = "/UserGetSubmitLimit"                    // 选手获取题目允许的语言与代码大小上限
+	CreateCustomRunPath                       = "/CreateCustomRun"                       // 选手使用自定义输入运行代码
+	GetCustomRunPath                          = "/GetCustomRun"                          // 选手获取自定义输入运行结果
+	CustomRunEventPath                        = "/CustomRunEvent"                        // 选手订阅自定义输入运行结果
+	GetJudgeReportPath                        = "/GetJudgeReport"                        // 管理员查看提交的判题报告
+	UserGetJudgeReportPath                    = "/UserGetJudgeReport"                    // 选手查看自己提交的判题报告
+	GetSubmissionDiffPath                     = "/GetSubmissionDiff"                     // 管理员对比两次提交的代码
+	UserGetSubmissionDiffPath                 = "/UserGetSubmissionDiff"                 // 选手对比自己两次提交的代码
 )
 
 const (
-	GetUserListPath               = "/GetUserList"               // 获取用户列表
-	DeleteUserPath                = "/DeleteUser"                // 删除用户
-	UpdateUserPath                = "/UpdateUser"                // 更新用户
-	ResetPasswordPath             = "/ResetPassword"             // 重置用户密码
-	UpdatePasswordPath            = "/UpdatePassword"            // 更新用户密码
-	GetCompetitionUserListPath    = "/GetCompetitionUserList"    // 获取比赛用户列表
-	AddUsersToCompetitionPath     = "/AddUsersToCompetition"     // 添加用户到比赛名单
-	EnableUsersInCompetitionPath  = "/EnableUsersInCompetition"  // 允许用户参加比赛
-	DisableUsersInCompetitionPath = "/DisableUsersInCompetition" // 禁用用户参加比赛
-	CreateUserPath                = "/CreateUser"                // 创建用户
+	GetPracticeProblemListPath      = "/GetPracticeProblemList"      // 获取练习题目列表
+	GetPracticeProblemPath          = "/GetPracticeProblem"          // 获取练习题目详情
+	SubmitPracticeProblemPath       = "/SubmitPracticeProblem"       // 提交练习题目
+	GetPracticeSubmissionListPath   = "/GetPracticeSubmissionList"   // 查询自己的练习提交列表
+	GetPracticeSubmissionDetailPath = "/GetPracticeSubmissionDetail" // 查看自己的练习提交详情
+	GetPracticeRankingListPath      = "/GetPracticeRankingList"      // 获取练习通过题目数排行榜
+)
+
+const (
+	GetUserListPath                = "/GetUserList"                // 获取用户列表
+	DeleteUserPath                 = "/DeleteUser"                 // 删除用户
+	UpdateUserPath                 = "/UpdateUser"                 // 更新用户
+	ResetPasswordPath              = "/ResetPassword"              // 重置用户密码
+	UpdatePasswordPath             = "/UpdatePassword"             // 更新用户密码
+	GetCompetitionUserListPath     = "/GetCompetitionUserList"     // 获取比赛用户列表
+	AddUsersToCompetitionPath      = "/AddUsersToCompetition"      // 添加用户到比赛名单
+	EnableUsersInCompetitionPath   = "/EnableUsersInCompetition"   // 允许用户参加比赛
+	DisableUsersInCompetitionPath  = "/DisableUsersInCompetition"  // 禁用用户参加比赛
+	CreateUserPath                 = "/CreateUser"                 // 创建用户
+	SetCompetitionUserCategoryPath = "/SetCompetitionUserCategory" // 设置比赛选手分类
+	UpdateUserNicknamePath         = "/UpdateUserNickname"         // 更新用户昵称
+	GetUserStatisticsPath          = "/GetUserStatistics"          // 管理员获取用户提交统计
+	UserGetStatisticsPath          = "/UserGetStatistics"          // 选手获取自己的提交统计
 )
@@ -1,11 +1,13 @@
 package constants
 
 const (
-	HeaderForwardedByKey      = "X-Forwarded-By"
-	HeaderUserIDKey           = "X-User-ID"
-	HeaderRequestIDKey        = "X-Request-ID"
-	HeaderProxyByKey          = "X-Proxy-By"
-	HeaderCompetitionTokenKey = "X-Competition-JWT-Token"
+	HeaderForwardedByKey        = "X-Forwarded-By"
+	HeaderUserIDKey             = "X-User-ID"
+	HeaderRequestIDKey          = "X-Request-ID"
+	HeaderProxyByKey            = "X-Proxy-By"
+	HeaderCompetitionTokenKey   = "X-Competition-JWT-Token"
+	HeaderIdempotencyKey        = "Idempotency-Key"     // 修改类请求的幂等键
+	HeaderIdempotentReplayedKey = "Idempotent-Replayed" // 响应为重复请求回放的原始响应
 )
 const GatewayServiceName = "OnlineJudge-Controller"
 
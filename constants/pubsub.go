@@ -1,5 +1,10 @@
 package constants
 
 const (
-	RedisPubSubCompetitionEndEventKey = "competition:%d:end:event"
+	RedisPubSubCompetitionEndEventKey          = "competition:%d:end:event"
+	RedisPubSubRankingVersionEventKey          = "ranking:competition:%d:version:event"
+	RedisPubSubCompetitionAnnouncementEventKey = "competition:%d:announcement:event"
 )
+
+// RedisPubSubCustomRunEventKey 判题服务完成自定义输入运行后发布的通知
+const RedisPubSubCustomRunEventKey = "run:%s:event"
@@ -0,0 +1,19 @@
+package constants
+
+// CustomRunTopic 自定义输入运行任务, 与正式提交分开投递, 判题服务以较低优先级消费
+const CustomRunTopic = "custom_run_topic"
+
+// JudgeReportTopic 判题服务在写入判题结果后投递的详细判题报告
+const JudgeReportTopic = "judge_report_topic"
+
+// 提交任务消息头, 携带提交语言的时间与内存限制倍率, 判题服务按题目限制乘以倍率判题
+const (
+	SubmissionTimeMultiplierHeader   = "time_multiplier"
+	SubmissionMemoryMultiplierHeader = "memory_multiplier"
+)
+
+// ControllerConsumerGroup 控制器消费判题服务消息使用的消费组
+const ControllerConsumerGroup = "online_judge_controller"
+
+// ControllerRankingConsumerGroup 控制器按判题报告修正实时排行榜使用的消费组, 与保存判题报告互不阻塞
+const ControllerRankingConsumerGroup = "online_judge_controller_ranking"
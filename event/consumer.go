@@ -0,0 +1,140 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+const (
+	minRetryBackoff = time.Second      // 处理失败或消费出错后的首次重试间隔
+	maxRetryBackoff = 30 * time.Second // 重试间隔上限
+)
+
+// ErrInvalidMessage 消息内容无效, 重试也无法处理, 处理函数返回包装该错误的错误时记录日志并跳过该消息
+var ErrInvalidMessage = errors.New("invalid message")
+
+type Consumer interface {
+	// Start 在后台开始消费, ctx 结束或调用 Close 后停止
+	Start(ctx context.Context) error
+	// Close 停止消费, 等待正在处理的消息完成并提交位移
+	Close() error
+}
+
+// HandlerFunc 处理单条消息, 返回错误时按退避间隔重试, 成功前不提交该消息的位移;
+// 返回 ErrInvalidMessage 时记录日志并跳过该消息, 避免单条异常消息阻塞消费
+type HandlerFunc func(ctx context.Context, msg *sarama.ConsumerMessage) error
+
+type SaramaConsumer struct {
+	group   sarama.ConsumerGroup
+	topics  []string
+	handler HandlerFunc
+	log     loggerv2.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ Consumer = (*SaramaConsumer)(nil)
+
+func NewSaramaConsumer(group sarama.ConsumerGroup, topics []string, handler HandlerFunc, log loggerv2.Logger) *SaramaConsumer {
+	return &SaramaConsumer{
+		group:   group,
+		topics:  topics,
+		handler: handler,
+		log:     log,
+	}
+}
+
+func (c *SaramaConsumer) Start(ctx context.Context) error {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		backoff := minRetryBackoff
+		for {
+			// 发生再均衡时 Consume 返回, 需要重新加入消费组
+			err := c.group.Consume(ctx, c.topics, c)
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) || ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				backoff = minRetryBackoff
+				continue
+			}
+			c.log.ErrorContext(ctx, "SaramaConsumer consume failed", logger.Error(err), logger.String("backoff", backoff.String()))
+			if !sleepContext(ctx, backoff) {
+				return
+			}
+			backoff = min(backoff*2, maxRetryBackoff)
+		}
+	}()
+	return nil
+}
+
+func (c *SaramaConsumer) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+	return c.group.Close()
+}
+
+func (c *SaramaConsumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (c *SaramaConsumer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (c *SaramaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		ctx := loggerv2.ContextWithFields(session.Context(),
+			logger.String("topic", msg.Topic),
+			logger.Int32("partition", msg.Partition),
+			logger.Int64("offset", msg.Offset))
+		if !c.handle(ctx, msg) {
+			// 会话结束时消息仍未处理成功, 不提交位移, 重新加入消费组后从该消息继续消费
+			return nil
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+// handle 处理单条消息直到成功或消息无效, 会话结束时返回 false
+func (c *SaramaConsumer) handle(ctx context.Context, msg *sarama.ConsumerMessage) bool {
+	backoff := minRetryBackoff
+	for {
+		err := c.handler(ctx, msg)
+		if err == nil {
+			return true
+		}
+		if errors.Is(err, ErrInvalidMessage) {
+			c.log.ErrorContext(ctx, "SaramaConsumer skip invalid message", logger.Error(err))
+			return true
+		}
+		c.log.ErrorContext(ctx, "SaramaConsumer handle message failed", logger.Error(err), logger.String("backoff", backoff.String()))
+		if !sleepContext(ctx, backoff) {
+			return false
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// sleepContext 等待给定时间, ctx 提前结束时返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,38 @@
+package plagiarism
+
+import (
+	"context"
+	"time"
+
+	"github.com/to404hanga/online_judge_controller/service"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+type PlagiarismDetector struct {
+	plagiarismSvc service.PlagiarismService
+	log           loggerv2.Logger
+	timeRange     time.Duration
+	threshold     float64
+}
+
+// NewPlagiarismDetector 创建新的代码查重器
+func NewPlagiarismDetector(plagiarismSvc service.PlagiarismService, log loggerv2.Logger, timeRange time.Duration, threshold float64) *PlagiarismDetector {
+	return &PlagiarismDetector{
+		plagiarismSvc: plagiarismSvc,
+		log:           log,
+		timeRange:     timeRange,
+		threshold:     threshold,
+	}
+}
+
+// RunDetection 对最近结束的比赛执行代码查重
+func (d *PlagiarismDetector) RunDetection(ctx context.Context) error {
+	d.log.InfoContext(ctx, "Starting plagiarism detection job")
+
+	if err := d.plagiarismSvc.DetectEndedCompetitions(ctx, time.Now().Add(-d.timeRange), d.threshold); err != nil {
+		return err
+	}
+
+	d.log.InfoContext(ctx, "Plagiarism detection completed")
+	return nil
+}
@@ -81,8 +81,12 @@ func (t ModelExportType) ToFactoryType() factory.ExporterType {
 type ExportCompetitionDataParam struct {
 	CommonParam `json:"-"`
 
-	CompetitionID uint64          `form:"competition_id" binding:"required"`
-	ExportType    ModelExportType `form:"export_type" binding:"required,oneof=1 2 3"`
+	CompetitionID       uint64          `form:"competition_id" binding:"required"`
+	ExportType          ModelExportType `form:"export_type" binding:"required,oneof=1 2 3"`
+	Category            *string         `form:"category" binding:"omitempty,max=32"` // 只导出指定分类的选手, 空字符串表示默认分类
+	SplitByCategory     bool            `form:"split_by_category"`                   // 按分类拆分工作表, 仅支持 XLSX 排名导出
+	IncludeDisqualified bool            `form:"include_disqualified"`                // 导出被取消资格的选手并标记为 DQ
+	ApplyDisplayMode    bool            `form:"apply_display_mode"`                  // 按比赛的选手信息展示模式导出, 用于公示; 默认导出完整信息
 }
 
 type GetCompetitionListParam struct {
@@ -152,8 +156,9 @@ type GetCompetitionParam struct {
 
 type GetCompetitionResponse struct {
 	*ojmodel.Competition `json:",inline"`
-	CreatorRealname      string `json:"creator_realname"`
-	UpdaterRealname      string `json:"updater_realname"`
+	CreatorRealname      string           `json:"creator_realname"`
+	UpdaterRealname      string           `json:"updater_realname"`
+	State                CompetitionState `json:"state"` // 比赛生命周期状态
 }
 
 type UserGetCompetitionProblemListParam struct {
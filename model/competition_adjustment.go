@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+)
+
+// SubmissionVerdictOverride 人工改判记录
+type SubmissionVerdictOverride struct {
+	ID             uint64                   `gorm:"column:id;type:bigint unsigned;primaryKey" json:"id"`                                            // 记录 ID
+	SubmissionID   uint64                   `gorm:"column:submission_id;type:bigint unsigned;index:idx_submission_id" json:"submission_id"`         // 提交 ID
+	CompetitionID  uint64                   `gorm:"column:competition_id;type:bigint unsigned;index:idx_competition_user_id" json:"competition_id"` // 比赛 ID
+	UserID         uint64                   `gorm:"column:user_id;type:bigint unsigned;index:idx_competition_user_id" json:"user_id"`               // 用户 ID
+	ProblemID      uint64                   `gorm:"column:problem_id;type:bigint unsigned" json:"problem_id"`                                       // 题目 ID
+	OriginalResult ojmodel.SubmissionResult `gorm:"column:original_result;type:tinyint" json:"original_result"`                                     // 改判前的判题结果
+	Result         ojmodel.SubmissionResult `gorm:"column:result;type:tinyint" json:"result"`                                                       // 改判后的判题结果
+	Reason         string                   `gorm:"column:reason;type:varchar(255);not null" json:"reason"`                                         // 改判原因
+	OperatorID     uint64                   `gorm:"column:operator_id;type:bigint unsigned" json:"operator_id"`                                     // 操作者 ID
+	CreatedAt      time.Time                `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`                      // 创建时间
+}
+
+func (SubmissionVerdictOverride) TableName() string {
+	return "submission_verdict_override"
+}
+
+// CompetitionTimeAdjustment 选手罚时调整记录, 同一选手的多条记录累加生效
+type CompetitionTimeAdjustment struct {
+	ID            uint64    `gorm:"column:id;type:bigint unsigned;primaryKey" json:"id"`                                            // 记录 ID
+	CompetitionID uint64    `gorm:"column:competition_id;type:bigint unsigned;index:idx_competition_user_id" json:"competition_id"` // 比赛 ID
+	UserID        uint64    `gorm:"column:user_id;type:bigint unsigned;index:idx_competition_user_id" json:"user_id"`               // 用户 ID
+	Minutes       int       `gorm:"column:minutes;type:int;not null" json:"minutes"`                                                // 调整分钟数, 正数为加罚时, 负数为减罚时
+	Reason        string    `gorm:"column:reason;type:varchar(255);not null" json:"reason"`                                         // 调整原因
+	OperatorID    uint64    `gorm:"column:operator_id;type:bigint unsigned" json:"operator_id"`                                     // 操作者 ID
+	CreatedAt     time.Time `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`                      // 创建时间
+}
+
+func (CompetitionTimeAdjustment) TableName() string {
+	return "competition_time_adjustment"
+}
+
+type OverrideSubmissionResultParam struct {
+	CommonParam `json:"-"`
+
+	SubmissionID uint64                    `json:"submission_id" binding:"required"`
+	Result       *ojmodel.SubmissionResult `json:"result" binding:"required,oneof=1 2 3 4 5 6 7"` // 改判后的判题结果
+	Reason       string                    `json:"reason" binding:"required,max=255"`             // 改判原因
+}
+
+type AddCompetitionTimeAdjustmentParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `json:"competition_id" binding:"required"`
+	UserID        uint64 `json:"user_id" binding:"required"`
+	Minutes       int    `json:"minutes" binding:"required,min=-300,max=300"` // 调整分钟数, 正数为加罚时, 负数为减罚时
+	Reason        string `json:"reason" binding:"required,max=255"`           // 调整原因
+}
+
+type GetCompetitionAdjustmentListParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64  `form:"competition_id" binding:"required"`
+	UserID        *uint64 `form:"user_id"` // 只返回指定选手的记录
+}
+
+type GetCompetitionAdjustmentListResponse struct {
+	VerdictOverrides []SubmissionVerdictOverride `json:"verdict_overrides"` // 人工改判记录
+	TimeAdjustments  []CompetitionTimeAdjustment `json:"time_adjustments"`  // 罚时调整记录
+}
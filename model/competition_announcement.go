@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// CompetitionAnnouncement 比赛公告, 发布后通过实时排行榜推送给所有连接
+type CompetitionAnnouncement struct {
+	ID            uint64    `gorm:"column:id;type:bigint unsigned;primaryKey" json:"id"`                       // 公告 ID
+	CompetitionID uint64    `gorm:"column:competition_id;type:bigint unsigned;index" json:"competition_id"`    // 比赛 ID
+	Title         string    `gorm:"column:title;type:varchar(128);not null" json:"title"`                      // 公告标题
+	Content       string    `gorm:"column:content;type:text" json:"content"`                                   // 公告内容
+	CreatorID     uint64    `gorm:"column:creator_id;type:bigint unsigned" json:"creator_id"`                  // 发布者 ID
+	CreatedAt     time.Time `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"` // 创建时间
+}
+
+func (CompetitionAnnouncement) TableName() string {
+	return "competition_announcement"
+}
+
+type CreateCompetitionAnnouncementParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `json:"competition_id" binding:"required"`
+	Title         string `json:"title" binding:"required,max=128"` // 公告标题
+	Content       string `json:"content" binding:"max=4096"`       // 公告内容
+}
+
+type GetCompetitionAnnouncementListParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `form:"competition_id" binding:"required"`
+}
+
+type GetCompetitionAnnouncementListResponse struct {
+	List  []CompetitionAnnouncement `json:"list"`
+	Total int                       `json:"total"`
+}
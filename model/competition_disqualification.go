@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// CompetitionDisqualification 比赛取消资格记录, RevokedAt 为空表示当前仍处于取消资格状态
+type CompetitionDisqualification struct {
+	ID            uint64     `gorm:"column:id;type:bigint unsigned;primaryKey" json:"id"`                                            // 记录 ID
+	CompetitionID uint64     `gorm:"column:competition_id;type:bigint unsigned;index:idx_competition_user_id" json:"competition_id"` // 比赛 ID
+	UserID        uint64     `gorm:"column:user_id;type:bigint unsigned;index:idx_competition_user_id" json:"user_id"`               // 用户 ID
+	Reason        string     `gorm:"column:reason;type:varchar(255);not null" json:"reason"`                                         // 取消资格原因
+	OperatorID    uint64     `gorm:"column:operator_id;type:bigint unsigned" json:"operator_id"`                                     // 操作者 ID
+	RevokedAt     *time.Time `gorm:"column:revoked_at;type:datetime(3)" json:"revoked_at"`                                           // 恢复资格时间
+	RevokerID     uint64     `gorm:"column:revoker_id;type:bigint unsigned" json:"revoker_id"`                                       // 恢复资格的操作者 ID
+	CreatedAt     time.Time  `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`                      // 创建时间
+	UpdatedAt     time.Time  `gorm:"column:updated_at;type:datetime(3);autoUpdateTime:milli" json:"updated_at"`                      // 更新时间
+}
+
+func (CompetitionDisqualification) TableName() string {
+	return "competition_disqualification"
+}
+
+type DisqualifyCompetitionUserParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `json:"competition_id" binding:"required"`
+	UserID        uint64 `json:"user_id" binding:"required"`
+	Reason        string `json:"reason" binding:"required,max=255"` // 取消资格原因
+}
+
+type ReinstateCompetitionUserParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `json:"competition_id" binding:"required"`
+	UserID        uint64 `json:"user_id" binding:"required"`
+}
+
+type GetCompetitionDisqualificationListParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `form:"competition_id" binding:"required"`
+	Active        bool   `form:"active"` // 只返回当前仍处于取消资格状态的记录
+}
+
+type GetCompetitionDisqualificationListResponse struct {
+	List  []CompetitionDisqualification `json:"list"`
+	Total int                           `json:"total"`
+}
@@ -0,0 +1,103 @@
+package model
+
+import "time"
+
+type CompetitionState int8
+
+const (
+	CompetitionStateDraft     CompetitionState = iota // 草稿
+	CompetitionStateScheduled                         // 已排期
+	CompetitionStateRunning                           // 进行中
+	CompetitionStateFrozen                            // 已封榜
+	CompetitionStateEnded                             // 已结束
+	CompetitionStateFinalized                         // 已定榜
+	CompetitionStateArchived                          // 已归档
+)
+
+func (s CompetitionState) Int8() int8 {
+	return int8(s)
+}
+
+func (s CompetitionState) String() string {
+	switch s {
+	case CompetitionStateDraft:
+		return "草稿"
+	case CompetitionStateScheduled:
+		return "已排期"
+	case CompetitionStateRunning:
+		return "进行中"
+	case CompetitionStateFrozen:
+		return "已封榜"
+	case CompetitionStateEnded:
+		return "已结束"
+	case CompetitionStateFinalized:
+		return "已定榜"
+	case CompetitionStateArchived:
+		return "已归档"
+	default:
+		return "未知状态"
+	}
+}
+
+// AcceptsSubmission 比赛状态是否接受正式提交, 管理员提前结束比赛后不再接受正式提交
+func (s CompetitionState) AcceptsSubmission() bool {
+	return s == CompetitionStateRunning || s == CompetitionStateFrozen
+}
+
+// 比赛中可按状态限制修改的字段
+const (
+	CompetitionFieldName      = "name"
+	CompetitionFieldStartTime = "start_time"
+	CompetitionFieldEndTime   = "end_time"
+	CompetitionFieldProblems  = "problems"
+	CompetitionFieldSetting   = "setting"
+)
+
+// CompetitionLifecycle 比赛生命周期, 与 competition 表一对一
+type CompetitionLifecycle struct {
+	CompetitionID uint64           `gorm:"column:competition_id;type:bigint unsigned;primaryKey" json:"competition_id"` // 比赛 ID
+	State         CompetitionState `gorm:"column:state;type:tinyint;not null;default:0" json:"state"`                   // 比赛状态 ( 0: 草稿, 1: 已排期, 2: 进行中, 3: 已封榜, 4: 已结束, 5: 已定榜, 6: 已归档 )
+	FreezeTime    *time.Time       `gorm:"column:freeze_time;type:datetime(3)" json:"freeze_time"`                      // 封榜时间
+	UpdaterID     uint64           `gorm:"column:updater_id;type:bigint unsigned" json:"updater_id"`                    // 更新者 ID, 0 表示系统自动流转
+	CreatedAt     time.Time        `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`   // 创建时间
+	UpdatedAt     time.Time        `gorm:"column:updated_at;type:datetime(3);autoUpdateTime:milli" json:"updated_at"`   // 更新时间
+}
+
+func (CompetitionLifecycle) TableName() string {
+	return "competition_lifecycle"
+}
+
+// CompetitionTransition 比赛状态流转记录
+type CompetitionTransition struct {
+	ID            uint64           `gorm:"column:id;type:bigint unsigned;primaryKey" json:"id"`                                       // 流转记录 ID
+	CompetitionID uint64           `gorm:"column:competition_id;type:bigint unsigned;index:idx_competition_id" json:"competition_id"` // 比赛 ID
+	FromState     CompetitionState `gorm:"column:from_state;type:tinyint" json:"from_state"`                                          // 流转前状态
+	ToState       CompetitionState `gorm:"column:to_state;type:tinyint" json:"to_state"`                                              // 流转后状态
+	OperatorID    uint64           `gorm:"column:operator_id;type:bigint unsigned" json:"operator_id"`                                // 操作者 ID, 0 表示系统自动流转
+	Reason        string           `gorm:"column:reason;type:varchar(255)" json:"reason"`                                             // 流转原因
+	CreatedAt     time.Time        `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`                 // 创建时间
+}
+
+func (CompetitionTransition) TableName() string {
+	return "competition_transition"
+}
+
+type TransitCompetitionParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64            `json:"competition_id" binding:"required"`
+	State         *CompetitionState `json:"state" binding:"required,oneof=0 1 2 3 4 5 6"` // 目标状态
+	Reason        string            `json:"reason" binding:"max=255"`                     // 流转原因
+}
+
+type GetCompetitionTransitionListParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `form:"competition_id" binding:"required"`
+}
+
+type GetCompetitionTransitionListResponse struct {
+	State CompetitionState        `json:"state"`
+	List  []CompetitionTransition `json:"list"`
+	Total int                     `json:"total"`
+}
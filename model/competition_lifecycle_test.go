@@ -0,0 +1,25 @@
+package model
+
+import "testing"
+
+func TestCompetitionStateAcceptsSubmission(t *testing.T) {
+	tests := []struct {
+		state CompetitionState
+		want  bool
+	}{
+		{CompetitionStateDraft, false},
+		{CompetitionStateScheduled, false},
+		{CompetitionStateRunning, true},
+		{CompetitionStateFrozen, true},
+		{CompetitionStateEnded, false},
+		{CompetitionStateFinalized, false},
+		{CompetitionStateArchived, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.state.String(), func(t *testing.T) {
+			if got := tt.state.AcceptsSubmission(); got != tt.want {
+				t.Errorf("AcceptsSubmission() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
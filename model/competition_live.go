@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+type LiveScoreboardMode string
+
+const (
+	LiveScoreboardModeContestant LiveScoreboardMode = "contestant" // 携带比赛 token 的选手连接
+	LiveScoreboardModePublic     LiveScoreboardMode = "public"     // 未登录的只读连接, 需要比赛开启公开排行榜
+)
+
+type LiveScoreboardMessageType string
+
+const (
+	LiveScoreboardMessageHello        LiveScoreboardMessageType = "hello"        // 连接建立后的第一条消息
+	LiveScoreboardMessageRanking      LiveScoreboardMessageType = "ranking"      // 排行榜差异, 数据为 RankingDiff
+	LiveScoreboardMessageAnnouncement LiveScoreboardMessageType = "announcement" // 比赛公告, 数据为 CompetitionAnnouncement
+	LiveScoreboardMessageClock        LiveScoreboardMessageType = "clock"        // 比赛时钟, 数据为 LiveClock
+	LiveScoreboardMessageClose        LiveScoreboardMessageType = "close"        // 服务端即将关闭连接
+)
+
+type LiveScoreboardParam struct {
+	CompetitionID uint64 `form:"competition_id" binding:"required"`
+	Version       int64  `form:"version" binding:"min=0"` // 客户端已有的排行榜版本, 为 0 时推送完整排行榜
+	Token         string `form:"token"`                   // 比赛 token, 浏览器无法为 WebSocket 设置请求头时使用
+}
+
+// LiveScoreboardMessage WebSocket 推送的消息信封
+type LiveScoreboardMessage struct {
+	Type LiveScoreboardMessageType `json:"type"`
+	Data any                       `json:"data,omitempty"`
+}
+
+// LiveScoreboardHello 连接建立后告知客户端连接模式
+type LiveScoreboardHello struct {
+	CompetitionID uint64             `json:"competition_id"`
+	Mode          LiveScoreboardMode `json:"mode"`
+	UserID        uint64             `json:"user_id,omitempty"` // 选手连接时为当前选手 ID, 用于高亮自己所在的行
+}
+
+// LiveClock 比赛时钟, 客户端以 ServerTime 校准本地时间后自行倒计时
+type LiveClock struct {
+	ServerTime time.Time        `json:"server_time"`
+	StartTime  time.Time        `json:"start_time"`
+	EndTime    time.Time        `json:"end_time"`
+	State      CompetitionState `json:"state"`
+	Remaining  int64            `json:"remaining"` // 距离比赛结束的秒数, 已结束时为 0
+}
@@ -0,0 +1,40 @@
+package model
+
+import ojmodel "github.com/to404hanga/online_judge_common/model"
+
+// CompetitionProblemLabel 比赛题目的顺序与字母标号, 与 competition_problem 表一对一
+type CompetitionProblemLabel struct {
+	ID            uint64 `gorm:"column:id;type:bigint unsigned;primaryKey" json:"id"`                                                 // 记录 ID
+	CompetitionID uint64 `gorm:"column:competition_id;type:bigint unsigned;uniqueIndex:uk_competition_problem" json:"competition_id"` // 比赛 ID
+	ProblemID     uint64 `gorm:"column:problem_id;type:bigint unsigned;uniqueIndex:uk_competition_problem" json:"problem_id"`         // 题目 ID
+	Position      int    `gorm:"column:position;type:int;not null" json:"position"`                                                   // 题目顺序, 从 0 开始
+	Label         string `gorm:"column:label;type:varchar(8);not null" json:"label"`                                                  // 题目标号 ( A, B, ..., Z, AA, ... )
+}
+
+func (CompetitionProblemLabel) TableName() string {
+	return "competition_problem_label"
+}
+
+// ProblemLabel 根据题目顺序生成字母标号, 0 -> A, 25 -> Z, 26 -> AA
+func ProblemLabel(position int) string {
+	label := ""
+	for position >= 0 {
+		label = string(rune('A'+position%26)) + label
+		position = position/26 - 1
+	}
+	return label
+}
+
+// CompetitionProblemItem 带标号的比赛题目
+type CompetitionProblemItem struct {
+	ojmodel.CompetitionProblem `json:",inline"`
+	Position                   int    `json:"position"` // 题目顺序
+	Label                      string `json:"label"`    // 题目标号
+}
+
+type ReorderCompetitionProblemParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64   `json:"competition_id" binding:"required"`
+	ProblemIDs    []uint64 `json:"problem_ids" binding:"required,min=1"` // 按新顺序排列的全部比赛题目 ID
+}
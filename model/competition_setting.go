@@ -0,0 +1,121 @@
+package model
+
+import (
+	"time"
+
+	"github.com/to404hanga/online_judge_controller/pkg/privacy"
+)
+
+const (
+	DefaultPenaltyMinutes = 20 // 默认罚时, 单位: 分钟
+	DefaultRunInterval    = 5  // 默认自定义输入运行的最小间隔, 单位: 秒
+	DefaultRunWindow      = 10 // 默认自定义输入运行次数限制的统计窗口, 单位: 分钟
+	DefaultRunLimit       = 30 // 默认统计窗口内的最大运行次数
+
+	DefaultPracticeSubmitInterval     = 5  // 练习中同一题目两次提交的默认最小间隔, 单位: 秒
+	DefaultPracticeSubmitWindow       = 10 // 练习提交次数限制的默认统计窗口, 单位: 分钟
+	DefaultPracticeUserSubmitLimit    = 60 // 默认统计窗口内练习全部题目的最大提交次数
+	DefaultPracticeProblemSubmitLimit = 20 // 默认统计窗口内练习同一题目的最大提交次数
+)
+
+// CompetitionSetting 比赛设置, 与 competition 表一对一, 无记录时使用默认设置
+type CompetitionSetting struct {
+	CompetitionID         uint64                `gorm:"column:competition_id;type:bigint unsigned;primaryKey" json:"competition_id"`                   // 比赛 ID
+	PenaltyMinutes        int                   `gorm:"column:penalty_minutes;type:int;not null;default:20" json:"penalty_minutes"`                    // 每次错误提交的罚时 ( 单位: 分钟 )
+	PublicScoreboard      bool                  `gorm:"column:public_scoreboard;type:tinyint(1);not null;default:0" json:"public_scoreboard"`          // 是否开放无需比赛 token 的公开排行榜
+	PublicAnonymous       bool                  `gorm:"column:public_anonymous;type:tinyint(1);not null;default:0" json:"public_anonymous"`            // 公开排行榜是否隐藏选手学号与姓名
+	DisplayMode           privacy.DisplayMode   `gorm:"column:display_mode;type:tinyint;not null;default:0" json:"display_mode"`                       // 选手信息展示模式 ( 0: 学号与姓名, 1: 昵称, 2: 学号脱敏, 3: 匿名 )
+	Languages             []int8                `gorm:"column:languages;type:json;serializer:json" json:"languages"`                                   // 允许的语言编号, 为空表示允许注册表中全部启用的语言
+	MaxCodeSize           int                   `gorm:"column:max_code_size;type:int;not null;default:0" json:"max_code_size"`                         // 源代码大小上限 ( 单位: 字节, 0 表示使用默认上限 )
+	SubmitInterval        int                   `gorm:"column:submit_interval;type:int;not null;default:0" json:"submit_interval"`                     // 同一选手同一题目两次提交的最小间隔 ( 单位: 秒, 0 表示不限制 )
+	SubmitWindow          int                   `gorm:"column:submit_window;type:int;not null;default:0" json:"submit_window"`                         // 提交次数限制的统计窗口 ( 单位: 分钟, 0 表示不限制 )
+	UserSubmitLimit       int                   `gorm:"column:user_submit_limit;type:int;not null;default:0" json:"user_submit_limit"`                 // 统计窗口内同一选手全部题目的最大提交次数 ( 0 表示不限制 )
+	ProblemSubmitLimit    int                   `gorm:"column:problem_submit_limit;type:int;not null;default:0" json:"problem_submit_limit"`           // 统计窗口内同一选手同一题目的最大提交次数 ( 0 表示不限制 )
+	RunInterval           int                   `gorm:"column:run_interval;type:int;not null;default:5" json:"run_interval"`                           // 同一选手两次自定义输入运行的最小间隔 ( 单位: 秒, 0 表示不限制 )
+	RunWindow             int                   `gorm:"column:run_window;type:int;not null;default:10" json:"run_window"`                              // 自定义输入运行次数限制的统计窗口 ( 单位: 分钟, 0 表示不限制 )
+	RunLimit              int                   `gorm:"column:run_limit;type:int;not null;default:30" json:"run_limit"`                                // 统计窗口内同一选手的最大运行次数 ( 0 表示不限制 )
+	DuplicateSubmitMode   DuplicateSubmitMode   `gorm:"column:duplicate_submit_mode;type:tinyint;not null;default:0" json:"duplicate_submit_mode"`     // 重复提交相同代码时的处理方式 ( 0: 不检查, 1: 提示, 2: 拒绝 )
+	JudgeReportVisibility JudgeReportVisibility `gorm:"column:judge_report_visibility;type:tinyint;not null;default:0" json:"judge_report_visibility"` // 选手可查看的判题报告范围 ( 0: 不可查看, 1: 仅样例, 2: 比赛结束前仅样例, 3: 全部 )
+	AllowUpsolve          bool                  `gorm:"column:allow_upsolve;type:tinyint(1);not null;default:0" json:"allow_upsolve"`                  // 比赛结束后是否允许选手继续提交补题, 补题提交不计入正式排行榜
+	UpdaterID             uint64                `gorm:"column:updater_id;type:bigint unsigned" json:"updater_id"`                                      // 更新者 ID
+	CreatedAt             time.Time             `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`                     // 创建时间
+	UpdatedAt             time.Time             `gorm:"column:updated_at;type:datetime(3);autoUpdateTime:milli" json:"updated_at"`                     // 更新时间
+}
+
+func (CompetitionSetting) TableName() string {
+	return "competition_setting"
+}
+
+// DefaultCompetitionSetting 返回比赛的默认设置
+func DefaultCompetitionSetting(competitionID uint64) *CompetitionSetting {
+	return &CompetitionSetting{
+		CompetitionID:  competitionID,
+		PenaltyMinutes: DefaultPenaltyMinutes,
+		RunInterval:    DefaultRunInterval,
+		RunWindow:      DefaultRunWindow,
+		RunLimit:       DefaultRunLimit,
+	}
+}
+
+// DefaultPracticeSetting 返回练习的默认设置, 练习面向所有用户开放,
+// 因此默认限制提交频率, 练习排行榜默认对学号脱敏
+func DefaultPracticeSetting() *CompetitionSetting {
+	setting := DefaultCompetitionSetting(PracticeCompetitionID)
+	setting.DisplayMode = privacy.DisplayModeMasked
+	setting.SubmitInterval = DefaultPracticeSubmitInterval
+	setting.SubmitWindow = DefaultPracticeSubmitWindow
+	setting.UserSubmitLimit = DefaultPracticeUserSubmitLimit
+	setting.ProblemSubmitLimit = DefaultPracticeProblemSubmitLimit
+	return setting
+}
+
+// PublicDisplayMode 公开排行榜的展示模式, 开启匿名时总是完全匿名
+func (s *CompetitionSetting) PublicDisplayMode() privacy.DisplayMode {
+	if s.PublicAnonymous {
+		return privacy.DisplayModeAnonymous
+	}
+	return s.DisplayMode
+}
+
+// SubmitWindowMs 提交次数限制的统计窗口, 单位: 毫秒
+func (s *CompetitionSetting) SubmitWindowMs() int64 {
+	return int64(s.SubmitWindow) * 60 * 1000
+}
+
+// RunWindowMs 自定义输入运行次数限制的统计窗口, 单位: 毫秒
+func (s *CompetitionSetting) RunWindowMs() int64 {
+	return int64(s.RunWindow) * 60 * 1000
+}
+
+// PenaltyMs 每次错误提交的罚时, 单位: 毫秒
+func (s *CompetitionSetting) PenaltyMs() int64 {
+	return int64(s.PenaltyMinutes) * 60 * 1000
+}
+
+type GetCompetitionSettingParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `form:"competition_id" binding:"required"`
+}
+
+type UpdateCompetitionSettingParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID         uint64                 `json:"competition_id" binding:"required"`
+	PenaltyMinutes        *int                   `json:"penalty_minutes" binding:"omitempty,min=0,max=300"`         // 罚时, 单位: 分钟
+	PublicScoreboard      *bool                  `json:"public_scoreboard"`                                         // 是否开放公开排行榜
+	PublicAnonymous       *bool                  `json:"public_anonymous"`                                          // 公开排行榜是否隐藏选手学号与姓名
+	DisplayMode           *privacy.DisplayMode   `json:"display_mode" binding:"omitempty,oneof=0 1 2 3"`            // 选手信息展示模式
+	Languages             *[]int8                `json:"languages" binding:"omitempty,dive,min=0"`                  // 允许的语言编号, 空数组表示不限制
+	MaxCodeSize           *int                   `json:"max_code_size" binding:"omitempty,min=0,max=1048576"`       // 源代码大小上限, 单位: 字节
+	SubmitInterval        *int                   `json:"submit_interval" binding:"omitempty,min=0,max=3600"`        // 同一题目两次提交的最小间隔, 单位: 秒
+	SubmitWindow          *int                   `json:"submit_window" binding:"omitempty,min=0,max=1440"`          // 提交次数限制的统计窗口, 单位: 分钟
+	UserSubmitLimit       *int                   `json:"user_submit_limit" binding:"omitempty,min=0,max=10000"`     // 统计窗口内全部题目的最大提交次数
+	ProblemSubmitLimit    *int                   `json:"problem_submit_limit" binding:"omitempty,min=0,max=10000"`  // 统计窗口内同一题目的最大提交次数
+	RunInterval           *int                   `json:"run_interval" binding:"omitempty,min=0,max=3600"`           // 两次自定义输入运行的最小间隔, 单位: 秒
+	RunWindow             *int                   `json:"run_window" binding:"omitempty,min=0,max=1440"`             // 自定义输入运行次数限制的统计窗口, 单位: 分钟
+	RunLimit              *int                   `json:"run_limit" binding:"omitempty,min=0,max=10000"`             // 统计窗口内的最大运行次数
+	DuplicateSubmitMode   *DuplicateSubmitMode   `json:"duplicate_submit_mode" binding:"omitempty,oneof=0 1 2"`     // 重复提交相同代码时的处理方式
+	JudgeReportVisibility *JudgeReportVisibility `json:"judge_report_visibility" binding:"omitempty,oneof=0 1 2 3"` // 选手可查看的判题报告范围
+	AllowUpsolve          *bool                  `json:"allow_upsolve"`                                             // 比赛结束后是否允许补题
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"time"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+)
+
+// CompetitionTemplateProblem 比赛模板中的题目, 按切片顺序排列
+type CompetitionTemplateProblem struct {
+	ProblemID uint64                            `json:"problem_id"`
+	Status    *ojmodel.CompetitionProblemStatus `json:"status"`
+}
+
+// CompetitionTemplateUser 比赛模板中的选手, 保留选手在来源比赛中的状态与分类
+type CompetitionTemplateUser struct {
+	UserID     uint64                         `json:"user_id"`
+	Status     *ojmodel.CompetitionUserStatus `json:"status"`
+	Category   string                         `json:"category"`   // 选手分类, 为空表示默认分类
+	Unofficial bool                           `json:"unofficial"` // 是否为打星选手
+}
+
+// CompetitionTemplate 比赛模板, 用于周期性比赛的快速创建
+type CompetitionTemplate struct {
+	ID              uint64                       `gorm:"column:id;type:bigint unsigned;primaryKey" json:"id"`                       // 模板 ID
+	Name            string                       `gorm:"column:name;type:varchar(255);not null" json:"name"`                        // 模板名称
+	DurationMinutes int                          `gorm:"column:duration_minutes;type:int;not null" json:"duration_minutes"`         // 比赛时长 ( 单位: 分钟 )
+	Problems        []CompetitionTemplateProblem `gorm:"column:problems;type:json;serializer:json" json:"problems"`                 // 题目列表
+	UserIDs         []uint64                     `gorm:"column:user_ids;type:json;serializer:json" json:"user_ids"`                 // 选手名单, 仅用于兼容未保存选手状态的旧模板
+	Users           []CompetitionTemplateUser    `gorm:"column:users;type:json;serializer:json" json:"users"`                       // 选手名单及其状态
+	Setting         *CompetitionSetting          `gorm:"column:setting;type:json;serializer:json" json:"setting"`                   // 比赛设置
+	CreatorID       uint64                       `gorm:"column:creator_id;type:bigint unsigned" json:"creator_id"`                  // 创建者 ID
+	UpdaterID       uint64                       `gorm:"column:updater_id;type:bigint unsigned" json:"updater_id"`                  // 更新者 ID
+	CreatedAt       time.Time                    `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"` // 创建时间
+	UpdatedAt       time.Time                    `gorm:"column:updated_at;type:datetime(3);autoUpdateTime:milli" json:"updated_at"` // 更新时间
+}
+
+func (CompetitionTemplate) TableName() string {
+	return "competition_template"
+}
+
+type CloneCompetitionParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64    `json:"competition_id" binding:"required"` // 被复制的比赛 ID
+	Name          string    `json:"name" binding:"required"`           // 新比赛名称
+	StartTime     time.Time `json:"start_time" binding:"required"`     // 新比赛开始时间, 结束时间按原比赛时长平移
+}
+
+type CloneCompetitionResponse struct {
+	CompetitionID uint64 `json:"competition_id"` // 新比赛 ID
+}
+
+type SaveCompetitionTemplateParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `json:"competition_id" binding:"required"` // 作为模板来源的比赛 ID
+	Name          string `json:"name" binding:"required,max=255"`   // 模板名称
+}
+
+type DeleteCompetitionTemplateParam struct {
+	CommonParam `json:"-"`
+
+	TemplateID uint64 `json:"template_id" binding:"required"`
+}
+
+type GetCompetitionTemplateListParam struct {
+	CommonParam `json:"-"`
+
+	Name string `form:"name"` // 按模板名称查询, 全模糊匹配
+
+	Page     int `form:"page" binding:"required,min=1"`
+	PageSize int `form:"page_size" binding:"required,min=10,max=100"`
+}
+
+type GetCompetitionTemplateListResponse struct {
+	List     []CompetitionTemplate `json:"list"`
+	Total    int                   `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+type CreateCompetitionFromTemplateParam struct {
+	CommonParam `json:"-"`
+
+	TemplateID uint64    `json:"template_id" binding:"required"`
+	Name       string    `json:"name" binding:"required"`
+	StartTime  time.Time `json:"start_time" binding:"required"`
+}
@@ -0,0 +1,23 @@
+package model
+
+// CompetitionUserCategory 比赛选手分类, 与 competition_user 表一对一, 无记录时为默认分类的正式选手
+type CompetitionUserCategory struct {
+	ID            uint64 `gorm:"column:id;type:bigint unsigned;primaryKey" json:"id"`                                                 // 记录 ID
+	CompetitionID uint64 `gorm:"column:competition_id;type:bigint unsigned;uniqueIndex:uk_competition_user_id" json:"competition_id"` // 比赛 ID
+	UserID        uint64 `gorm:"column:user_id;type:bigint unsigned;uniqueIndex:uk_competition_user_id" json:"user_id"`               // 用户 ID
+	Category      string `gorm:"column:category;type:varchar(32);not null;default:''" json:"category"`                                // 选手分类, 如 Div1 / Div2 / 嘉宾
+	Unofficial    bool   `gorm:"column:unofficial;type:tinyint(1);not null;default:0" json:"unofficial"`                              // 是否为打星选手, 打星选手出现在排行榜上但不占用正式排名
+}
+
+func (CompetitionUserCategory) TableName() string {
+	return "competition_user_category"
+}
+
+type SetCompetitionUserCategoryParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64   `json:"competition_id" binding:"required"`     // 竞赛ID
+	UserIDList    []uint64 `json:"user_id_list" binding:"required,min=1"` // 用户ID
+	Category      string   `json:"category" binding:"max=32"`             // 选手分类, 为空表示默认分类
+	Unofficial    bool     `json:"unofficial"`                            // 是否为打星选手
+}
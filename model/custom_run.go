@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+const (
+	CustomRunTTL      = 10 * time.Minute // 运行记录的保留时间
+	MaxCustomRunStdin = 64 * 1024        // 自定义输入大小上限, 单位: 字节
+)
+
+// CustomRunStatus 自定义输入运行状态
+type CustomRunStatus int8
+
+const (
+	CustomRunStatusPending  CustomRunStatus = iota // 等待运行
+	CustomRunStatusRunning                         // 运行中
+	CustomRunStatusFinished                        // 运行完成
+)
+
+// CustomRun 自定义输入运行记录, 仅保存在 Redis 中, 不写入 submission 表也不影响排行榜
+// 判题服务完成运行后覆盖同一 key 的记录 ( 保留过期时间 ) 并在 RedisPubSubCustomRunEventKey 上发布通知
+type CustomRun struct {
+	RunID         string          `json:"run_id"`         // 运行 ID
+	CompetitionID uint64          `json:"competition_id"` // 比赛 ID
+	ProblemID     uint64          `json:"problem_id"`     // 题目 ID
+	UserID        uint64          `json:"user_id"`        // 用户 ID
+	Language      int8            `json:"language"`       // 提交语言
+	Status        CustomRunStatus `json:"status"`         // 运行状态
+	Result        int8            `json:"result"`         // 运行结果, 取值与提交的判题结果一致, 不比较输出
+	Stdout        string          `json:"stdout"`         // 标准输出, 由判题服务截断
+	Stderr        string          `json:"stderr"`         // 编译错误或标准错误输出
+	TimeUsed      int             `json:"time_used"`      // 运行时间 ( 单位: 毫秒 )
+	MemoryUsed    int             `json:"memory_used"`    // 运行内存 ( 单位: KB )
+	CreatedAt     time.Time       `json:"created_at"`     // 创建时间
+	FinishedAt    *time.Time      `json:"finished_at"`    // 完成时间
+}
+
+// CustomRunTask 投递到 CustomRunTopic 的运行任务, 以 JSON 编码
+type CustomRunTask struct {
+	RunID       string `json:"run_id"`
+	ProblemID   uint64 `json:"problem_id"`
+	Code        string `json:"code"`
+	Language    int8   `json:"language"`
+	Stdin       string `json:"stdin"`
+	TimeLimit   int    `json:"time_limit"`   // 已乘以语言倍率, 单位: 毫秒
+	MemoryLimit int    `json:"memory_limit"` // 已乘以语言倍率, 单位: MB
+	RequestID   string `json:"request_id"`
+}
+
+type CreateCustomRunParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	ProblemID uint64 `json:"problem_id" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+	Language  int8   `json:"language" binding:"min=0"`
+	Stdin     string `json:"stdin"`
+}
+
+type CreateCustomRunResponse struct {
+	RunID string `json:"run_id"`
+}
+
+type GetCustomRunParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	RunID string `form:"run_id" binding:"required,len=32,hexadecimal"`
+}
+
+type CustomRunEventParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	RunID string `form:"run_id" binding:"required,len=32,hexadecimal"`
+}
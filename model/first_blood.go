@@ -0,0 +1,25 @@
+package model
+
+// FirstBlood 题目的首个通过者
+type FirstBlood struct {
+	ProblemID uint64 `json:"problem_id"`
+	Label     string `json:"label"`      // 题目标号
+	UserID    uint64 `json:"user_id"`    // 首个通过的选手 ID, 为 0 表示原首个通过因重判或取消资格被撤销且当前无人通过
+	Username  string `json:"username"`   // 学号
+	Realname  string `json:"realname"`   // 姓名
+	SolveTime int64  `json:"solve_time"` // 通过时间距比赛开始的毫秒数
+	Tries     int    `json:"tries"`      // 包含通过提交在内的提交次数
+}
+
+type GetCompetitionFirstBloodListParam struct {
+	CompetitionCommonParam `json:"-"`
+}
+
+type GetCompetitionFirstBloodListResponse struct {
+	List  []FirstBlood `json:"list"`
+	Total int          `json:"total"`
+}
+
+type FirstBloodEventParam struct {
+	CompetitionCommonParam `json:"-"`
+}
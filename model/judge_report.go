@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// JudgeReportVisibility 选手可查看的判题报告范围
+type JudgeReportVisibility int8
+
+const (
+	JudgeReportVisibilityNone        JudgeReportVisibility = iota // 不可查看
+	JudgeReportVisibilitySample                                   // 仅样例测试点
+	JudgeReportVisibilitySampleUntil                              // 比赛期间仅样例测试点, 比赛结束后全部测试点
+	JudgeReportVisibilityFull                                     // 全部测试点
+)
+
+// TestcaseReport 单个测试点的判题结果
+type TestcaseReport struct {
+	Index          int    `json:"index"`           // 测试点序号, 从 1 开始
+	Sample         bool   `json:"sample"`          // 是否为样例测试点
+	Result         int8   `json:"result"`          // 判题结果, 取值与提交的判题结果一致
+	TimeUsed       int    `json:"time_used"`       // 运行时间 ( 单位: 毫秒 )
+	MemoryUsed     int    `json:"memory_used"`     // 运行内存 ( 单位: KB )
+	CheckerMessage string `json:"checker_message"` // 检查器输出
+}
+
+// JudgeReport 提交的详细判题报告, 与 submission 表一对一, 重判时覆盖
+// 判题服务以 JSON 编码投递到 JudgeReportTopic
+type JudgeReport struct {
+	SubmissionID uint64           `gorm:"column:submission_id;type:bigint unsigned;primaryKey" json:"submission_id"` // 提交 ID
+	CompileLog   string           `gorm:"column:compile_log;type:text" json:"compile_log"`                           // 编译输出
+	Testcases    []TestcaseReport `gorm:"column:testcases;type:json;serializer:json" json:"testcases"`               // 各测试点判题结果, 按序号排列
+	CreatedAt    time.Time        `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"` // 创建时间
+	UpdatedAt    time.Time        `gorm:"column:updated_at;type:datetime(3);autoUpdateTime:milli" json:"updated_at"` // 更新时间
+}
+
+func (JudgeReport) TableName() string {
+	return "judge_report"
+}
+
+type GetJudgeReportParam struct {
+	CommonParam `json:"-"`
+
+	SubmissionID uint64 `form:"submission_id" binding:"required"`
+}
+
+type UserGetJudgeReportParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	SubmissionID uint64 `form:"submission_id" binding:"required"`
+}
+
+// UserJudgeReport 选手可见的判题报告, 不可见的测试点只计入总数
+type UserJudgeReport struct {
+	SubmissionID  uint64           `json:"submission_id"`
+	CompileLog    string           `json:"compile_log"`
+	Testcases     []TestcaseReport `json:"testcases"`
+	TotalCount    int              `json:"total_count"`    // 测试点总数
+	AcceptedCount int              `json:"accepted_count"` // 通过的测试点数
+}
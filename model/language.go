@@ -0,0 +1,124 @@
+package model
+
+import (
+	"time"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+)
+
+const DefaultMaxCodeSize = 64 * 1024 // 默认源代码大小上限, 单位: 字节
+
+// Language 提交语言注册表, ID 与判题服务使用的语言编号一致, 倍率随判题任务下发给判题服务
+type Language struct {
+	ID               int8      `gorm:"column:id;type:tinyint;primaryKey;autoIncrement:false" json:"id"`                        // 语言编号
+	Name             string    `gorm:"column:name;type:varchar(32);not null" json:"name"`                                      // 语言名称
+	Version          string    `gorm:"column:version;type:varchar(64);not null;default:''" json:"version"`                     // 编译器或解释器版本
+	FileExt          string    `gorm:"column:file_ext;type:varchar(16);not null;default:''" json:"file_ext"`                   // 源文件扩展名
+	TimeMultiplier   float64   `gorm:"column:time_multiplier;type:decimal(4,2);not null;default:1" json:"time_multiplier"`     // 时间限制倍率
+	MemoryMultiplier float64   `gorm:"column:memory_multiplier;type:decimal(4,2);not null;default:1" json:"memory_multiplier"` // 内存限制倍率
+	Enabled          bool      `gorm:"column:enabled;type:tinyint(1);not null;default:1" json:"enabled"`                       // 是否启用
+	UpdaterID        uint64    `gorm:"column:updater_id;type:bigint unsigned" json:"updater_id"`                               // 更新者 ID
+	CreatedAt        time.Time `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`              // 创建时间
+	UpdatedAt        time.Time `gorm:"column:updated_at;type:datetime(3);autoUpdateTime:milli" json:"updated_at"`              // 更新时间
+}
+
+func (Language) TableName() string {
+	return "language"
+}
+
+// DefaultLanguages 内置的提交语言, 注册表中同编号的记录会覆盖内置定义
+func DefaultLanguages() []Language {
+	ids := []ojmodel.SubmissionLanguage{
+		ojmodel.SubmissionLanguageC,
+		ojmodel.SubmissionLanguageCPP,
+		ojmodel.SubmissionLanguagePython,
+		ojmodel.SubmissionLanguageJava,
+		ojmodel.SubmissionLanguageGo,
+	}
+	languages := make([]Language, 0, len(ids))
+	for _, id := range ids {
+		languages = append(languages, Language{
+			ID:               int8(id),
+			Name:             id.String(),
+			FileExt:          SubmissionFileExt(int8(id)),
+			TimeMultiplier:   1,
+			MemoryMultiplier: 1,
+			Enabled:          true,
+		})
+	}
+	return languages
+}
+
+// CompetitionProblemSubmitSetting 比赛题目的提交限制, 无记录时仅受比赛设置限制
+type CompetitionProblemSubmitSetting struct {
+	CompetitionID uint64    `gorm:"column:competition_id;type:bigint unsigned;primaryKey" json:"competition_id"` // 比赛 ID
+	ProblemID     uint64    `gorm:"column:problem_id;type:bigint unsigned;primaryKey" json:"problem_id"`         // 题目 ID
+	Languages     []int8    `gorm:"column:languages;type:json;serializer:json" json:"languages"`                 // 允许的语言编号, 为空表示不限制
+	MaxCodeSize   int       `gorm:"column:max_code_size;type:int;not null;default:0" json:"max_code_size"`       // 源代码大小上限 ( 单位: 字节, 0 表示不限制 )
+	UpdaterID     uint64    `gorm:"column:updater_id;type:bigint unsigned" json:"updater_id"`                    // 更新者 ID
+	CreatedAt     time.Time `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`   // 创建时间
+	UpdatedAt     time.Time `gorm:"column:updated_at;type:datetime(3);autoUpdateTime:milli" json:"updated_at"`   // 更新时间
+}
+
+func (CompetitionProblemSubmitSetting) TableName() string {
+	return "competition_problem_submit_setting"
+}
+
+// SubmitLimit 比赛题目最终生效的提交限制
+type SubmitLimit struct {
+	Languages   []Language `json:"languages"`     // 允许的语言
+	MaxCodeSize int        `json:"max_code_size"` // 源代码大小上限, 单位: 字节
+}
+
+// AllowLanguage 判断语言是否在允许列表中
+func (l *SubmitLimit) AllowLanguage(language int8) bool {
+	for _, lang := range l.Languages {
+		if lang.ID == language {
+			return true
+		}
+	}
+	return false
+}
+
+type SaveLanguageParam struct {
+	CommonParam `json:"-"`
+
+	ID               *int8   `json:"id" binding:"required,min=0"`
+	Name             string  `json:"name" binding:"required,max=32"`
+	Version          string  `json:"version" binding:"max=64"`
+	FileExt          string  `json:"file_ext" binding:"max=16"`
+	TimeMultiplier   float64 `json:"time_multiplier" binding:"required,gt=0,lte=10"`
+	MemoryMultiplier float64 `json:"memory_multiplier" binding:"required,gt=0,lte=10"`
+	Enabled          *bool   `json:"enabled" binding:"required"`
+}
+
+type GetLanguageListParam struct {
+	CommonParam `json:"-"`
+}
+
+type GetLanguageListResponse struct {
+	List  []Language `json:"list"`
+	Total int        `json:"total"`
+}
+
+type GetCompetitionProblemSubmitSettingParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `form:"competition_id" binding:"required"`
+	ProblemID     uint64 `form:"problem_id" binding:"required"`
+}
+
+type UpdateCompetitionProblemSubmitSettingParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64  `json:"competition_id" binding:"required"`
+	ProblemID     uint64  `json:"problem_id" binding:"required"`
+	Languages     *[]int8 `json:"languages" binding:"omitempty,dive,min=0"`            // 允许的语言编号, 空数组表示不限制
+	MaxCodeSize   *int    `json:"max_code_size" binding:"omitempty,min=0,max=1048576"` // 源代码大小上限, 单位: 字节
+}
+
+type UserGetSubmitLimitParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	ProblemID uint64 `form:"problem_id" binding:"required"`
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"time"
+
+	"github.com/to404hanga/online_judge_controller/pkg/textdiff"
+)
+
+type PlagiarismTaskStatus int8
+
+const (
+	PlagiarismTaskStatusPending  PlagiarismTaskStatus = iota // 等待执行
+	PlagiarismTaskStatusRunning                              // 执行中
+	PlagiarismTaskStatusFinished                             // 已完成
+	PlagiarismTaskStatusFailed                               // 执行失败
+)
+
+// PlagiarismTask 比赛题目的代码查重任务
+type PlagiarismTask struct {
+	ID              uint64               `gorm:"column:id;type:bigint unsigned;primaryKey" json:"id"`                                            // 任务 ID
+	CompetitionID   uint64               `gorm:"column:competition_id;type:bigint unsigned;index:idx_competition_problem" json:"competition_id"` // 比赛 ID
+	ProblemID       uint64               `gorm:"column:problem_id;type:bigint unsigned;index:idx_competition_problem" json:"problem_id"`         // 题目 ID
+	Status          PlagiarismTaskStatus `gorm:"column:status;type:tinyint;not null;default:0" json:"status"`                                    // 任务状态 ( 0: 等待执行, 1: 执行中, 2: 已完成, 3: 执行失败 )
+	Threshold       float64              `gorm:"column:threshold;type:double;not null" json:"threshold"`                                         // 相似度阈值, 不低于该值的两份代码记为可疑
+	SubmissionCount int                  `gorm:"column:submission_count;type:int;not null;default:0" json:"submission_count"`                    // 参与比较的提交数
+	PairCount       int                  `gorm:"column:pair_count;type:int;not null;default:0" json:"pair_count"`                                // 可疑代码对数
+	ClusterCount    int                  `gorm:"column:cluster_count;type:int;not null;default:0" json:"cluster_count"`                          // 可疑代码簇数
+	Message         string               `gorm:"column:message;type:varchar(255);not null;default:''" json:"message"`                            // 失败原因
+	CreatorID       uint64               `gorm:"column:creator_id;type:bigint unsigned" json:"creator_id"`                                       // 创建者 ID, 0 表示定时任务
+	FinishedAt      *time.Time           `gorm:"column:finished_at;type:datetime(3)" json:"finished_at"`                                         // 完成时间
+	CreatedAt       time.Time            `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`                      // 创建时间
+	UpdatedAt       time.Time            `gorm:"column:updated_at;type:datetime(3);autoUpdateTime:milli" json:"updated_at"`                      // 更新时间
+}
+
+func (PlagiarismTask) TableName() string {
+	return "plagiarism_task"
+}
+
+type PlagiarismPairStatus int8
+
+const (
+	PlagiarismPairStatusPending   PlagiarismPairStatus = iota // 待复核
+	PlagiarismPairStatusConfirmed                             // 确认抄袭
+	PlagiarismPairStatusDismissed                             // 排除嫌疑
+)
+
+// PlagiarismPair 相似度超过阈值的两份提交, 同一簇中的代码两两之间直接或间接相似
+type PlagiarismPair struct {
+	ID                uint64               `gorm:"column:id;type:bigint unsigned;primaryKey" json:"id"`                        // 记录 ID
+	TaskID            uint64               `gorm:"column:task_id;type:bigint unsigned;index:idx_task_id" json:"task_id"`       // 查重任务 ID
+	CompetitionID     uint64               `gorm:"column:competition_id;type:bigint unsigned" json:"competition_id"`           // 比赛 ID
+	ProblemID         uint64               `gorm:"column:problem_id;type:bigint unsigned" json:"problem_id"`                   // 题目 ID
+	ClusterID         int                  `gorm:"column:cluster_id;type:int;not null" json:"cluster_id"`                      // 所属簇编号, 同一任务内从 1 开始
+	LeftSubmissionID  uint64               `gorm:"column:left_submission_id;type:bigint unsigned" json:"left_submission_id"`   // 左侧提交 ID
+	LeftUserID        uint64               `gorm:"column:left_user_id;type:bigint unsigned" json:"left_user_id"`               // 左侧用户 ID
+	RightSubmissionID uint64               `gorm:"column:right_submission_id;type:bigint unsigned" json:"right_submission_id"` // 右侧提交 ID
+	RightUserID       uint64               `gorm:"column:right_user_id;type:bigint unsigned" json:"right_user_id"`             // 右侧用户 ID
+	Similarity        float64              `gorm:"column:similarity;type:double;not null" json:"similarity"`                   // 相似度, 取值范围 [0, 1]
+	Status            PlagiarismPairStatus `gorm:"column:status;type:tinyint;not null;default:0" json:"status"`                // 复核状态 ( 0: 待复核, 1: 确认抄袭, 2: 排除嫌疑 )
+	ReviewerID        uint64               `gorm:"column:reviewer_id;type:bigint unsigned" json:"reviewer_id"`                 // 复核者 ID
+	CreatedAt         time.Time            `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`  // 创建时间
+	UpdatedAt         time.Time            `gorm:"column:updated_at;type:datetime(3);autoUpdateTime:milli" json:"updated_at"`  // 更新时间
+}
+
+func (PlagiarismPair) TableName() string {
+	return "plagiarism_pair"
+}
+
+// PlagiarismPairItem 附带双方用户信息的可疑代码对
+type PlagiarismPairItem struct {
+	PlagiarismPair `json:",inline"`
+	LeftUsername   string `gorm:"column:left_username" json:"left_username"`
+	LeftRealname   string `gorm:"column:left_realname" json:"left_realname"`
+	RightUsername  string `gorm:"column:right_username" json:"right_username"`
+	RightRealname  string `gorm:"column:right_realname" json:"right_realname"`
+}
+
+type CreatePlagiarismTaskParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64  `json:"competition_id" binding:"required"`
+	ProblemID     uint64  `json:"problem_id" binding:"required"`
+	Threshold     float64 `json:"threshold" binding:"omitempty,gt=0,lte=1"` // 相似度阈值, 为空时使用默认值
+}
+
+type CreatePlagiarismTaskResponse struct {
+	TaskID uint64 `json:"task_id"`
+}
+
+type GetPlagiarismTaskListParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64  `form:"competition_id" binding:"required"`
+	ProblemID     *uint64 `form:"problem_id"` // 只返回指定题目的任务
+}
+
+type GetPlagiarismTaskListResponse struct {
+	List  []PlagiarismTask `json:"list"`
+	Total int              `json:"total"`
+}
+
+type GetPlagiarismPairListParam struct {
+	CommonParam `json:"-"`
+
+	TaskID    uint64                `form:"task_id" binding:"required"`
+	ClusterID *int                  `form:"cluster_id"`                             // 只返回指定簇的代码对
+	Status    *PlagiarismPairStatus `form:"status" binding:"omitempty,oneof=0 1 2"` // 按复核状态查询
+
+	Page     int `form:"page" binding:"required,min=1"`               // 分页页码
+	PageSize int `form:"page_size" binding:"required,min=10,max=100"` // 分页每页数量
+}
+
+type GetPlagiarismPairListResponse struct {
+	Total    int                  `json:"total"`     // 总记录数
+	List     []PlagiarismPairItem `json:"list"`      // 记录列表, 按相似度降序
+	Page     int                  `json:"page"`      // 分页页码
+	PageSize int                  `json:"page_size"` // 分页每页数量
+}
+
+type GetPlagiarismPairDiffParam struct {
+	CommonParam `json:"-"`
+
+	PairID uint64 `form:"pair_id" binding:"required"`
+}
+
+type GetPlagiarismPairDiffResponse struct {
+	Pair PlagiarismPairItem `json:"pair"`
+	Rows []textdiff.Row     `json:"rows"` // 左右对照的代码
+}
+
+type ReviewPlagiarismPairParam struct {
+	CommonParam `json:"-"`
+
+	PairID            uint64               `json:"pair_id" binding:"required"`
+	Status            PlagiarismPairStatus `json:"status" binding:"oneof=1 2"` // 复核结果, 1: 确认抄袭, 2: 排除嫌疑
+	DisqualifyUserIDs []uint64             `json:"disqualify_user_ids"`        // 确认抄袭时取消比赛资格的用户, 只能为代码对中的双方
+	Reason            string               `json:"reason" binding:"max=255"`   // 取消资格原因, 为空时自动生成
+}
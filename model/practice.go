@@ -0,0 +1,84 @@
+package model
+
+import "time"
+
+// PracticeCompetitionID 练习提交的比赛 ID, 练习提交不属于任何比赛
+const PracticeCompetitionID uint64 = 0
+
+// PracticeProblemItem 练习题目列表中的题目, 不包含题目描述
+type PracticeProblemItem struct {
+	ID          uint64 `json:"id"`
+	Title       string `json:"title"`
+	TimeLimit   int    `json:"time_limit"`   // 时间限制 ( 单位: 毫秒 )
+	MemoryLimit int    `json:"memory_limit"` // 内存限制 ( 单位: MB )
+	Accepted    bool   `json:"accepted"`     // 当前用户是否已在练习中通过
+}
+
+type GetPracticeProblemListParam struct {
+	CommonParam `json:"-"`
+
+	Title string `form:"title" binding:"omitempty,max=255"` // 按标题模糊查询
+
+	Page     int `form:"page" binding:"required,min=1"`
+	PageSize int `form:"page_size" binding:"required,min=10,max=100"`
+}
+
+type GetPracticeProblemListResponse struct {
+	List     []PracticeProblemItem `json:"list"`
+	Total    int                   `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+type GetPracticeProblemParam struct {
+	CommonParam `json:"-"`
+
+	ProblemID uint64 `form:"problem_id" binding:"required"`
+}
+
+type SubmitPracticeProblemParam struct {
+	CommonParam `json:"-"`
+
+	Code      string `json:"code" binding:"required"`
+	Language  int8   `json:"language" binding:"min=0"` // 提交语言, 是否允许由语言注册表决定
+	ProblemID uint64 `json:"problem_id" binding:"required"`
+}
+
+type GetPracticeSubmissionListParam struct {
+	CommonParam `json:"-"`
+
+	ProblemID *uint64 `form:"problem_id"` // 只查询指定题目的提交
+
+	Page     int `form:"page" binding:"required,min=1"`
+	PageSize int `form:"page_size" binding:"required,min=10,max=100"`
+}
+
+type GetPracticeSubmissionDetailParam struct {
+	CommonParam `json:"-"`
+
+	SubmissionID uint64 `form:"submission_id" binding:"required"`
+}
+
+// PracticeRanking 练习排行榜中的用户, 按通过题目数降序排列
+type PracticeRanking struct {
+	Rank           int       `gorm:"-" json:"rank"` // 通过题目数相同的用户排名相同
+	UserID         uint64    `gorm:"column:user_id" json:"user_id"`
+	Username       string    `gorm:"column:username" json:"username"`
+	Realname       string    `gorm:"column:realname" json:"realname"`
+	SolvedCount    int       `gorm:"column:solved_count" json:"solved_count"`         // 练习中通过的题目数
+	LastAcceptedAt time.Time `gorm:"column:last_accepted_at" json:"last_accepted_at"` // 各题首次通过时间中最晚的一个
+}
+
+type GetPracticeRankingListParam struct {
+	CommonParam `json:"-"`
+
+	Page     int `form:"page" binding:"required,min=1"`
+	PageSize int `form:"page_size" binding:"required,min=10,max=100"`
+}
+
+type GetPracticeRankingListResponse struct {
+	List     []PracticeRanking `json:"list"`
+	Total    int               `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
@@ -2,21 +2,26 @@ package model
 
 import (
 	"time"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
 )
 
 type GetCompetitionRankingListParam struct {
 	CompetitionCommonParam `json:"-"`
 
-	Page     int `form:"page" binding:"required,min=1"`
-	PageSize int `form:"page_size" binding:"required,min=10,max=100"`
+	Page     int     `form:"page" binding:"required,min=1"`
+	PageSize int     `form:"page_size" binding:"required,min=10,max=100"`
+	Category *string `form:"category" binding:"omitempty,max=32"` // 按选手分类筛选, 空字符串表示默认分类
 }
 
 type Problem struct {
 	ProblemID  uint64        `json:"problem_id"`
+	Label      string        `json:"label"`       // 题目标号
 	Result     ProblemStatut `json:"result"`      // 题目状态: 0-未尝试, 1-尝试中, 2-通过
 	AcceptedAt int64         `json:"accepted_at"` // 通过时间(不含罚时, 单位: 毫秒)
 	Retrys     int           `json:"retries"`     // 重试次数
 	IsFastest  bool          `json:"is_fastest"`
+	Upsolved   bool          `json:"upsolved,omitempty"` // 是否为赛后补题通过, 仅出现在包含补题的排行榜中
 }
 
 type ProblemStatut int8
@@ -28,19 +33,35 @@ const (
 )
 
 type Ranking struct {
-	UserID        uint64    `json:"user_id"`
-	Username      string    `json:"username"`        // 学号
-	Realname      string    `json:"realname"`        // 真实姓名
-	TotalAccepted int       `json:"total_accepted"`  // 通过数
-	TotalTimeUsed int64     `json:"total_time_used"` // 总耗时(包括罚时, 单位: 毫秒)
-	Problems      []Problem `json:"problems"`        // 题目通过情况
+	UserID          uint64    `json:"user_id"`
+	Username        string    `json:"username"`                   // 学号
+	Realname        string    `json:"realname"`                   // 真实姓名
+	Category        string    `json:"category"`                   // 选手分类
+	Unofficial      bool      `json:"unofficial"`                 // 是否为打星选手
+	Rank            int       `json:"rank"`                       // 正式排名, 打星选手为 0
+	CategoryRank    int       `json:"category_rank"`              // 分类内正式排名, 打星选手为 0
+	TotalAccepted   int       `json:"total_accepted"`             // 通过数
+	TotalTimeUsed   int64     `json:"total_time_used"`            // 总耗时(包括罚时与罚时调整, 单位: 毫秒)
+	TimeAdjustment  int64     `json:"time_adjustment"`            // 裁判罚时调整(单位: 毫秒), 正数为加罚时
+	UpsolveAccepted int       `json:"upsolve_accepted,omitempty"` // 赛后补题通过数, 已计入通过数, 仅出现在包含补题的排行榜中
+	Problems        []Problem `json:"problems"`                   // 题目通过情况
 }
 
 type GetCompetitionRankingListResponse struct {
-	List     []Ranking `json:"list"`
-	Total    int       `json:"total"`
-	Page     int       `json:"page"`
-	PageSize int       `json:"page_size"`
+	Version    int64                    `json:"version"`    // 排行榜版本, 用于获取增量更新
+	Problems   []CompetitionProblemItem `json:"problems"`   // 按顺序排列的比赛题目, 作为排行榜表头
+	Statistics []ProblemStatistics      `json:"statistics"` // 各题统计, 顺序与 Problems 一致
+	List       []Ranking                `json:"list"`
+	Total      int                      `json:"total"`
+	Page       int                      `json:"page"`
+	PageSize   int                      `json:"page_size"`
+}
+
+type GetPublicCompetitionRankingListParam struct {
+	CompetitionID uint64  `form:"competition_id" binding:"required"`
+	Page          int     `form:"page" binding:"required,min=1"`
+	PageSize      int     `form:"page_size" binding:"required,min=10,max=100"`
+	Category      *string `form:"category" binding:"omitempty,max=32"` // 按选手分类筛选, 空字符串表示默认分类
 }
 
 type InitRankingParam struct {
@@ -53,10 +74,11 @@ type InitRankingParam struct {
 type UpdateScoreParam struct {
 	CommonParam `json:"-"`
 
-	CompetitionID  uint64    `json:"competition_id" binding:"required"`
-	UserID         uint64    `json:"user_id" binding:"required"`
-	ProblemID      uint64    `json:"problem_id" binding:"required"`
-	IsAccepted     *bool     `json:"is_accepted" binding:"required"`
-	SubmissionTime time.Time `json:"submission_time" binding:"required"`
-	StartTime      time.Time `json:"start_time" binding:"required"`
+	CompetitionID  uint64                    `json:"competition_id" binding:"required"`
+	UserID         uint64                    `json:"user_id" binding:"required"`
+	ProblemID      uint64                    `json:"problem_id" binding:"required"`
+	IsAccepted     *bool                     `json:"is_accepted" binding:"required"`
+	Result         *ojmodel.SubmissionResult `json:"result" binding:"omitempty,min=1,max=7"` // 判题结果, 用于题目统计, 为空时按 is_accepted 记为 Accepted 或 Wrong Answer
+	SubmissionTime time.Time                 `json:"submission_time" binding:"required"`
+	StartTime      time.Time                 `json:"start_time" binding:"required"`
 }
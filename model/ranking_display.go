@@ -0,0 +1,49 @@
+package model
+
+import "github.com/to404hanga/online_judge_controller/pkg/privacy"
+
+// RankingDisplay 排行榜选手信息展示方式, 管理员接口与内部计算不使用, 只在返回给选手与观众前处理
+type RankingDisplay struct {
+	Mode         privacy.DisplayMode
+	Nicknames    map[uint64]string // 用户 ID -> 昵称, 仅昵称模式下加载
+	AnonymousKey []byte            // 比赛的匿名密钥, 仅匿名模式下加载, 用于将用户 ID 替换为匿名 ID
+}
+
+// UserID 返回用于展示的用户 ID, 匿名模式下替换为比赛内稳定的匿名 ID, 避免通过用户 ID 反查选手
+func (d *RankingDisplay) UserID(userID uint64) uint64 {
+	if d == nil || d.Mode != privacy.DisplayModeAnonymous {
+		return userID
+	}
+	return privacy.AnonymousID(d.AnonymousKey, userID)
+}
+
+// Apply 按展示模式处理单个选手的用户 ID, 学号与姓名
+func (d *RankingDisplay) Apply(userID *uint64, username, realname *string) {
+	if d == nil || d.Mode == privacy.DisplayModeFull {
+		return
+	}
+	*username, *realname = d.Mode.Apply(*username, *realname, d.Nicknames[*userID])
+	*userID = d.UserID(*userID)
+}
+
+// ApplyRankings 按展示模式处理排行榜中的用户 ID, 学号与姓名
+func (d *RankingDisplay) ApplyRankings(rankings []Ranking) {
+	for i := range rankings {
+		d.Apply(&rankings[i].UserID, &rankings[i].Username, &rankings[i].Realname)
+	}
+}
+
+// ApplyRankingDiff 按展示模式处理排行榜差异, 移出排行榜的用户 ID 与变化行使用同样的映射, 客户端仍可按 ID 合并
+func (d *RankingDisplay) ApplyRankingDiff(diff *RankingDiff) {
+	d.ApplyRankings(diff.Rows)
+	for i := range diff.Removed {
+		diff.Removed[i] = d.UserID(diff.Removed[i])
+	}
+}
+
+// ApplyStatistics 按展示模式处理题目统计中首个通过的选手 ID
+func (d *RankingDisplay) ApplyStatistics(statistics []ProblemStatistics) {
+	for i := range statistics {
+		statistics[i].FirstSolveUserID = d.UserID(statistics[i].FirstSolveUserID)
+	}
+}
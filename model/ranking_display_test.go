@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/to404hanga/online_judge_controller/pkg/privacy"
+)
+
+func TestRankingDisplayApply(t *testing.T) {
+	key := []byte("competition-key")
+	tests := []struct {
+		name         string
+		display      *RankingDisplay
+		wantUserID   uint64
+		wantUsername string
+		wantRealname string
+	}{
+		{"未设置展示方式", nil, 42, "202312345678", "张三"},
+		{"完整显示", &RankingDisplay{Mode: privacy.DisplayModeFull}, 42, "202312345678", "张三"},
+		{"昵称模式", &RankingDisplay{Mode: privacy.DisplayModeNickname, Nicknames: map[uint64]string{42: "小张"}}, 42, "", "小张"},
+		{"学号脱敏", &RankingDisplay{Mode: privacy.DisplayModeMasked}, 42, "2023******78", "张三"},
+		{"完全匿名替换用户 ID", &RankingDisplay{Mode: privacy.DisplayModeAnonymous, AnonymousKey: key}, privacy.AnonymousID(key, 42), "", privacy.AnonymousName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID, username, realname := uint64(42), "202312345678", "张三"
+			tt.display.Apply(&userID, &username, &realname)
+			if userID != tt.wantUserID || username != tt.wantUsername || realname != tt.wantRealname {
+				t.Errorf("Apply() = (%d, %q, %q), want (%d, %q, %q)", userID, username, realname, tt.wantUserID, tt.wantUsername, tt.wantRealname)
+			}
+		})
+	}
+}
+
+func TestRankingDisplayApplyRankingDiff(t *testing.T) {
+	display := &RankingDisplay{Mode: privacy.DisplayModeAnonymous, AnonymousKey: []byte("competition-key")}
+	diff := &RankingDiff{
+		Rows:    []Ranking{{UserID: 1, Username: "202300000001", Realname: "张三"}},
+		Removed: []uint64{1, 2},
+	}
+	display.ApplyRankingDiff(diff)
+	if diff.Rows[0].UserID == 1 || diff.Rows[0].Username != "" {
+		t.Fatalf("row not anonymized: %+v", diff.Rows[0])
+	}
+	// 客户端按用户 ID 合并, 移出的行与变化行必须使用同一映射
+	if diff.Removed[0] != diff.Rows[0].UserID {
+		t.Errorf("Removed[0] = %d, want %d", diff.Removed[0], diff.Rows[0].UserID)
+	}
+	if diff.Removed[1] == 2 {
+		t.Errorf("Removed[1] kept the real user id")
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+type GetCompetitionRankingHistoryParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	Minute   int     `form:"minute" binding:"min=0"` // 距比赛开始的分钟数
+	Page     int     `form:"page" binding:"required,min=1"`
+	PageSize int     `form:"page_size" binding:"required,min=10,max=100"`
+	Category *string `form:"category" binding:"omitempty,max=32"` // 按选手分类筛选, 空字符串表示默认分类
+}
+
+type GetCompetitionRankingHistoryResponse struct {
+	Minute   int                      `json:"minute"`   // 实际查询的分钟数, 超过比赛时长时为比赛时长
+	Problems []CompetitionProblemItem `json:"problems"` // 按顺序排列的比赛题目, 作为排行榜表头
+	List     []Ranking                `json:"list"`
+	Total    int                      `json:"total"`
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"page_size"`
+}
+
+type GetCompetitionRankTrendParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	UserID   *uint64 `form:"user_id"`                                   // 查询指定选手的排名变化, 为空时查询最终排名前 Top 名选手
+	Top      int     `form:"top" binding:"omitempty,min=1,max=50"`      // 默认 10
+	Interval int     `form:"interval" binding:"omitempty,min=1,max=60"` // 采样间隔 ( 单位: 分钟 ), 默认 5
+}
+
+// RankTrendSeries 单个选手的排名变化曲线
+type RankTrendSeries struct {
+	UserID        uint64 `json:"user_id"`
+	Username      string `json:"username"`
+	Realname      string `json:"realname"`
+	Ranks         []int  `json:"ranks"`          // 各采样点的正式排名, 0 表示尚未上榜或不占用排名
+	TotalAccepted []int  `json:"total_accepted"` // 各采样点的通过数
+}
+
+type GetCompetitionRankTrendResponse struct {
+	Minutes []int             `json:"minutes"` // 采样点, 距比赛开始的分钟数
+	Series  []RankTrendSeries `json:"series"`
+}
@@ -0,0 +1,35 @@
+package model
+
+// ProblemStatistics 比赛题目统计, 不包含被取消资格选手的提交
+type ProblemStatistics struct {
+	ProblemID        uint64         `json:"problem_id"`
+	Label            string         `json:"label"`               // 题目标号
+	Tried            int            `json:"tried"`               // 提交过该题的选手数
+	Solved           int            `json:"solved"`              // 通过该题的选手数
+	Submissions      int            `json:"submissions"`         // 已判题的提交数
+	FirstSolveUserID uint64         `json:"first_solve_user_id"` // 首个通过的选手 ID, 0 表示尚无人通过
+	FirstSolveTime   int64          `json:"first_solve_time"`    // 首次通过距比赛开始的时间, 单位为毫秒
+	FirstSolveTries  int            `json:"first_solve_tries"`   // 首个通过的选手包含通过提交在内的提交次数
+	Verdicts         map[string]int `json:"verdicts"`            // 各判题结果的提交数, 键为判题结果名称
+}
+
+type GetCompetitionProblemStatisticsParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `form:"competition_id" binding:"required"`
+}
+
+type UserGetCompetitionProblemStatisticsParam struct {
+	CompetitionCommonParam `json:"-"`
+}
+
+type GetCompetitionProblemStatisticsResponse struct {
+	List  []ProblemStatistics `json:"list"`
+	Total int                 `json:"total"`
+}
+
+type RebuildCompetitionProblemStatisticsParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID uint64 `json:"competition_id" binding:"required"`
+}
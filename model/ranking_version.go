@@ -0,0 +1,28 @@
+package model
+
+type GetCompetitionRankingDiffParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	Version int64 `form:"version" binding:"min=0"` // 客户端当前的排行榜版本, 0 表示获取完整排行榜
+}
+
+// RankingDiff 两个排行榜版本之间的差异
+type RankingDiff struct {
+	Version int64     `json:"version"` // 当前排行榜版本
+	Full    bool      `json:"full"`    // 客户端版本过旧或为 0 时返回完整排行榜, 客户端应整体替换本地数据
+	Rows    []Ranking `json:"rows"`    // 新增或发生变化的行
+	Removed []uint64  `json:"removed"` // 移出排行榜的用户 ID
+}
+
+// RankingChangeLog 排行榜变更日志, 按版本号保存在 Redis 中
+type RankingChangeLog struct {
+	Version int64     `json:"version"`
+	Rows    []Ranking `json:"rows"`
+	Removed []uint64  `json:"removed"`
+}
+
+type RankingEventParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	Version int64 `form:"version" binding:"min=0"` // 客户端当前的排行榜版本, 连接建立后先推送该版本之后的差异
+}
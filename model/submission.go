@@ -1,13 +1,18 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+)
 
 type SubmitCompetitionProblemParam struct {
 	CompetitionCommonParam `json:"-"`
 
 	Code      string `json:"code" binding:"required"`
-	Language  int8   `json:"language" binding:"required,oneof=0 1 2 3 4"`
+	Language  int8   `json:"language" binding:"min=0"` // 提交语言, 是否允许由语言注册表与比赛设置决定
 	ProblemID uint64 `json:"problem_id" binding:"required"`
+	Upsolve   bool   `json:"-"` // 是否为赛后补题提交, 由服务端根据比赛时间与设置判断
 }
 
 type Submission struct {
@@ -22,6 +27,12 @@ type Submission struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// SubmitRateLimitedResponse 提交被限流时返回
+type SubmitRateLimitedResponse struct {
+	RetryAfter int64  `json:"retry_after"` // 需要等待的时间, 单位: 毫秒
+	Scope      string `json:"scope"`       // 触发限流的规则 ( interval: 最小间隔, user: 选手提交次数, problem: 题目提交次数 )
+}
+
 type GetLatestSubmissionParam struct {
 	CompetitionCommonParam `json:"-"`
 
@@ -31,3 +42,100 @@ type GetLatestSubmissionParam struct {
 type GetLatestSubmissionResponse struct {
 	Submission
 }
+
+// CleanedSubmissionCode 失败提交的代码被 CleanUserFailedSubmission 清理后的占位内容
+const CleanedSubmissionCode = "**代码已被清理**"
+
+type GetSubmissionListParam struct {
+	CommonParam `json:"-"`
+
+	CompetitionID *uint64                     `form:"competition_id"`                                   // 按比赛查询
+	UserID        *uint64                     `form:"user_id"`                                          // 按用户查询
+	ProblemID     *uint64                     `form:"problem_id"`                                       // 按题目查询
+	Language      *ojmodel.SubmissionLanguage `form:"language" binding:"omitempty,min=0"`               // 按提交语言查询
+	Result        *ojmodel.SubmissionResult   `form:"result" binding:"omitempty,oneof=0 1 2 3 4 5 6 7"` // 按判题结果查询
+	CodeHash      string                      `form:"code_hash" binding:"omitempty,len=64,hexadecimal"` // 按规范化代码的摘要查询相同代码
+	StartTime     *time.Time                  `form:"start_time"`                                       // 提交时间下限 ( 包含 ), RFC3339 格式
+	EndTime       *time.Time                  `form:"end_time"`                                         // 提交时间上限 ( 不包含 ), RFC3339 格式
+
+	Page     int `form:"page" binding:"required,min=1"`               // 分页页码
+	PageSize int `form:"page_size" binding:"required,min=10,max=100"` // 分页每页数量
+}
+
+// SubmissionListItem 提交列表中的提交记录, 不包含代码与错误输出
+type SubmissionListItem struct {
+	ID            uint64    `gorm:"column:id" json:"id"`
+	CompetitionID uint64    `gorm:"column:competition_id" json:"competition_id"`
+	ProblemID     uint64    `gorm:"column:problem_id" json:"problem_id"`
+	UserID        uint64    `gorm:"column:user_id" json:"user_id"`
+	Username      string    `gorm:"column:username" json:"username"`
+	Realname      string    `gorm:"column:realname" json:"realname"`
+	Language      int8      `gorm:"column:language" json:"language"`
+	Status        int8      `gorm:"column:status" json:"status"`
+	Result        int8      `gorm:"column:result" json:"result"`
+	TimeUsed      int       `gorm:"column:time_used" json:"time_used"`     // 判题时间 ( 单位: 毫秒 ), -1 表示未判题
+	MemoryUsed    int       `gorm:"column:memory_used" json:"memory_used"` // 判题内存 ( 单位: KB ), -1 表示未判题
+	CodeLength    int       `gorm:"column:code_length" json:"code_length"` // 代码长度 ( 字符数 )
+	CodeHash      string    `gorm:"column:code_hash" json:"code_hash"`     // 规范化代码的摘要, 早于摘要功能的提交为空
+	CreatedAt     time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+type GetSubmissionListResponse struct {
+	Total    int                  `json:"total"`     // 总记录数
+	List     []SubmissionListItem `json:"list"`      // 记录列表
+	Page     int                  `json:"page"`      // 分页页码
+	PageSize int                  `json:"page_size"` // 分页每页数量
+}
+
+// SubmissionDetail 提交详情
+type SubmissionDetail struct {
+	SubmissionListItem `json:",inline"`
+	Code               string `gorm:"column:code" json:"code"`
+	Stderr             string `gorm:"column:stderr" json:"stderr"`
+	Cleaned            bool   `gorm:"-" json:"cleaned"` // 代码是否已被清理
+}
+
+type GetSubmissionDetailParam struct {
+	CommonParam `json:"-"`
+
+	SubmissionID uint64 `form:"submission_id" binding:"required"`
+}
+
+type DownloadSubmissionCodeParam struct {
+	CommonParam `json:"-"`
+
+	SubmissionID uint64 `form:"submission_id" binding:"required"`
+}
+
+// SubmissionFileExt 提交语言对应的源文件扩展名
+func SubmissionFileExt(language int8) string {
+	switch ojmodel.SubmissionLanguage(language) {
+	case ojmodel.SubmissionLanguageC:
+		return ".c"
+	case ojmodel.SubmissionLanguageCPP:
+		return ".cpp"
+	case ojmodel.SubmissionLanguagePython:
+		return ".py"
+	case ojmodel.SubmissionLanguageJava:
+		return ".java"
+	case ojmodel.SubmissionLanguageGo:
+		return ".go"
+	default:
+		return ".txt"
+	}
+}
+
+type UserGetSubmissionListParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	ProblemID *uint64 `form:"problem_id"` // 只查询指定题目的提交
+
+	Page     int `form:"page" binding:"required,min=1"`               // 分页页码
+	PageSize int `form:"page_size" binding:"required,min=10,max=100"` // 分页每页数量
+}
+
+type UserGetSubmissionDetailParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	SubmissionID uint64 `form:"submission_id" binding:"required"`
+}
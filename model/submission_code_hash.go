@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// DuplicateSubmitMode 重复提交相同代码时的处理方式
+type DuplicateSubmitMode int8
+
+const (
+	DuplicateSubmitModeOff    DuplicateSubmitMode = iota // 不检查
+	DuplicateSubmitModeWarn                              // 允许提交, 在响应中提示
+	DuplicateSubmitModeReject                            // 拒绝提交
+)
+
+// SubmissionCodeHash 提交代码规范化后的摘要, 与 submission 表一对一
+type SubmissionCodeHash struct {
+	SubmissionID  uint64    `gorm:"column:submission_id;type:bigint unsigned;primaryKey" json:"submission_id"`                                       // 提交 ID
+	CompetitionID uint64    `gorm:"column:competition_id;type:bigint unsigned;index:idx_submission_code_hash_user,priority:1" json:"competition_id"` // 比赛 ID
+	ProblemID     uint64    `gorm:"column:problem_id;type:bigint unsigned;index:idx_submission_code_hash_user,priority:2" json:"problem_id"`         // 题目 ID
+	UserID        uint64    `gorm:"column:user_id;type:bigint unsigned;index:idx_submission_code_hash_user,priority:3" json:"user_id"`               // 用户 ID
+	Language      int8      `gorm:"column:language;type:tinyint" json:"language"`                                                                    // 提交语言
+	CodeHash      string    `gorm:"column:code_hash;type:char(64);not null;index:idx_submission_code_hash_user,priority:4" json:"code_hash"`         // 去除注释与多余空白后代码的 SHA-256 摘要
+	CreatedAt     time.Time `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`                                       // 创建时间
+}
+
+func (SubmissionCodeHash) TableName() string {
+	return "submission_code_hash"
+}
+
+// DuplicateSubmission 与本次提交代码相同的已判题提交
+type DuplicateSubmission struct {
+	SubmissionID uint64    `gorm:"column:submission_id" json:"submission_id"` // 之前的提交 ID
+	Result       int8      `gorm:"column:result" json:"result"`               // 之前提交的判题结果
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`       // 之前提交的时间
+}
+
+// SubmitCompetitionProblemResponse 提交成功时返回, 开启重复提交提示时携带相同代码的已判题提交
+type SubmitCompetitionProblemResponse struct {
+	Duplicate *DuplicateSubmission `json:"duplicate,omitempty"`
+	Upsolve   bool                 `json:"upsolve"` // 是否为赛后补题提交, 不计入正式排行榜
+}
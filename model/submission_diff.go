@@ -0,0 +1,34 @@
+package model
+
+// SubmissionDiffOption 代码对比的空白规范化选项, 只影响两行是否相同的判断;
+// Python 的缩进影响语义, 对比 Python 代码时始终比较行首缩进
+type SubmissionDiffOption struct {
+	IgnoreTrailingSpace bool `form:"ignore_trailing_space"`                     // 忽略行尾空白
+	IgnoreSpaceChange   bool `form:"ignore_space_change"`                       // 连续空白视为一个空格
+	IgnoreAllSpace      bool `form:"ignore_all_space"`                          // 忽略全部空白
+	Context             *int `form:"context" binding:"omitempty,min=0,max=100"` // 每处修改前后保留的相同行数, 默认 3
+}
+
+type GetSubmissionDiffParam struct {
+	CommonParam          `json:"-"`
+	SubmissionDiffOption `json:"-"`
+
+	LeftSubmissionID  uint64 `form:"left_submission_id" binding:"required"`  // 旧提交 ID
+	RightSubmissionID uint64 `form:"right_submission_id" binding:"required"` // 新提交 ID
+}
+
+type UserGetSubmissionDiffParam struct {
+	CompetitionCommonParam `json:"-"`
+	SubmissionDiffOption   `json:"-"`
+
+	LeftSubmissionID  uint64 `form:"left_submission_id" binding:"required"`  // 旧提交 ID
+	RightSubmissionID uint64 `form:"right_submission_id" binding:"required"` // 新提交 ID
+}
+
+// SubmissionDiff 两次提交代码的差异
+type SubmissionDiff struct {
+	Left      SubmissionListItem `json:"left"`
+	Right     SubmissionListItem `json:"right"`
+	Identical bool               `json:"identical"` // 按选项规范化空白后两份代码是否相同
+	Diff      string             `json:"diff"`      // 统一格式 ( unified diff ) 的差异, 相同时为空
+}
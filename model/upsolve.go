@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// UpsolveSubmission 赛后补题提交标记, 与 submission 表一对一, 有记录的提交不计入正式排行榜与题目统计,
+// 判题服务同样需要跳过这些提交的排行榜更新
+type UpsolveSubmission struct {
+	SubmissionID  uint64    `gorm:"column:submission_id;type:bigint unsigned;primaryKey" json:"submission_id"`                            // 提交 ID
+	CompetitionID uint64    `gorm:"column:competition_id;type:bigint unsigned;not null;index:idx_competition_user" json:"competition_id"` // 比赛 ID
+	ProblemID     uint64    `gorm:"column:problem_id;type:bigint unsigned;not null" json:"problem_id"`                                    // 题目 ID
+	UserID        uint64    `gorm:"column:user_id;type:bigint unsigned;not null;index:idx_competition_user" json:"user_id"`               // 用户 ID
+	CreatedAt     time.Time `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"`                            // 创建时间
+}
+
+func (UpsolveSubmission) TableName() string {
+	return "submission_upsolve"
+}
+
+type GetCompetitionUpsolveRankingListParam struct {
+	CompetitionCommonParam `json:"-"`
+
+	Page     int     `form:"page" binding:"required,min=1"`
+	PageSize int     `form:"page_size" binding:"required,min=10,max=100"`
+	Category *string `form:"category" binding:"omitempty,max=32"` // 按选手分类筛选, 空字符串表示默认分类
+}
+
+type GetCompetitionUpsolveRankingListResponse struct {
+	Problems []CompetitionProblemItem `json:"problems"` // 按顺序排列的比赛题目, 作为排行榜表头
+	List     []Ranking                `json:"list"`
+	Total    int                      `json:"total"`
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"page_size"`
+}
+
+// UserCompetitionStatistics 用户在单场比赛中的提交统计, 比赛期间与赛后补题分开计算
+type UserCompetitionStatistics struct {
+	CompetitionID          uint64 `json:"competition_id"`
+	CompetitionName        string `json:"competition_name"`
+	SubmissionCount        int    `json:"submission_count"`         // 比赛期间的已判题提交数
+	SolvedCount            int    `json:"solved_count"`             // 比赛期间通过的题目数
+	UpsolveSubmissionCount int    `json:"upsolve_submission_count"` // 赛后补题的已判题提交数
+	UpsolveSolvedCount     int    `json:"upsolve_solved_count"`     // 赛后补题通过的题目数, 不含比赛期间已通过的题目
+}
+
+// UserStatistics 用户提交统计, 汇总全部比赛
+type UserStatistics struct {
+	UserID                 uint64                      `json:"user_id"`
+	SubmissionCount        int                         `json:"submission_count"`
+	SolvedCount            int                         `json:"solved_count"`
+	UpsolveSubmissionCount int                         `json:"upsolve_submission_count"`
+	UpsolveSolvedCount     int                         `json:"upsolve_solved_count"`
+	Competitions           []UserCompetitionStatistics `json:"competitions"` // 按比赛 ID 降序排列, 比赛 ID 为 0 的是练习提交
+}
+
+type GetUserStatisticsParam struct {
+	CommonParam `json:"-"`
+
+	UserID uint64 `form:"user_id" binding:"required"`
+}
+
+type UserGetStatisticsParam struct {
+	CompetitionCommonParam `json:"-"`
+}
@@ -29,6 +29,8 @@ type AddUsersToCompetition struct {
 
 	CompetitionID uint64   `form:"competition_id" binding:"required"` // 竞赛ID
 	UserIDList    []uint64 `json:"user_id_list"`                      // 用户ID, 仅当管理页面选择用户时使用
+	Category      string   `form:"category" binding:"max=32"`         // 选手分类, 为空表示默认分类
+	Unofficial    bool     `form:"unofficial"`                        // 是否为打星选手
 }
 
 type AddUsersToCompetitionResponse struct {
@@ -81,16 +83,24 @@ type GetCompetitionUserListParam struct {
 	Username string                         `form:"username"`                                                // 按用户名查询, 前缀匹配
 	Realname string                         `form:"realname"`                                                // 按真实姓名查询, 全模糊匹配
 	Status   *ojmodel.CompetitionUserStatus `form:"status" binding:"omitempty,oneof=0 1"`                    // 按状态查询, 0: 正常, 1: 禁用
+	Category *string                        `form:"category"`                                                // 按选手分类查询
 
 	Page     int `form:"page" binding:"required,min=1"`               // 分页页码
 	PageSize int `form:"page_size" binding:"required,min=10,max=100"` // 分页每页数量
 }
 
 type GetCompetitionUserListResponse struct {
-	Total    int                       `json:"total"`     // 总记录数
-	List     []ojmodel.CompetitionUser `json:"list"`      // 记录列表
-	Page     int                       `json:"page"`      // 分页页码
-	PageSize int                       `json:"page_size"` // 分页每页数量
+	Total    int                   `json:"total"`     // 总记录数
+	List     []CompetitionUserItem `json:"list"`      // 记录列表
+	Page     int                   `json:"page"`      // 分页页码
+	PageSize int                   `json:"page_size"` // 分页每页数量
+}
+
+// CompetitionUserItem 带分类的比赛选手
+type CompetitionUserItem struct {
+	ojmodel.CompetitionUser `json:",inline"`
+	Category                string `json:"category"`   // 选手分类
+	Unofficial              bool   `json:"unofficial"` // 是否为打星选手
 }
 
 type CreateUserParam struct {
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// UserProfile 用户资料, 与 user 表一对一, 无记录时昵称为空
+type UserProfile struct {
+	UserID    uint64    `gorm:"column:user_id;type:bigint unsigned;primaryKey" json:"user_id"`             // 用户 ID
+	Nickname  string    `gorm:"column:nickname;type:varchar(32);not null;default:''" json:"nickname"`      // 昵称, 比赛使用昵称展示模式时代替姓名显示
+	CreatedAt time.Time `gorm:"column:created_at;type:datetime(3);autoCreateTime:milli" json:"created_at"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime(3);autoUpdateTime:milli" json:"updated_at"` // 更新时间
+}
+
+func (UserProfile) TableName() string {
+	return "user_profile"
+}
+
+type UpdateUserNicknameParam struct {
+	CommonParam `json:"-"`
+
+	UserID   uint64 `json:"user_id" binding:"required"` // 用户ID
+	Nickname string `json:"nickname" binding:"max=32"`  // 昵称, 为空表示清除昵称
+}
@@ -0,0 +1,217 @@
+package gintool
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/online_judge_controller/constants"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+const (
+	idempotencyKey            = "idempotency:%s:%s:%s" // 操作人, 请求路径, Idempotency-Key
+	idempotencyProcessingTTL  = time.Minute            // 处理中标记的过期时间, 避免进程异常退出后永久占用
+	idempotencyRenewInterval  = 20 * time.Second       // 处理期间续期处理中标记的间隔, 小于过期时间
+	idempotencyMaxKeyLength   = 128
+	DefaultIdempotencyTTL     = 24 * time.Hour
+	idempotencyStateProcessed = "processed"
+	idempotencyStateRunning   = "processing"
+)
+
+// renewIdempotencyScript 标记仍为本次请求写入的处理中标记时续期
+const renewIdempotencyScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`
+
+// idempotencyRecord 幂等记录, 处理完成后保存原始响应
+type idempotencyRecord struct {
+	State    string `json:"state"`
+	Hash     string `json:"hash"` // 请求参数的摘要, 同一个 key 对应不同参数时拒绝
+	Response []byte `json:"response"`
+}
+
+type idempotencyStore struct {
+	rdb redis.Cmdable
+	ttl time.Duration
+}
+
+var idempotency *idempotencyStore
+
+// SetIdempotencyStore 开启 Idempotency-Key 支持, 未设置时包装函数忽略该请求头
+func SetIdempotencyStore(rdb redis.Cmdable, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	idempotency = &idempotencyStore{
+		rdb: rdb,
+		ttl: ttl,
+	}
+}
+
+// responseRecorder 记录写入的响应体, 用于重复请求时回放
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// runIdempotent 对携带 Idempotency-Key 的修改类请求保证只执行一次
+// 有效期内的重复请求直接回放原始响应, 仍在处理中的重复请求返回 409, 同一个 key 携带不同参数返回 422
+// 只保存成功 ( 响应码为 2xx ) 的响应, 限流、冲突、鉴权失败等响应不保存, 允许客户端使用同一个 key 重试;
+// 处理期间定期续期处理中标记, 避免耗时较长的请求被重复执行; Redis 不可用时直接执行
+// operator 区分不同操作人, 避免不同用户使用相同的 key 互相影响
+func runIdempotent(c *gin.Context, operator string, param any, log loggerv2.Logger, handle func()) {
+	key := c.GetHeader(constants.HeaderIdempotencyKey)
+	if idempotency == nil || key == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		handle()
+		return
+	}
+	if len(key) > idempotencyMaxKeyLength {
+		GinResponse(c, &Response{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("%s header is too long", constants.HeaderIdempotencyKey),
+		})
+		return
+	}
+
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.String("idempotency_key", key))
+	paramBytes, _ := json.Marshal(param)
+	hash := sha256.Sum256(paramBytes)
+	record := idempotencyRecord{
+		State: idempotencyStateRunning,
+		Hash:  hex.EncodeToString(hash[:]),
+	}
+	recordKey := fmt.Sprintf(idempotencyKey, operator, c.FullPath(), key)
+
+	recordBytes, _ := json.Marshal(record)
+	ok, err := idempotency.rdb.SetNX(ctx, recordKey, recordBytes, idempotencyProcessingTTL).Result()
+	if err != nil {
+		log.WarnContext(ctx, "runIdempotent set processing record failed, skip idempotency check", logger.Error(err))
+		handle()
+		return
+	}
+	if !ok {
+		replayIdempotent(ctx, c, recordKey, record.Hash, log)
+		return
+	}
+
+	completed := false
+	defer func() {
+		if !completed {
+			// 处理失败或发生 panic 时删除标记, 允许客户端重试
+			idempotency.rdb.Del(context.WithoutCancel(ctx), recordKey)
+		}
+	}()
+
+	done := make(chan struct{})
+	go renewIdempotent(context.WithoutCancel(ctx), recordKey, recordBytes, done, log)
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	func() {
+		defer close(done)
+		handle()
+	}()
+	c.Writer = recorder.ResponseWriter
+
+	var resp Response
+	if err = json.Unmarshal(recorder.body.Bytes(), &resp); err != nil || resp.Code < http.StatusOK || resp.Code >= http.StatusMultipleChoices {
+		return
+	}
+	record.State = idempotencyStateProcessed
+	record.Response = recorder.body.Bytes()
+	recordBytes, _ = json.Marshal(record)
+	err = idempotency.rdb.Set(context.WithoutCancel(ctx), recordKey, recordBytes, idempotency.ttl).Err()
+	if err != nil {
+		log.ErrorContext(ctx, "runIdempotent save response failed", logger.Error(err))
+		return
+	}
+	completed = true
+}
+
+// renewIdempotent 在请求处理完成前定期续期处理中标记
+func renewIdempotent(ctx context.Context, recordKey string, recordBytes []byte, done <-chan struct{}, log loggerv2.Logger) {
+	ticker := time.NewTicker(idempotencyRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			err := idempotency.rdb.Eval(ctx, renewIdempotencyScript, []string{recordKey},
+				string(recordBytes), idempotencyProcessingTTL.Milliseconds()).Err()
+			if err != nil {
+				log.WarnContext(ctx, "renewIdempotent renew processing record failed", logger.Error(err))
+			}
+		}
+	}
+}
+
+// replayIdempotent 处理重复请求
+func replayIdempotent(ctx context.Context, c *gin.Context, recordKey, hash string, log loggerv2.Logger) {
+	recordBytes, err := idempotency.rdb.Get(ctx, recordKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// 原请求恰好处理失败并删除了标记, 由客户端重试
+		GinResponse(c, &Response{
+			Code:    http.StatusConflict,
+			Message: "request with the same Idempotency-Key failed, please retry",
+		})
+		return
+	} else if err != nil {
+		GinResponse(c, &Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		log.ErrorContext(ctx, "replayIdempotent get record failed", logger.Error(err))
+		return
+	}
+
+	var record idempotencyRecord
+	if err = json.Unmarshal(recordBytes, &record); err != nil {
+		GinResponse(c, &Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		log.ErrorContext(ctx, "replayIdempotent unmarshal record failed", logger.Error(err))
+		return
+	}
+	if record.Hash != hash {
+		GinResponse(c, &Response{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Idempotency-Key has been used with different parameters",
+		})
+		return
+	}
+	if record.State != idempotencyStateProcessed {
+		GinResponse(c, &Response{
+			Code:    http.StatusConflict,
+			Message: "request with the same Idempotency-Key is being processed",
+		})
+		return
+	}
+
+	c.Header(constants.HeaderIdempotentReplayedKey, "true")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", record.Response)
+}
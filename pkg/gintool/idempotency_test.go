@@ -0,0 +1,191 @@
+package gintool
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/online_judge_controller/constants"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// memoryRedis 只实现幂等记录所需命令的内存 Redis 替身, 不处理过期时间
+type memoryRedis struct {
+	redis.Cmdable
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func newMemoryRedis() *memoryRedis {
+	return &memoryRedis{data: make(map[string]string)}
+}
+
+func (r *memoryRedis) SetNX(ctx context.Context, key string, value any, expiration time.Duration) *redis.BoolCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cmd := redis.NewBoolCmd(ctx)
+	if _, ok := r.data[key]; ok {
+		cmd.SetVal(false)
+		return cmd
+	}
+	r.data[key] = string(value.([]byte))
+	cmd.SetVal(true)
+	return cmd
+}
+
+func (r *memoryRedis) Set(ctx context.Context, key string, value any, expiration time.Duration) *redis.StatusCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data[key] = string(value.([]byte))
+	cmd := redis.NewStatusCmd(ctx)
+	cmd.SetVal("OK")
+	return cmd
+}
+
+func (r *memoryRedis) Get(ctx context.Context, key string) *redis.StringCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cmd := redis.NewStringCmd(ctx)
+	value, ok := r.data[key]
+	if !ok {
+		cmd.SetErr(redis.Nil)
+		return cmd
+	}
+	cmd.SetVal(value)
+	return cmd
+}
+
+func (r *memoryRedis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		delete(r.data, key)
+	}
+	return redis.NewIntCmd(ctx)
+}
+
+func TestRunIdempotent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := loggerv2.NewLoggerAdapter(logger.NewNopLogger())
+	const path = "/competition/transit"
+
+	type request struct {
+		key      string
+		param    string
+		code     int // 业务处理返回的响应码
+		wantCode int // 客户端收到的响应码
+		wantRun  bool
+		replayed bool
+	}
+	tests := []struct {
+		name     string
+		seed     *idempotencyRecord // 预先写入的幂等记录, 模拟并发中的请求
+		requests []request
+	}{
+		{
+			name: "未携带 key 时每次都执行",
+			requests: []request{
+				{param: "a", code: http.StatusOK, wantCode: http.StatusOK, wantRun: true},
+				{param: "a", code: http.StatusOK, wantCode: http.StatusOK, wantRun: true},
+			},
+		},
+		{
+			name: "重复请求回放原始响应",
+			requests: []request{
+				{key: "k1", param: "a", code: http.StatusOK, wantCode: http.StatusOK, wantRun: true},
+				{key: "k1", param: "a", code: http.StatusOK, wantCode: http.StatusOK, replayed: true},
+			},
+		},
+		{
+			name: "同一个 key 携带不同参数返回 422",
+			requests: []request{
+				{key: "k1", param: "a", code: http.StatusOK, wantCode: http.StatusOK, wantRun: true},
+				{key: "k1", param: "b", code: http.StatusOK, wantCode: http.StatusUnprocessableEntity},
+			},
+		},
+		{
+			name: "原请求仍在处理中返回 409",
+			seed: &idempotencyRecord{State: idempotencyStateRunning, Hash: paramHash(t, "a")},
+			requests: []request{
+				{key: "k1", param: "a", code: http.StatusOK, wantCode: http.StatusConflict},
+			},
+		},
+		{
+			name: "失败的响应不保存, 允许使用同一个 key 重试",
+			requests: []request{
+				{key: "k1", param: "a", code: http.StatusTooManyRequests, wantCode: http.StatusTooManyRequests, wantRun: true},
+				{key: "k1", param: "a", code: http.StatusOK, wantCode: http.StatusOK, wantRun: true},
+				{key: "k1", param: "a", code: http.StatusOK, wantCode: http.StatusOK, replayed: true},
+			},
+		},
+		{
+			name: "key 过长返回 400",
+			requests: []request{
+				{key: strings.Repeat("k", idempotencyMaxKeyLength+1), param: "a", code: http.StatusOK, wantCode: http.StatusBadRequest},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdb := newMemoryRedis()
+			SetIdempotencyStore(rdb, time.Hour)
+			t.Cleanup(func() { idempotency = nil })
+			if tt.seed != nil {
+				seedBytes, _ := json.Marshal(tt.seed)
+				rdb.data["idempotency:1:"+path+":k1"] = string(seedBytes)
+			}
+
+			for i, req := range tt.requests {
+				run := false
+				r := gin.New()
+				r.POST(path, func(c *gin.Context) {
+					runIdempotent(c, "1", req.param, log, func() {
+						run = true
+						GinResponse(c, &Response{Code: req.code, Message: "handled"})
+					})
+				})
+				httpReq := httptest.NewRequest(http.MethodPost, path, nil)
+				if req.key != "" {
+					httpReq.Header.Set(constants.HeaderIdempotencyKey, req.key)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httpReq)
+
+				var resp Response
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("request %d: unmarshal response %q failed: %v", i, w.Body.String(), err)
+				}
+				if resp.Code != req.wantCode {
+					t.Errorf("request %d: code = %d, want %d", i, resp.Code, req.wantCode)
+				}
+				if run != req.wantRun {
+					t.Errorf("request %d: handler run = %v, want %v", i, run, req.wantRun)
+				}
+				if got := w.Header().Get(constants.HeaderIdempotentReplayedKey) == "true"; got != req.replayed {
+					t.Errorf("request %d: replayed = %v, want %v", i, got, req.replayed)
+				}
+			}
+		})
+	}
+}
+
+// paramHash 与 runIdempotent 一致的请求参数摘要
+func paramHash(t *testing.T, param any) string {
+	t.Helper()
+	paramBytes, err := json.Marshal(param)
+	if err != nil {
+		t.Fatalf("marshal param failed: %v", err)
+	}
+	hash := sha256.Sum256(paramBytes)
+	return hex.EncodeToString(hash[:])
+}
@@ -102,7 +102,9 @@ func WrapHandler[T model.CommonParamInterface](h func(c *gin.Context, pType T),
 			return
 		}
 
-		h(c, param)
+		runIdempotent(c, c.GetHeader(constants.HeaderUserIDKey), param, log, func() {
+			h(c, param)
+		})
 	}
 }
 
@@ -229,7 +231,10 @@ func WrapCompetitionHandler[T model.CompetitionCommonParamInterface](h func(c *g
 		param.SetOperator(competitionClaims.UserId)
 		param.SetCompetitionID(competitionClaims.CompetitionID)
 
-		h(c, param)
+		operator := fmt.Sprintf("%d:%d", competitionClaims.CompetitionID, competitionClaims.UserId)
+		runIdempotent(c, operator, param, log, func() {
+			h(c, param)
+		})
 	}
 }
 
@@ -278,6 +283,26 @@ func WrapCompetitionSSEHandler[T model.CompetitionCommonParamInterface](h func(c
 			param = reflect.New(rv.Type().Elem()).Interface().(T)
 		}
 
+		if c.Request.URL != nil && c.Request.URL.RawQuery != "" {
+			err := binding.Query.Bind(c.Request, param)
+			if err != nil {
+				GinResponse(c, &Response{
+					Code:    http.StatusBadRequest,
+					Message: err.Error(),
+				})
+				log.ErrorContext(c.Request.Context(), "WrapCompetitionSSEHandler bind query failed", logger.Error(err))
+				return
+			}
+		}
+		if err := Validator.Struct(param); err != nil {
+			GinResponse(c, &Response{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			log.ErrorContext(c.Request.Context(), "WrapCompetitionSSEHandler validate failed", logger.Error(err))
+			return
+		}
+
 		userClaims, exists := c.Get(constants.ContextCompetitionClaimsKey)
 		if !exists {
 			GinResponse(c, &Response{
@@ -335,3 +360,34 @@ func WrapCompetitionSSEHandler[T model.CompetitionCommonParamInterface](h func(c
 		}
 	}
 }
+
+// WrapPublicHandler 包装无需登录的只读处理函数, 只绑定 Query 参数, 不提取操作人
+func WrapPublicHandler[T any](h func(c *gin.Context, pType *T), log loggerv2.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		param := new(T)
+
+		if c.Request.URL != nil && c.Request.URL.RawQuery != "" {
+			err := binding.Query.Bind(c.Request, param)
+			if err != nil {
+				GinResponse(c, &Response{
+					Code:    http.StatusBadRequest,
+					Message: err.Error(),
+				})
+				log.ErrorContext(c.Request.Context(), "WrapPublicHandler bind query failed", logger.Error(err))
+				return
+			}
+		}
+
+		err := Validator.Struct(param)
+		if err != nil {
+			GinResponse(c, &Response{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			log.ErrorContext(c.Request.Context(), "WrapPublicHandler validate failed", logger.Error(err))
+			return
+		}
+
+		h(c, param)
+	}
+}
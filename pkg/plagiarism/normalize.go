@@ -0,0 +1,89 @@
+package plagiarism
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// Normalize 去除注释并压缩空白, 标识符与字面量保留原文, 用于判断两份代码是否实质相同
+// Python 的缩进与预处理指令的换行影响语义, 予以保留; 两个标识符或两个符号之间的空白压缩为一个空格,
+// 以区分 a - -b 与 a--b 这类去掉空白后会合并为其他运算符的写法
+func Normalize(code string, syntax *Syntax) string {
+	src := []rune(code)
+	keepIndent := syntax == PythonSyntax
+
+	var b strings.Builder
+	var last rune
+	lineStart, space, directive := true, false, false
+	indent := make([]rune, 0)
+	for i := 0; i < len(src); {
+		r := src[i]
+		if r == '\n' {
+			if (keepIndent || directive) && b.Len() > 0 && last != '\n' {
+				b.WriteRune('\n')
+				last = '\n'
+			}
+			lineStart, space, directive = true, false, false
+			indent = indent[:0]
+			i++
+			continue
+		}
+		if unicode.IsSpace(r) {
+			if lineStart {
+				indent = append(indent, r)
+			}
+			space = true
+			i++
+			continue
+		}
+		if prefix, ok := matchAny(src, i, syntax.LineComments); ok {
+			i = skipLine(src, i+len([]rune(prefix)))
+			continue
+		}
+		if end, ok := matchBlockComment(src, i, syntax.BlockComments); ok {
+			space = true
+			i = end
+			continue
+		}
+
+		start := i
+		if quote, ok := matchAny(src, i, syntax.Quotes); ok {
+			i = skipString(src, i, []rune(quote))
+		} else if isIdentRune(r) {
+			for i < len(src) && isIdentRune(src[i]) {
+				i++
+			}
+		} else {
+			i++
+		}
+
+		if lineStart {
+			directive = syntax.Preprocessor && r == '#'
+			if keepIndent {
+				b.WriteString(string(indent))
+			} else if b.Len() > 0 && last != '\n' && needSeparator(last, r) {
+				b.WriteRune(' ')
+			}
+		} else if space && needSeparator(last, r) {
+			b.WriteRune(' ')
+		}
+		b.WriteString(string(src[start:i]))
+		last = src[i-1]
+		lineStart, space = false, false
+	}
+	return b.String()
+}
+
+// needSeparator 两个记号之间的空白是否有意义: 标识符与标识符之间的空白分隔两个标识符,
+// 符号与符号之间的空白避免 - -、+ + 等与 --、++ 混淆; 标识符与符号之间的空白可以去掉
+func needSeparator(last, next rune) bool {
+	return isIdentRune(last) == isIdentRune(next)
+}
+
+// CodeHash 规范化后代码的 SHA-256 摘要, 仅修改注释或空白的代码摘要相同
+func CodeHash(code string, syntax *Syntax) string {
+	hash := sha256.Sum256([]byte(Normalize(code, syntax)))
+	return hex.EncodeToString(hash[:])
+}
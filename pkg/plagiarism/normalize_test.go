@@ -0,0 +1,74 @@
+package plagiarism
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		syntax *Syntax
+		want   string
+	}{
+		{
+			name:   "去除注释并压缩空白",
+			code:   "int  main ( ) {\n\t// comment\n\treturn /* zero */ 0 ;\n}\n",
+			syntax: CSyntax,
+			want:   "int main( ) {return 0; }",
+		},
+		{
+			name:   "保留预处理指令的换行",
+			code:   "#include <stdio.h>\n#define N 10\nint a[N];",
+			syntax: CSyntax,
+			want:   "#include<stdio.h>\n#define N 10\nint a[N];",
+		},
+		{
+			name:   "相邻符号之间的空白压缩为一个空格",
+			code:   "a - -b; c + +d; e--;",
+			syntax: CSyntax,
+			want:   "a- -b;c+ +d;e--;",
+		},
+		{
+			name:   "字符串内的空白与注释符保持原样",
+			code:   `s = "a  // b"  ;`,
+			syntax: CSyntax,
+			want:   `s= "a  // b" ;`,
+		},
+		{
+			name:   "Python 保留缩进与换行",
+			code:   "def f(x):  # doc\n    return x\n\n\nprint(f(1))\n",
+			syntax: PythonSyntax,
+			want:   "def f(x):\n    return x\nprint(f(1))\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.code, tt.syntax); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeHash(t *testing.T) {
+	const base = "int main() { int a = 1; return a - -1; }"
+	tests := []struct {
+		name   string
+		code   string
+		syntax *Syntax
+		same   bool
+	}{
+		{"只改注释", "int main() { /* x */ int a = 1; // y\n return a - -1; }", CSyntax, true},
+		{"只改空白", "int main()  {\n\tint a=1;\n\treturn a -  -1;\n}", CSyntax, true},
+		{"合并为其他运算符", "int main() { int a = 1; return a--1; }", CSyntax, false},
+		{"改变量名", "int main() { int b = 1; return b - -1; }", CSyntax, false},
+		{"改常量", "int main() { int a = 2; return a - -1; }", CSyntax, false},
+	}
+	want := CodeHash(base, CSyntax)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeHash(tt.code, tt.syntax) == want; got != tt.same {
+				t.Errorf("CodeHash() same = %v, want %v", got, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package plagiarism
+
+import "unicode"
+
+// 规范化后的记号, 标识符、数字与字符串字面量只保留类别, 使改名、改常量不影响比较结果
+const (
+	TokenIdentifier = "V"
+	TokenNumber     = "N"
+	TokenString     = "S"
+)
+
+// Syntax 分词所需的语言语法
+type Syntax struct {
+	Family        string              // 语法族, 只有同一语法族的代码之间才进行比较
+	LineComments  []string            // 单行注释起始符
+	BlockComments [][2]string         // 多行注释起止符
+	Quotes        []string            // 字符串定界符, 较长的定界符需要排在前面
+	Preprocessor  bool                // 是否忽略以 # 开头的预处理行
+	Keywords      map[string]struct{} // 关键字原样保留, 其余标识符规范化为 TokenIdentifier
+}
+
+func keywords(words ...string) map[string]struct{} {
+	m := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		m[w] = struct{}{}
+	}
+	return m
+}
+
+var (
+	CSyntax = &Syntax{
+		Family:        "c",
+		LineComments:  []string{"//"},
+		BlockComments: [][2]string{{"/*", "*/"}},
+		Quotes:        []string{`"`, `'`},
+		Preprocessor:  true,
+		Keywords: keywords(
+			"auto", "bool", "break", "case", "catch", "char", "class", "const", "continue", "default", "delete", "do",
+			"double", "else", "enum", "extern", "float", "for", "goto", "if", "inline", "int", "long", "new",
+			"operator", "private", "protected", "public", "register", "return", "short", "signed", "sizeof", "static",
+			"struct", "switch", "template", "this", "throw", "try", "typedef", "typename", "union", "unsigned",
+			"using", "virtual", "void", "volatile", "while", "namespace", "true", "false", "nullptr",
+		),
+	}
+	JavaSyntax = &Syntax{
+		Family:        "java",
+		LineComments:  []string{"//"},
+		BlockComments: [][2]string{{"/*", "*/"}},
+		Quotes:        []string{`"""`, `"`, `'`},
+		Keywords: keywords(
+			"abstract", "boolean", "break", "byte", "case", "catch", "char", "class", "continue", "default", "do",
+			"double", "else", "enum", "extends", "final", "finally", "float", "for", "if", "implements", "import",
+			"instanceof", "int", "interface", "long", "new", "package", "private", "protected", "public", "return",
+			"short", "static", "super", "switch", "this", "throw", "throws", "try", "void", "while", "var", "true",
+			"false", "null",
+		),
+	}
+	PythonSyntax = &Syntax{
+		Family:       "python",
+		LineComments: []string{"#"},
+		Quotes:       []string{`"""`, `'''`, `"`, `'`},
+		Keywords: keywords(
+			"and", "as", "assert", "break", "class", "continue", "def", "del", "elif", "else", "except", "finally",
+			"for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal", "not", "or", "pass", "raise",
+			"return", "try", "while", "with", "yield", "True", "False", "None",
+		),
+	}
+	GoSyntax = &Syntax{
+		Family:        "go",
+		LineComments:  []string{"//"},
+		BlockComments: [][2]string{{"/*", "*/"}},
+		Quotes:        []string{"`", `"`, `'`},
+		Keywords: keywords(
+			"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func",
+			"go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct",
+			"switch", "type", "var", "true", "false", "nil",
+		),
+	}
+)
+
+// Tokenize 按语言语法将代码切分为规范化的记号序列, 忽略空白、注释与预处理行
+func Tokenize(code string, syntax *Syntax) []string {
+	src := []rune(code)
+	tokens := make([]string, 0, len(src)/3)
+	lineStart := true
+
+	for i := 0; i < len(src); {
+		r := src[i]
+		if r == '\n' {
+			lineStart = true
+			i++
+			continue
+		}
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+		if syntax.Preprocessor && lineStart && r == '#' {
+			i = skipLine(src, i)
+			continue
+		}
+		lineStart = false
+
+		if prefix, ok := matchAny(src, i, syntax.LineComments); ok {
+			i = skipLine(src, i+len([]rune(prefix)))
+			continue
+		}
+		if end, ok := matchBlockComment(src, i, syntax.BlockComments); ok {
+			i = end
+			continue
+		}
+		if quote, ok := matchAny(src, i, syntax.Quotes); ok {
+			i = skipString(src, i, []rune(quote))
+			tokens = append(tokens, TokenString)
+			continue
+		}
+
+		switch {
+		case unicode.IsDigit(r):
+			for i < len(src) && (isIdentRune(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, TokenNumber)
+		case isIdentRune(r):
+			start := i
+			for i < len(src) && isIdentRune(src[i]) {
+				i++
+			}
+			word := string(src[start:i])
+			if _, ok := syntax.Keywords[word]; ok {
+				tokens = append(tokens, word)
+			} else {
+				tokens = append(tokens, TokenIdentifier)
+			}
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// matchAny 判断 src[i:] 是否以给定前缀之一开头, 返回匹配的前缀
+func matchAny(src []rune, i int, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if hasPrefix(src, i, []rune(prefix)) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+func hasPrefix(src []rune, i int, prefix []rune) bool {
+	if len(prefix) == 0 || i+len(prefix) > len(src) {
+		return false
+	}
+	for j, r := range prefix {
+		if src[i+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// matchBlockComment 匹配多行注释, 返回注释结束后的位置, 注释未闭合时跳至末尾
+func matchBlockComment(src []rune, i int, comments [][2]string) (int, bool) {
+	for _, comment := range comments {
+		open, closing := []rune(comment[0]), []rune(comment[1])
+		if !hasPrefix(src, i, open) {
+			continue
+		}
+		for j := i + len(open); j < len(src); j++ {
+			if hasPrefix(src, j, closing) {
+				return j + len(closing), true
+			}
+		}
+		return len(src), true
+	}
+	return i, false
+}
+
+// skipLine 跳至行尾 ( 不含换行符 )
+func skipLine(src []rune, i int) int {
+	for i < len(src) && src[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+// skipString 跳过字符串字面量, 处理反斜杠转义, 字符串未闭合时跳至末尾
+func skipString(src []rune, i int, quote []rune) int {
+	for j := i + len(quote); j < len(src); j++ {
+		if src[j] == '\\' {
+			j++
+			continue
+		}
+		if hasPrefix(src, j, quote) {
+			return j + len(quote)
+		}
+	}
+	return len(src)
+}
@@ -0,0 +1,59 @@
+package plagiarism
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		syntax *Syntax
+		want   []string
+	}{
+		{
+			name:   "标识符与数字只保留类别",
+			code:   "int total = count + 10;",
+			syntax: CSyntax,
+			want:   []string{"int", "V", "=", "V", "+", "N", ";"},
+		},
+		{
+			name:   "忽略注释与预处理行",
+			code:   "#include <stdio.h>\n// comment\nreturn /* block */ x;",
+			syntax: CSyntax,
+			want:   []string{"return", "V", ";"},
+		},
+		{
+			name:   "字符串字面量",
+			code:   `s = "a # b" + 'c'`,
+			syntax: PythonSyntax,
+			want:   []string{"V", "=", "S", "+", "S"},
+		},
+		{
+			name:   "Python 三引号字符串与注释",
+			code:   "x = \"\"\"doc\nstring\"\"\" # tail\nreturn x",
+			syntax: PythonSyntax,
+			want:   []string{"V", "=", "S", "return", "V"},
+		},
+		{
+			name:   "非行首的 # 不作为预处理行",
+			code:   "a # b",
+			syntax: CSyntax,
+			want:   []string{"V", "#", "V"},
+		},
+		{
+			name:   "小数作为一个数字",
+			code:   "f := 3.14",
+			syntax: GoSyntax,
+			want:   []string{"V", ":", "=", "N"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.code, tt.syntax); !slices.Equal(got, tt.want) {
+				t.Errorf("Tokenize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
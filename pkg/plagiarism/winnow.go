@@ -0,0 +1,65 @@
+package plagiarism
+
+import "hash/fnv"
+
+const (
+	DefaultK      = 8 // 默认 k-gram 长度 ( 记号数 )
+	DefaultWindow = 4 // 默认 winnowing 窗口大小
+)
+
+// Fingerprint 代码指纹, 为 winnowing 选出的 k-gram 哈希集合
+type Fingerprint map[uint64]struct{}
+
+// Winnow 对记号序列计算 k-gram 哈希, 并在每个大小为 window 的窗口中选取最小哈希作为指纹,
+// 记号数不足 k 时以整个序列作为一个 k-gram
+func Winnow(tokens []string, k, window int) Fingerprint {
+	fp := make(Fingerprint)
+	if len(tokens) == 0 {
+		return fp
+	}
+	if len(tokens) < k {
+		k = len(tokens)
+	}
+
+	hashes := make([]uint64, 0, len(tokens)-k+1)
+	for i := 0; i+k <= len(tokens); i++ {
+		h := fnv.New64a()
+		for _, token := range tokens[i : i+k] {
+			h.Write([]byte(token))
+			h.Write([]byte{0})
+		}
+		hashes = append(hashes, h.Sum64())
+	}
+	if len(hashes) < window {
+		window = len(hashes)
+	}
+
+	for i := 0; i+window <= len(hashes); i++ {
+		// 取窗口内最右侧的最小值, 与原论文一致, 减少相邻窗口重复选择
+		minIdx := i
+		for j := i + 1; j < i+window; j++ {
+			if hashes[j] <= hashes[minIdx] {
+				minIdx = j
+			}
+		}
+		fp[hashes[minIdx]] = struct{}{}
+	}
+	return fp
+}
+
+// Similarity 两份指纹的 Jaccard 相似度, 取值范围 [0, 1]
+func Similarity(a, b Fingerprint) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for h := range a {
+		if _, ok := b[h]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
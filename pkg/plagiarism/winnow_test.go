@@ -0,0 +1,55 @@
+package plagiarism
+
+import "testing"
+
+func TestSimilarity(t *testing.T) {
+	const (
+		original = `int main() { int a, b; scanf("%d %d", &a, &b); printf("%d\n", a + b); return 0; }`
+		renamed  = `int main() { int x, y; /* read */ scanf("%d%d", &x, &y); printf("%d", x + y); return 0; }`
+		other    = `int main() { for (int i = 0; i < 10; i++) { if (i % 2 == 0) continue; puts("odd"); } }`
+	)
+	fingerprint := func(code string) Fingerprint {
+		return Winnow(Tokenize(code, CSyntax), DefaultK, DefaultWindow)
+	}
+	tests := []struct {
+		name    string
+		a, b    Fingerprint
+		wantMin float64
+		wantMax float64
+	}{
+		{"相同代码", fingerprint(original), fingerprint(original), 1, 1},
+		{"只改名与注释", fingerprint(original), fingerprint(renamed), 1, 1},
+		{"不同代码", fingerprint(original), fingerprint(other), 0, 0.3},
+		{"空指纹", Fingerprint{}, fingerprint(original), 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.a, tt.b)
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("Similarity() = %v, want in [%v, %v]", got, tt.wantMin, tt.wantMax)
+			}
+			if reverse := Similarity(tt.b, tt.a); reverse != got {
+				t.Errorf("Similarity() not symmetric: %v != %v", reverse, got)
+			}
+		})
+	}
+}
+
+func TestWinnow(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  []string
+		wantLen int
+	}{
+		{"空序列", nil, 0},
+		{"不足 k 个记号时整体作为一个 k-gram", []string{"int", "V", ";"}, 1},
+		{"k-gram 数不足窗口时取其中最小值", []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Winnow(tt.tokens, DefaultK, DefaultWindow); len(got) != tt.wantLen {
+				t.Errorf("len(Winnow()) = %d, want %d", len(got), tt.wantLen)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+)
+
+// DisplayMode 选手信息展示模式, 决定排行榜与导出中学号与姓名的显示方式
+type DisplayMode int8
+
+const (
+	DisplayModeFull      DisplayMode = iota // 显示学号与姓名
+	DisplayModeNickname                     // 只显示昵称
+	DisplayModeMasked                       // 学号脱敏, 显示姓名
+	DisplayModeAnonymous                    // 完全匿名
+)
+
+// AnonymousName 匿名显示或未设置昵称时替代选手姓名
+const AnonymousName = "匿名选手"
+
+func (m DisplayMode) String() string {
+	switch m {
+	case DisplayModeFull:
+		return "full"
+	case DisplayModeNickname:
+		return "nickname"
+	case DisplayModeMasked:
+		return "masked"
+	case DisplayModeAnonymous:
+		return "anonymous"
+	default:
+		return "unknown"
+	}
+}
+
+// Apply 按展示模式返回用于显示的学号与姓名
+func (m DisplayMode) Apply(username, realname, nickname string) (string, string) {
+	switch m {
+	case DisplayModeNickname:
+		if nickname == "" {
+			return "", AnonymousName
+		}
+		return "", nickname
+	case DisplayModeMasked:
+		return MaskUsername(username), realname
+	case DisplayModeAnonymous:
+		return "", AnonymousName
+	default:
+		return username, realname
+	}
+}
+
+// anonymousIDMask 匿名 ID 只保留低 53 位, 保证前端按 Number 解析时不丢失精度
+const anonymousIDMask = 1<<53 - 1
+
+// AnonymousID 用比赛的匿名密钥将用户 ID 映射为不可逆的匿名 ID, 同一密钥下结果稳定, 不同比赛之间无法关联.
+// 0 表示无人, 仍映射为 0
+func AnonymousID(key []byte, userID uint64) uint64 {
+	if userID == 0 {
+		return 0
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(binary.BigEndian.AppendUint64(nil, userID))
+	id := binary.BigEndian.Uint64(mac.Sum(nil)) & anonymousIDMask
+	if id == 0 {
+		return 1
+	}
+	return id
+}
+
+// MaskUsername 学号脱敏, 保留前 4 位与后 2 位, 长度不足 7 位时只保留首位
+func MaskUsername(username string) string {
+	runes := []rune(username)
+	switch {
+	case len(runes) == 0:
+		return ""
+	case len(runes) < 7:
+		return string(runes[:1]) + strings.Repeat("*", len(runes)-1)
+	default:
+		return string(runes[:4]) + strings.Repeat("*", len(runes)-6) + string(runes[len(runes)-2:])
+	}
+}
@@ -0,0 +1,82 @@
+package privacy
+
+import "testing"
+
+func TestMaskUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     string
+	}{
+		{"空学号", "", ""},
+		{"单字符", "a", "a"},
+		{"不足 7 位只保留首位", "123456", "1*****"},
+		{"7 位", "1234567", "1234*67"},
+		{"12 位学号", "202312345678", "2023******78"},
+		{"按字符而非字节脱敏", "学号一二三四五六", "学号一二**五六"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaskUsername(tt.username); got != tt.want {
+				t.Errorf("MaskUsername(%q) = %q, want %q", tt.username, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayModeApply(t *testing.T) {
+	const username, realname = "202312345678", "张三"
+	tests := []struct {
+		name         string
+		mode         DisplayMode
+		nickname     string
+		wantUsername string
+		wantRealname string
+	}{
+		{"完整显示", DisplayModeFull, "小张", username, realname},
+		{"昵称模式", DisplayModeNickname, "小张", "", "小张"},
+		{"昵称模式未设置昵称", DisplayModeNickname, "", "", AnonymousName},
+		{"学号脱敏", DisplayModeMasked, "小张", "2023******78", realname},
+		{"完全匿名", DisplayModeAnonymous, "小张", "", AnonymousName},
+		{"未知模式按完整显示", DisplayMode(99), "", username, realname},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUsername, gotRealname := tt.mode.Apply(username, realname, tt.nickname)
+			if gotUsername != tt.wantUsername || gotRealname != tt.wantRealname {
+				t.Errorf("Apply() = (%q, %q), want (%q, %q)", gotUsername, gotRealname, tt.wantUsername, tt.wantRealname)
+			}
+		})
+	}
+}
+
+func TestAnonymousID(t *testing.T) {
+	keyA, keyB := []byte("competition-a"), []byte("competition-b")
+	if got := AnonymousID(keyA, 0); got != 0 {
+		t.Errorf("AnonymousID(key, 0) = %d, want 0", got)
+	}
+	tests := []struct {
+		name   string
+		userID uint64
+	}{
+		{"普通用户", 1},
+		{"大 ID", 1<<63 + 12345},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := AnonymousID(keyA, tt.userID)
+			if id == 0 || id > anonymousIDMask {
+				t.Errorf("AnonymousID() = %d, want in (0, %d]", id, uint64(anonymousIDMask))
+			}
+			if id == tt.userID {
+				t.Errorf("AnonymousID() returned the real user id %d", id)
+			}
+			if again := AnonymousID(keyA, tt.userID); again != id {
+				t.Errorf("AnonymousID() not stable: %d != %d", again, id)
+			}
+			if other := AnonymousID(keyB, tt.userID); other == id {
+				t.Errorf("AnonymousID() equal across keys: %d", id)
+			}
+		})
+	}
+}
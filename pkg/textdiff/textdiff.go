@@ -0,0 +1,264 @@
+package textdiff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	MaxLines = 2000       // 单侧最大行数, 最长公共子序列表的大小为两侧行数之积
+	MaxBytes = 256 * 1024 // 单侧最大字节数
+)
+
+var ErrTooLarge = errors.New("text is too large to compare")
+
+// Kind 对比行的类型
+type Kind int8
+
+const (
+	KindEqual  Kind = iota // 两侧相同
+	KindChange             // 两侧均有内容但不同
+	KindDelete             // 只存在于左侧
+	KindInsert             // 只存在于右侧
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindEqual:
+		return "equal"
+	case KindChange:
+		return "change"
+	case KindDelete:
+		return "delete"
+	case KindInsert:
+		return "insert"
+	default:
+		return "unknown"
+	}
+}
+
+// Row 左右对照的一行, 行号从 1 开始, 为 0 表示该侧没有对应行
+type Row struct {
+	Kind      Kind   `json:"kind"`
+	LeftLine  int    `json:"left_line"`
+	Left      string `json:"left"`
+	RightLine int    `json:"right_line"`
+	Right     string `json:"right"`
+}
+
+// SplitLines 按行切分文本, 兼容 \r\n 换行, 忽略末尾换行
+func SplitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Options 对比时的空白规范化选项, 只影响两行是否相同的判断, 结果中保留原文
+type Options struct {
+	IgnoreTrailingSpace bool // 忽略行尾空白
+	IgnoreSpaceChange   bool // 连续空白视为一个空格, 并忽略行首行尾空白
+	IgnoreAllSpace      bool // 忽略全部空白
+	KeepIndent          bool // 始终比较行首缩进, 用于 Python 等缩进有语义的语言
+}
+
+// normalize 按选项规范化一行, 用作比较的键
+func (o Options) normalize(line string) string {
+	var indent string
+	if o.KeepIndent {
+		if body := strings.TrimLeft(line, " \t"); body != "" {
+			indent, line = line[:len(line)-len(body)], body
+		}
+	}
+	switch {
+	case o.IgnoreAllSpace:
+		line = strings.Join(strings.Fields(line), "")
+	case o.IgnoreSpaceChange:
+		line = strings.Join(strings.Fields(line), " ")
+	case o.IgnoreTrailingSpace:
+		line = strings.TrimRight(line, " \t\r\v\f")
+	}
+	return indent + line
+}
+
+// checkSize 检查一侧文本是否超过对比上限
+func checkSize(lines []string) error {
+	if len(lines) > MaxLines {
+		return fmt.Errorf("%w: %d lines, limit %d", ErrTooLarge, len(lines), MaxLines)
+	}
+	size := 0
+	for _, line := range lines {
+		size += len(line) + 1
+	}
+	if size > MaxBytes {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrTooLarge, size, MaxBytes)
+	}
+	return nil
+}
+
+// Compare 基于最长公共子序列逐行对比, 返回左右对照的结果;
+// 两个相同行之间的删除与插入按顺序配对为修改行, 任一侧超过 MaxLines 或 MaxBytes 时返回 ErrTooLarge
+func Compare(left, right []string) ([]Row, error) {
+	return CompareWithOptions(left, right, Options{})
+}
+
+// CompareWithOptions 与 Compare 相同, 但按选项规范化空白后再判断两行是否相同, 相同行输出左侧原文
+func CompareWithOptions(left, right []string, opts Options) ([]Row, error) {
+	if err := checkSize(left); err != nil {
+		return nil, err
+	}
+	if err := checkSize(right); err != nil {
+		return nil, err
+	}
+
+	leftKeys, rightKeys := left, right
+	if opts != (Options{}) {
+		leftKeys, rightKeys = make([]string, len(left)), make([]string, len(right))
+		for i, line := range left {
+			leftKeys[i] = opts.normalize(line)
+		}
+		for j, line := range right {
+			rightKeys[j] = opts.normalize(line)
+		}
+	}
+
+	n, m := len(left), len(right)
+	// lcs[i*(m+1)+j] 为 left[i:] 与 right[j:] 的最长公共子序列长度
+	lcs := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if leftKeys[i] == rightKeys[j] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+			}
+		}
+	}
+
+	rows := make([]Row, 0, max(n, m))
+	var deleted, inserted []int
+	flush := func() {
+		paired := min(len(deleted), len(inserted))
+		for k := 0; k < paired; k++ {
+			rows = append(rows, Row{Kind: KindChange, LeftLine: deleted[k] + 1, Left: left[deleted[k]], RightLine: inserted[k] + 1, Right: right[inserted[k]]})
+		}
+		for _, i := range deleted[paired:] {
+			rows = append(rows, Row{Kind: KindDelete, LeftLine: i + 1, Left: left[i]})
+		}
+		for _, j := range inserted[paired:] {
+			rows = append(rows, Row{Kind: KindInsert, RightLine: j + 1, Right: right[j]})
+		}
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && leftKeys[i] == rightKeys[j]:
+			flush()
+			rows = append(rows, Row{Kind: KindEqual, LeftLine: i + 1, Left: left[i], RightLine: j + 1, Right: right[j]})
+			i++
+			j++
+		case j == m || (i < n && lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]):
+			deleted = append(deleted, i)
+			i++
+		default:
+			inserted = append(inserted, j)
+			j++
+		}
+	}
+	flush()
+	return rows, nil
+}
+
+// Unified 将对比结果输出为统一格式 ( unified diff ), context 为每处修改前后保留的相同行数;
+// 没有差异时返回空字符串
+func Unified(rows []Row, leftName, rightName string, context int) string {
+	changed := make([]int, 0)
+	for i, row := range rows {
+		if row.Kind != KindEqual {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", leftName, rightName)
+	for k := 0; k < len(changed); {
+		start, end := max(changed[k]-context, 0), changed[k]
+		k++
+		// 两处修改之间的相同行不超过两倍上下文时合并为一个块
+		for k < len(changed) && changed[k]-end-1 <= 2*context {
+			end = changed[k]
+			k++
+		}
+		writeHunk(&b, rows, start, min(end+context+1, len(rows)))
+	}
+	return b.String()
+}
+
+// writeHunk 输出 rows[start:stop] 构成的一个块, 修改行拆分为先删除后插入
+func writeHunk(b *strings.Builder, rows []Row, start, stop int) {
+	// 块为空的一侧, 起始行号为块前的最后一行
+	leftBefore, rightBefore := 0, 0
+	for i := start - 1; i >= 0 && (leftBefore == 0 || rightBefore == 0); i-- {
+		if leftBefore == 0 && rows[i].LeftLine > 0 {
+			leftBefore = rows[i].LeftLine
+		}
+		if rightBefore == 0 && rows[i].RightLine > 0 {
+			rightBefore = rows[i].RightLine
+		}
+	}
+	leftCount, rightCount := 0, 0
+	for _, row := range rows[start:stop] {
+		if row.LeftLine > 0 {
+			leftCount++
+		}
+		if row.RightLine > 0 {
+			rightCount++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(leftBefore, leftCount), hunkRange(rightBefore, rightCount))
+
+	var deleted, inserted []string
+	flush := func() {
+		for _, line := range deleted {
+			b.WriteString("-" + line + "\n")
+		}
+		for _, line := range inserted {
+			b.WriteString("+" + line + "\n")
+		}
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+	for _, row := range rows[start:stop] {
+		if row.Kind == KindEqual {
+			flush()
+			b.WriteString(" " + row.Left + "\n")
+			continue
+		}
+		if row.LeftLine > 0 {
+			deleted = append(deleted, row.Left)
+		}
+		if row.RightLine > 0 {
+			inserted = append(inserted, row.Right)
+		}
+	}
+	flush()
+}
+
+// hunkRange 块头中一侧的行范围, 与 GNU diff 相同: 一行时省略行数, 没有行时起始行号为前一行
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
@@ -0,0 +1,148 @@
+package textdiff
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"空文本", "", nil},
+		{"只有换行", "\n", nil},
+		{"忽略末尾换行", "a\nb\n", []string{"a", "b"}},
+		{"兼容 CRLF", "a\r\nb", []string{"a", "b"}},
+		{"保留中间空行", "a\n\nb", []string{"a", "", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitLines(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("SplitLines(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareWithOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		left      []string
+		right     []string
+		opts      Options
+		wantKinds []Kind
+	}{
+		{"相同", []string{"a", "b"}, []string{"a", "b"}, Options{}, []Kind{KindEqual, KindEqual}},
+		{"删除与插入配对为修改", []string{"a", "b", "c"}, []string{"a", "x", "c"}, Options{}, []Kind{KindEqual, KindChange, KindEqual}},
+		{"只删除", []string{"a", "b"}, []string{"a"}, Options{}, []Kind{KindEqual, KindDelete}},
+		{"只插入", nil, []string{"a"}, Options{}, []Kind{KindInsert}},
+		{"多出的删除行不配对", []string{"a", "b", "c"}, []string{"x"}, Options{}, []Kind{KindChange, KindDelete, KindDelete}},
+		{"忽略行尾空白", []string{"a  "}, []string{"a"}, Options{IgnoreTrailingSpace: true}, []Kind{KindEqual}},
+		{"连续空白视为一个空格", []string{"a  =  1"}, []string{" a = 1"}, Options{IgnoreSpaceChange: true}, []Kind{KindEqual}},
+		{"忽略全部空白", []string{"a=1"}, []string{"a = 1"}, Options{IgnoreAllSpace: true}, []Kind{KindEqual}},
+		{"保留缩进时缩进变化仍为修改", []string{"    return x"}, []string{"return  x"}, Options{IgnoreAllSpace: true, KeepIndent: true}, []Kind{KindChange}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := CompareWithOptions(tt.left, tt.right, tt.opts)
+			if err != nil {
+				t.Fatalf("CompareWithOptions() error = %v", err)
+			}
+			kinds := make([]Kind, 0, len(rows))
+			for _, row := range rows {
+				kinds = append(kinds, row.Kind)
+			}
+			if !slices.Equal(kinds, tt.wantKinds) {
+				t.Errorf("CompareWithOptions() kinds = %v, want %v", kinds, tt.wantKinds)
+			}
+		})
+	}
+}
+
+func TestCompareTooLarge(t *testing.T) {
+	lines := func(n int, line string) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = line
+		}
+		return out
+	}
+	tests := []struct {
+		name        string
+		left, right []string
+		wantErr     error
+	}{
+		{"行数达到上限", lines(MaxLines, "a"), lines(MaxLines, "b"), nil},
+		{"左侧行数超过上限", lines(MaxLines+1, "a"), nil, ErrTooLarge},
+		{"右侧行数超过上限", nil, lines(MaxLines+1, "a"), ErrTooLarge},
+		{"字节数超过上限", []string{strings.Repeat("a", MaxBytes)}, nil, ErrTooLarge},
+		{"字节数含换行恰好达到上限", []string{strings.Repeat("a", MaxBytes-1)}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compare(tt.left, tt.right); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Compare() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		left    string
+		right   string
+		context int
+		want    string
+	}{
+		{
+			name:  "没有差异",
+			left:  "a\nb\n",
+			right: "a\nb\n",
+			want:  "",
+		},
+		{
+			name:    "修改一行",
+			left:    "a\nb\nc\n",
+			right:   "a\nx\nc\n",
+			context: 1,
+			want:    "--- l\n+++ r\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name:    "空文件插入",
+			left:    "",
+			right:   "a\n",
+			context: 3,
+			want:    "--- l\n+++ r\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:    "相距较远的修改分为两个块",
+			left:    "1\n2\n3\n4\n5\n6\n7\n",
+			right:   "x\n2\n3\n4\n5\n6\ny\n",
+			context: 1,
+			want:    "--- l\n+++ r\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -6,2 +6,2 @@\n 6\n-7\n+y\n",
+		},
+		{
+			name:    "相近的修改合并为一个块",
+			left:    "1\n2\n3\n",
+			right:   "x\n2\ny\n",
+			context: 1,
+			want:    "--- l\n+++ r\n@@ -1,3 +1,3 @@\n-1\n+x\n 2\n-3\n+y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Compare(SplitLines(tt.left), SplitLines(tt.right))
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if got := Unified(rows, "l", "r", tt.context); got != tt.want {
+				t.Errorf("Unified() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -31,24 +31,40 @@ type CompetitionService interface {
 	EnableCompetitionProblem(ctx context.Context, param *model.CompetitionProblemParam) error
 	// DisableCompetitionProblem 禁用比赛题目
 	DisableCompetitionProblem(ctx context.Context, param *model.CompetitionProblemParam) error
+	// ReorderCompetitionProblem 调整比赛题目顺序并重新生成标号
+	ReorderCompetitionProblem(ctx context.Context, param *model.ReorderCompetitionProblemParam) error
 	// GetCompetitionProblemList 获取比赛题目列表
-	GetCompetitionProblemList(ctx context.Context, competitionID uint64) ([]ojmodel.CompetitionProblem, error)
+	GetCompetitionProblemList(ctx context.Context, competitionID uint64) ([]model.CompetitionProblemItem, error)
 	// CheckUserInCompetition 检查用户是否在比赛名单中
 	CheckUserInCompetition(ctx context.Context, competitionID, userID uint64) (bool, error)
 	// CheckCompetitionTime 检查比赛时间是否在范围内
 	CheckCompetitionTime(ctx context.Context, competitionID uint64) (bool, error)
+	// CheckCompetitionUpsolve 检查比赛是否已结束且允许赛后补题
+	CheckCompetitionUpsolve(ctx context.Context, competitionID uint64) (bool, error)
 	// GetCompetition 获取比赛信息
 	GetCompetition(ctx context.Context, competitionID uint64) (*ojmodel.Competition, error)
 	// GetCompetitionList 获取比赛列表
 	GetCompetitionList(ctx context.Context, desc bool, orderBy, name string, status *ojmodel.CompetitionStatus, phase *model.CompetitionPhase, page, pageSize int) ([]ojmodel.Competition, int, error)
 	// UserGetCompetitionProblemList 用户获取比赛题目列表
-	UserGetCompetitionProblemList(ctx context.Context, competitionID uint64) ([]ojmodel.CompetitionProblem, error)
+	UserGetCompetitionProblemList(ctx context.Context, competitionID uint64) ([]model.CompetitionProblemItem, error)
 	// UserGetCompetitionProblemDetail 用户获取比赛题目详情
 	UserGetCompetitionProblemDetail(ctx context.Context, competitionID, problemID uint64) (*ojmodel.Problem, error)
 	// CheckUserCompetitionProblemAccepted 检查用户比赛题目是否已通过
 	CheckUserCompetitionProblemAccepted(ctx context.Context, competitionID, problemID, userID uint64) (bool, error)
 	// SubscribeCompetitionEndEvent 订阅比赛结束事件
 	SubscribeCompetitionEndEvent(ctx context.Context, competitionID uint64) chan string
+	// CloneCompetition 复制比赛为新的未发布比赛, 返回新比赛 ID
+	CloneCompetition(ctx context.Context, param *model.CloneCompetitionParam) (uint64, error)
+	// GetCompetitionSetting 获取比赛设置
+	GetCompetitionSetting(ctx context.Context, competitionID uint64) (*model.CompetitionSetting, error)
+	// UpdateCompetitionSetting 更新比赛设置
+	UpdateCompetitionSetting(ctx context.Context, param *model.UpdateCompetitionSettingParam) error
+	// CreateCompetitionAnnouncement 发布比赛公告并通知所有实时排行榜连接
+	CreateCompetitionAnnouncement(ctx context.Context, param *model.CreateCompetitionAnnouncementParam) (*model.CompetitionAnnouncement, error)
+	// GetCompetitionAnnouncementList 按发布时间顺序获取比赛公告
+	GetCompetitionAnnouncementList(ctx context.Context, competitionID uint64) ([]model.CompetitionAnnouncement, error)
+	// SubscribeCompetitionAnnouncement 订阅比赛公告, 只推送订阅之后发布的公告
+	SubscribeCompetitionAnnouncement(ctx context.Context, competitionID uint64) chan *model.CompetitionAnnouncement
 }
 
 const (
@@ -91,6 +107,16 @@ func (s *CompetitionServiceImpl) CreateCompetition(ctx context.Context, param *m
 		return fmt.Errorf("CreateCompetition transaction failed at insert into competition: %w", err)
 	}
 
+	err = tx.Create(&model.CompetitionLifecycle{
+		CompetitionID: competition.ID,
+		State:         model.CompetitionStateDraft,
+		UpdaterID:     param.Operator,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("CreateCompetition transaction failed at insert into competition_lifecycle: %w", err)
+	}
+
 	if len(param.Problems) != 0 {
 		competitionProblems := make([]ojmodel.CompetitionProblem, 0, len(param.Problems))
 		for _, problem := range param.Problems {
@@ -120,6 +146,11 @@ func (s *CompetitionServiceImpl) CreateCompetition(ctx context.Context, param *m
 			tx.Rollback()
 			return fmt.Errorf("CreateCompetition transaction failed at insert into competition_problem: %w", err)
 		}
+		err = syncCompetitionProblemLabels(tx, competition.ID, nil)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("CreateCompetition transaction failed at generate problem label: %w", err)
+		}
 	}
 
 	// 提交事务
@@ -142,6 +173,20 @@ func (s *CompetitionServiceImpl) CreateCompetition(ctx context.Context, param *m
 	return nil
 }
 
+// CloneCompetition 复制比赛的题目 ( 保持顺序与启用状态 )、选手名单与比赛设置, 按新开始时间平移比赛时间
+func (s *CompetitionServiceImpl) CloneCompetition(ctx context.Context, param *model.CloneCompetitionParam) (uint64, error) {
+	blueprint, err := loadCompetitionBlueprint(ctx, s.db, s.rdb, param.CompetitionID)
+	if err != nil {
+		return 0, fmt.Errorf("CloneCompetition failed at load competition %d: %w", param.CompetitionID, err)
+	}
+	competitionID, err := createCompetitionFromBlueprint(ctx, s.db, blueprint, param.Name, param.StartTime, param.Operator)
+	if err != nil {
+		return 0, fmt.Errorf("CloneCompetition transaction failed: %w", err)
+	}
+	deleteCompetitionUserNicknameCache(ctx, s.rdb, s.log, competitionID)
+	return competitionID, nil
+}
+
 // UpdateCompetition 更新比赛
 func (s *CompetitionServiceImpl) UpdateCompetition(ctx context.Context, param *model.UpdateCompetitionParam) error {
 	updates := map[string]any{
@@ -208,22 +253,37 @@ func (s *CompetitionServiceImpl) AddCompetitionProblem(ctx context.Context, para
 			Status:        pointer.ToPtr(ojmodel.CompetitionProblemStatusEnabled),
 		})
 	}
-	err := s.db.WithContext(ctx).Create(&competitionProblems).Error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&competitionProblems).Error
+		if err != nil {
+			return fmt.Errorf("insert into competition_problem failed: %w", err)
+		}
+		// 新增题目追加到末尾
+		return syncCompetitionProblemLabels(tx, param.CompetitionID, nil)
+	})
 	if err != nil {
-		return fmt.Errorf("AddCompetitionProblem failed at insert into competition_problem: %w", err)
+		return fmt.Errorf("AddCompetitionProblem transaction failed: %w", err)
 	}
+	s.deleteCompetitionProblemCache(ctx, param.CompetitionID)
 	return nil
 }
 
 // RemoveCompetitionProblem 删除比赛题目
 func (s *CompetitionServiceImpl) RemoveCompetitionProblem(ctx context.Context, param *model.CompetitionProblemParam) error {
-	err := s.db.WithContext(ctx).
-		Where("competition_id = ?", param.CompetitionID).
-		Where("problem_id IN ?", param.ProblemIDs).
-		Delete(&ojmodel.CompetitionProblem{}).Error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("competition_id = ?", param.CompetitionID).
+			Where("problem_id IN ?", param.ProblemIDs).
+			Delete(&ojmodel.CompetitionProblem{}).Error
+		if err != nil {
+			return fmt.Errorf("delete from competition_problem failed: %w", err)
+		}
+		// 删除题目后剩余题目标号顺延
+		return syncCompetitionProblemLabels(tx, param.CompetitionID, nil)
+	})
 	if err != nil {
-		return fmt.Errorf("RemoveCompetitionProblem failed at delete from competition_problem: %w", err)
+		return fmt.Errorf("RemoveCompetitionProblem transaction failed: %w", err)
 	}
+	s.deleteCompetitionProblemCache(ctx, param.CompetitionID)
 	return nil
 }
 
@@ -238,6 +298,7 @@ func (s *CompetitionServiceImpl) EnableCompetitionProblem(ctx context.Context, p
 	if err != nil {
 		return fmt.Errorf("EnableCompetitionProblem failed at update competition_problem: %w", err)
 	}
+	s.deleteCompetitionProblemCache(ctx, param.CompetitionID)
 	return nil
 }
 
@@ -252,15 +313,13 @@ func (s *CompetitionServiceImpl) DisableCompetitionProblem(ctx context.Context,
 	if err != nil {
 		return fmt.Errorf("DisableCompetitionProblem failed at update competition_problem: %w", err)
 	}
+	s.deleteCompetitionProblemCache(ctx, param.CompetitionID)
 	return nil
 }
 
 // GetCompetitionProblemList 获取比赛题目列表
-func (s *CompetitionServiceImpl) GetCompetitionProblemList(ctx context.Context, competitionID uint64) ([]ojmodel.CompetitionProblem, error) {
-	var competitionProblems []ojmodel.CompetitionProblem
-	err := s.db.WithContext(ctx).
-		Where("competition_id = ?", competitionID).
-		Find(&competitionProblems).Error
+func (s *CompetitionServiceImpl) GetCompetitionProblemList(ctx context.Context, competitionID uint64) ([]model.CompetitionProblemItem, error) {
+	competitionProblems, err := loadCompetitionProblemItems(ctx, s.db, competitionID)
 	if err != nil {
 		return nil, fmt.Errorf("GetCompetitionProblemList failed at select from competition_problem: %w", err)
 	}
@@ -417,6 +476,51 @@ func (s *CompetitionServiceImpl) CheckCompetitionTime(ctx context.Context, compe
 	return !time.Now().Before(competition.StartTime) && time.Now().Before(competition.EndTime), nil
 }
 
+// CheckCompetitionUpsolve 检查比赛是否已结束且允许赛后补题
+func (s *CompetitionServiceImpl) CheckCompetitionUpsolve(ctx context.Context, competitionID uint64) (bool, error) {
+	competition, err := s.GetCompetition(ctx, competitionID)
+	if err != nil {
+		return false, fmt.Errorf("CheckCompetitionUpsolve failed: %w", err)
+	}
+	if competition.ID == 0 || competition.Status == nil || *competition.Status != ojmodel.CompetitionStatusPublished {
+		return false, nil
+	}
+	if time.Now().Before(competition.EndTime) {
+		return false, nil
+	}
+
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return false, fmt.Errorf("CheckCompetitionUpsolve failed: %w", err)
+	}
+	return setting.AllowUpsolve, nil
+}
+
+// loadCompetitionMeta 获取比赛元数据, 优先读取与 GetCompetition 共用的 Redis 缓存, 比赛不存在时返回 gorm.ErrRecordNotFound
+func loadCompetitionMeta(ctx context.Context, db *gorm.DB, rdb redis.Cmdable, competitionID uint64) (*ojmodel.Competition, error) {
+	metaKey := fmt.Sprintf(competitionMetaKey, competitionID)
+
+	var competition ojmodel.Competition
+	metaBytes, err := rdb.Get(ctx, metaKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(metaBytes, &competition); err == nil && competition.ID != 0 {
+			return &competition, nil
+		}
+	}
+
+	err = db.WithContext(ctx).
+		Where("id = ?", competitionID).
+		First(&competition).Error
+	if err != nil {
+		return nil, fmt.Errorf("loadCompetitionMeta failed at select from competition: %w", err)
+	}
+
+	if metaBytes, err = json.Marshal(competition); err == nil {
+		rdb.Set(ctx, metaKey, metaBytes, 8*time.Hour)
+	}
+	return &competition, nil
+}
+
 // GetCompetition 获取比赛元数据
 func (s *CompetitionServiceImpl) GetCompetition(ctx context.Context, competitionID uint64) (*ojmodel.Competition, error) {
 	var competition ojmodel.Competition
@@ -523,8 +627,8 @@ func (s *CompetitionServiceImpl) GetCompetitionList(ctx context.Context, desc bo
 	return competitions, int(total), nil
 }
 
-func (s *CompetitionServiceImpl) UserGetCompetitionProblemList(ctx context.Context, competitionID uint64) ([]ojmodel.CompetitionProblem, error) {
-	var competitionProblems []ojmodel.CompetitionProblem
+func (s *CompetitionServiceImpl) UserGetCompetitionProblemList(ctx context.Context, competitionID uint64) ([]model.CompetitionProblemItem, error) {
+	var competitionProblems []model.CompetitionProblemItem
 	problemListKey := fmt.Sprintf(competitionProblemListKey, competitionID)
 
 	// 从 Redis 获取比赛题目列表
@@ -568,15 +672,17 @@ func (s *CompetitionServiceImpl) UserGetCompetitionProblemList(ctx context.Conte
 	}
 	s.log.WarnContext(ctx, "UserGetCompetitionProblemList: failed to get competition problem list from redis", logger.Error(err))
 
-	// 从数据库加载比赛题目列表
-	err = s.db.WithContext(ctx).
-		Where("competition_id = ?", competitionID).
-		Where("status = ?", ojmodel.CompetitionProblemStatusEnabled).
-		Select("id", "competition_id", "problem_id", "problem_title").
-		Find(&competitionProblems).Error
+	// 从数据库加载比赛题目列表, 仅返回启用的题目
+	items, err := loadCompetitionProblemItems(ctx, s.db, competitionID)
 	if err != nil {
 		return nil, fmt.Errorf("UserGetCompetitionProblemList: failed to select competition problem: %w", err)
 	}
+	competitionProblems = make([]model.CompetitionProblemItem, 0, len(items))
+	for _, item := range items {
+		if item.Status.Int8() == int8(ojmodel.CompetitionProblemStatusEnabled) {
+			competitionProblems = append(competitionProblems, item)
+		}
+	}
 
 	// 将比赛题目列表写入 Redis 缓存
 	problemBytes, err = json.Marshal(competitionProblems)
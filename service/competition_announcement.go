@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/online_judge_controller/constants"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+// CreateCompetitionAnnouncement 发布比赛公告并通知所有实时排行榜连接
+func (s *CompetitionServiceImpl) CreateCompetitionAnnouncement(ctx context.Context, param *model.CreateCompetitionAnnouncementParam) (*model.CompetitionAnnouncement, error) {
+	_, err := s.GetCompetition(ctx, param.CompetitionID)
+	if err != nil {
+		return nil, fmt.Errorf("CreateCompetitionAnnouncement failed: %w", err)
+	}
+
+	announcement := &model.CompetitionAnnouncement{
+		CompetitionID: param.CompetitionID,
+		Title:         param.Title,
+		Content:       param.Content,
+		CreatorID:     param.Operator,
+	}
+	err = s.db.WithContext(ctx).Create(announcement).Error
+	if err != nil {
+		return nil, fmt.Errorf("CreateCompetitionAnnouncement failed at insert into competition_announcement: %w", err)
+	}
+
+	// 公告已落库, 推送失败时客户端重连后仍可在初始消息中获取
+	announcementBytes, err := json.Marshal(announcement)
+	if err != nil {
+		s.log.ErrorContext(ctx, "CreateCompetitionAnnouncement: marshal announcement failed", logger.Error(err))
+		return announcement, nil
+	}
+	err = s.rdb.Publish(ctx, fmt.Sprintf(constants.RedisPubSubCompetitionAnnouncementEventKey, param.CompetitionID), announcementBytes).Err()
+	if err != nil {
+		s.log.ErrorContext(ctx, "CreateCompetitionAnnouncement: publish announcement failed", logger.Error(err))
+	}
+	return announcement, nil
+}
+
+// GetCompetitionAnnouncementList 按发布时间顺序获取比赛公告
+func (s *CompetitionServiceImpl) GetCompetitionAnnouncementList(ctx context.Context, competitionID uint64) ([]model.CompetitionAnnouncement, error) {
+	var announcements []model.CompetitionAnnouncement
+	err := s.db.WithContext(ctx).
+		Where("competition_id = ?", competitionID).
+		Order("id ASC").
+		Find(&announcements).Error
+	if err != nil {
+		return nil, fmt.Errorf("GetCompetitionAnnouncementList failed: %w", err)
+	}
+	return announcements, nil
+}
+
+// SubscribeCompetitionAnnouncement 订阅比赛公告, 只推送订阅之后发布的公告
+func (s *CompetitionServiceImpl) SubscribeCompetitionAnnouncement(ctx context.Context, competitionID uint64) chan *model.CompetitionAnnouncement {
+	ch := make(chan *model.CompetitionAnnouncement, 1)
+	uc, ok := s.rdb.(redis.UniversalClient)
+	if !ok {
+		s.log.ErrorContext(ctx, "SubscribeCompetitionAnnouncement: redis cmdable not universal client")
+		close(ch)
+		return ch
+	}
+	go func() {
+		pubsub := uc.Subscribe(ctx, fmt.Sprintf(constants.RedisPubSubCompetitionAnnouncementEventKey, competitionID))
+		defer pubsub.Close()
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				s.log.InfoContext(ctx, "SubscribeCompetitionAnnouncement: client closed")
+				return
+			case msg, ok := <-pubsub.Channel():
+				if !ok {
+					return
+				}
+				var announcement model.CompetitionAnnouncement
+				if err := json.Unmarshal([]byte(msg.Payload), &announcement); err != nil {
+					s.log.ErrorContext(ctx, "SubscribeCompetitionAnnouncement: unmarshal announcement failed", logger.Error(err))
+					continue
+				}
+				select {
+				case ch <- &announcement:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
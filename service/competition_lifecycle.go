@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/pointer"
+	"github.com/to404hanga/pkg404/gotools/retry"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCompetitionTransition = errors.New("invalid competition state transition")
+	ErrCompetitionFieldNotEditable  = errors.New("competition field is not editable in current state")
+	ErrCompetitionStateConflict     = errors.New("competition state has been changed by others")
+)
+
+type CompetitionLifecycleService interface {
+	// GetCompetitionState 获取比赛当前状态, 到达开始/结束时间时自动流转
+	GetCompetitionState(ctx context.Context, competitionID uint64) (model.CompetitionState, error)
+	// CheckCompetitionTransition 检查比赛能否从当前状态流转到目标状态, 不做修改
+	CheckCompetitionTransition(ctx context.Context, competitionID uint64, to model.CompetitionState) error
+	// TransitCompetition 比赛状态流转
+	TransitCompetition(ctx context.Context, competitionID uint64, to model.CompetitionState, operator uint64, reason string) error
+	// CheckCompetitionEditable 检查比赛当前状态下给定字段是否允许修改
+	CheckCompetitionEditable(ctx context.Context, competitionID uint64, fields ...string) error
+	// GetCompetitionTransitionList 获取比赛状态流转记录
+	GetCompetitionTransitionList(ctx context.Context, competitionID uint64) ([]model.CompetitionTransition, error)
+}
+
+// competitionTransitions 合法的状态流转, 草稿直接归档即删除比赛
+var competitionTransitions = map[model.CompetitionState][]model.CompetitionState{
+	model.CompetitionStateDraft:     {model.CompetitionStateScheduled, model.CompetitionStateArchived},
+	model.CompetitionStateScheduled: {model.CompetitionStateDraft, model.CompetitionStateRunning},
+	model.CompetitionStateRunning:   {model.CompetitionStateFrozen, model.CompetitionStateEnded},
+	model.CompetitionStateFrozen:    {model.CompetitionStateEnded},
+	model.CompetitionStateEnded:     {model.CompetitionStateFinalized},
+	model.CompetitionStateFinalized: {model.CompetitionStateArchived},
+}
+
+// competitionEditableFields 各状态下允许修改的字段
+var competitionEditableFields = map[model.CompetitionState][]string{
+	model.CompetitionStateDraft:     {model.CompetitionFieldName, model.CompetitionFieldStartTime, model.CompetitionFieldEndTime, model.CompetitionFieldProblems, model.CompetitionFieldSetting},
+	model.CompetitionStateScheduled: {model.CompetitionFieldName, model.CompetitionFieldStartTime, model.CompetitionFieldEndTime, model.CompetitionFieldProblems, model.CompetitionFieldSetting},
+	model.CompetitionStateRunning:   {model.CompetitionFieldName, model.CompetitionFieldEndTime},
+	model.CompetitionStateFrozen:    {model.CompetitionFieldName, model.CompetitionFieldEndTime},
+	model.CompetitionStateEnded:     {model.CompetitionFieldName},
+}
+
+type CompetitionLifecycleServiceImpl struct {
+	db             *gorm.DB
+	rdb            redis.Cmdable
+	competitionSvc CompetitionService
+	rankingSvc     RankingService
+	log            loggerv2.Logger
+}
+
+var _ CompetitionLifecycleService = (*CompetitionLifecycleServiceImpl)(nil)
+
+func NewCompetitionLifecycleService(db *gorm.DB, rdb redis.Cmdable, competitionSvc CompetitionService, rankingSvc RankingService, log loggerv2.Logger) CompetitionLifecycleService {
+	return &CompetitionLifecycleServiceImpl{
+		db:             db,
+		rdb:            rdb,
+		competitionSvc: competitionSvc,
+		rankingSvc:     rankingSvc,
+		log:            log,
+	}
+}
+
+// GetCompetitionState 获取比赛当前状态, 到达开始/结束时间时自动流转
+func (s *CompetitionLifecycleServiceImpl) GetCompetitionState(ctx context.Context, competitionID uint64) (model.CompetitionState, error) {
+	lifecycle, competition, err := s.loadLifecycle(ctx, competitionID)
+	if err != nil {
+		return model.CompetitionStateDraft, err
+	}
+
+	// 按比赛时间自动推进, 操作者记为系统
+	now := time.Now()
+	state := lifecycle.State
+	var autoTo []model.CompetitionState
+	if state == model.CompetitionStateScheduled && !now.Before(competition.StartTime) {
+		autoTo = append(autoTo, model.CompetitionStateRunning)
+		state = model.CompetitionStateRunning
+	}
+	if (state == model.CompetitionStateRunning || state == model.CompetitionStateFrozen) && !now.Before(competition.EndTime) {
+		autoTo = append(autoTo, model.CompetitionStateEnded)
+	}
+	for _, to := range autoTo {
+		err = s.TransitCompetition(ctx, competitionID, to, 0, "到达比赛时间自动流转")
+		if err != nil && !errors.Is(err, ErrCompetitionStateConflict) && !errors.Is(err, ErrInvalidCompetitionTransition) {
+			return lifecycle.State, fmt.Errorf("GetCompetitionState failed at auto transit: %w", err)
+		}
+	}
+	if len(autoTo) == 0 {
+		return lifecycle.State, nil
+	}
+
+	lifecycle, _, err = s.loadLifecycle(ctx, competitionID)
+	if err != nil {
+		return model.CompetitionStateDraft, err
+	}
+	return lifecycle.State, nil
+}
+
+// CheckCompetitionTransition 检查比赛能否从当前状态流转到目标状态, 不做修改
+func (s *CompetitionLifecycleServiceImpl) CheckCompetitionTransition(ctx context.Context, competitionID uint64, to model.CompetitionState) error {
+	state, err := s.GetCompetitionState(ctx, competitionID)
+	if err != nil {
+		return err
+	}
+	_, competition, err := s.loadLifecycle(ctx, competitionID)
+	if err != nil {
+		return err
+	}
+	if err = checkCompetitionTransition(state, to, competition); err != nil {
+		return fmt.Errorf("CheckCompetitionTransition failed: %w", err)
+	}
+	return nil
+}
+
+// checkCompetitionTransition 检查状态流转是否合法
+func checkCompetitionTransition(from, to model.CompetitionState, competition *ojmodel.Competition) error {
+	if !canTransitCompetition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidCompetitionTransition, from.String(), to.String())
+	}
+	if to == model.CompetitionStateRunning && time.Now().Before(competition.StartTime) {
+		return fmt.Errorf("%w: competition not started yet", ErrInvalidCompetitionTransition)
+	}
+	return nil
+}
+
+// TransitCompetition 比赛状态流转
+func (s *CompetitionLifecycleServiceImpl) TransitCompetition(ctx context.Context, competitionID uint64, to model.CompetitionState, operator uint64, reason string) error {
+	lifecycle, competition, err := s.loadLifecycle(ctx, competitionID)
+	if err != nil {
+		return err
+	}
+	from := lifecycle.State
+	if err = checkCompetitionTransition(from, to, competition); err != nil {
+		return fmt.Errorf("TransitCompetition failed: %w", err)
+	}
+
+	now := time.Now()
+	// 提前结束比赛时将结束时间改为当前时间, 比赛时间检查、赛后补题与排行榜均以结束时间为准
+	endEarly := to == model.CompetitionStateEnded && now.Before(competition.EndTime)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]any{
+			"state":      to,
+			"updater_id": operator,
+		}
+		if to == model.CompetitionStateFrozen {
+			updates["freeze_time"] = now
+		}
+		// 以流转前状态作为条件, 防止并发流转
+		res := tx.Model(&model.CompetitionLifecycle{}).
+			Where("competition_id = ?", competitionID).
+			Where("state = ?", from).
+			Updates(updates)
+		if res.Error != nil {
+			return fmt.Errorf("update competition_lifecycle failed: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrCompetitionStateConflict
+		}
+
+		// 草稿对应未发布, 草稿直接归档对应删除, 其余状态均为已发布
+		status := ojmodel.CompetitionStatusPublished
+		switch {
+		case to == model.CompetitionStateDraft:
+			status = ojmodel.CompetitionStatusUnpublished
+		case from == model.CompetitionStateDraft && to == model.CompetitionStateArchived:
+			status = ojmodel.CompetitionStatusDeleted
+		}
+		if endEarly {
+			err := tx.Model(&ojmodel.Competition{}).
+				Where("id = ?", competitionID).
+				Updates(map[string]any{
+					"end_time":   now,
+					"updater_id": operator,
+				}).Error
+			if err != nil {
+				return fmt.Errorf("update competition end_time failed: %w", err)
+			}
+		}
+		if from == model.CompetitionStateDraft || to == model.CompetitionStateDraft {
+			err := tx.Model(&ojmodel.Competition{}).
+				Where("id = ?", competitionID).
+				Updates(map[string]any{
+					"status":     status,
+					"updater_id": operator,
+				}).Error
+			if err != nil {
+				return fmt.Errorf("update competition status failed: %w", err)
+			}
+		}
+
+		err := tx.Create(&model.CompetitionTransition{
+			CompetitionID: competitionID,
+			FromState:     from,
+			ToState:       to,
+			OperatorID:    operator,
+			Reason:        reason,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("insert into competition_transition failed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("TransitCompetition transaction failed: %w", err)
+	}
+	if endEarly {
+		// 同步删除比赛元数据缓存, 删除失败时提交仍会按比赛状态拒绝
+		if err = s.rdb.Del(ctx, fmt.Sprintf(competitionMetaKey, competitionID)).Err(); err != nil {
+			s.log.WarnContext(ctx, "TransitCompetition: failed to delete competition meta cache", logger.Error(err))
+		}
+	}
+
+	s.log.InfoContext(ctx, "TransitCompetition success",
+		logger.Uint64("competition_id", competitionID),
+		logger.String("from", from.String()),
+		logger.String("to", to.String()),
+		logger.Uint64("operator", operator))
+
+	s.afterTransit(ctx, competitionID, to)
+	return nil
+}
+
+// afterTransit 状态流转后的副作用, 异步执行并重试
+func (s *CompetitionLifecycleServiceImpl) afterTransit(ctx context.Context, competitionID uint64, to model.CompetitionState) {
+	retryCtx := context.WithValue(context.Background(), loggerv2.FieldsKey, ctx.Value(loggerv2.FieldsKey))
+
+	var fn func() error
+	switch to {
+	case model.CompetitionStateDraft, model.CompetitionStateScheduled, model.CompetitionStateArchived:
+		// 预热比赛缓存: 比赛元数据、题目列表、选手名单
+		fn = func() error {
+			if err := s.rdb.Del(retryCtx, fmt.Sprintf(competitionMetaKey, competitionID), fmt.Sprintf(competitionProblemListKey, competitionID), fmt.Sprintf(competitionProblemItemsKey, competitionID)).Err(); err != nil {
+				return fmt.Errorf("delete competition cache failed: %w", err)
+			}
+			if to != model.CompetitionStateScheduled {
+				return nil
+			}
+			if _, err := s.competitionSvc.GetCompetition(retryCtx, competitionID); err != nil {
+				return fmt.Errorf("warm up competition meta failed: %w", err)
+			}
+			if _, err := s.competitionSvc.UserGetCompetitionProblemList(retryCtx, competitionID); err != nil {
+				return fmt.Errorf("warm up competition problem list failed: %w", err)
+			}
+			if _, err := s.competitionSvc.CheckUserInCompetition(retryCtx, competitionID, 0); err != nil {
+				return fmt.Errorf("warm up competition user set failed: %w", err)
+			}
+			return nil
+		}
+	case model.CompetitionStateRunning:
+		fn = func() error {
+			return s.rankingSvc.InitCompetitionRanking(retryCtx, competitionID)
+		}
+	case model.CompetitionStateFrozen:
+		fn = func() error {
+			return s.rankingSvc.FreezeCompetitionRanking(retryCtx, competitionID)
+		}
+	case model.CompetitionStateFinalized:
+		fn = func() error {
+			return s.rankingSvc.FinalizeCompetitionRanking(retryCtx, competitionID)
+		}
+	default:
+		return
+	}
+
+	retry.Do(retryCtx, fn, retry.WithAsync(true), retry.WithCallback(func(err error) {
+		if err != nil {
+			s.log.ErrorContext(retryCtx, "TransitCompetition failed at side effect",
+				logger.Uint64("competition_id", competitionID),
+				logger.String("to", to.String()),
+				logger.Error(err))
+		}
+	}))
+}
+
+// CheckCompetitionEditable 检查比赛当前状态下给定字段是否允许修改
+func (s *CompetitionLifecycleServiceImpl) CheckCompetitionEditable(ctx context.Context, competitionID uint64, fields ...string) error {
+	state, err := s.GetCompetitionState(ctx, competitionID)
+	if err != nil {
+		return err
+	}
+	editable := competitionEditableFields[state]
+	for _, field := range fields {
+		found := false
+		for _, f := range editable {
+			if f == field {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("CheckCompetitionEditable failed: %w: field %s in state %s", ErrCompetitionFieldNotEditable, field, state.String())
+		}
+	}
+	return nil
+}
+
+// GetCompetitionTransitionList 获取比赛状态流转记录
+func (s *CompetitionLifecycleServiceImpl) GetCompetitionTransitionList(ctx context.Context, competitionID uint64) ([]model.CompetitionTransition, error) {
+	var transitions []model.CompetitionTransition
+	err := s.db.WithContext(ctx).
+		Where("competition_id = ?", competitionID).
+		Order("id ASC").
+		Find(&transitions).Error
+	if err != nil {
+		return nil, fmt.Errorf("GetCompetitionTransitionList failed: %w", err)
+	}
+	return transitions, nil
+}
+
+// loadLifecycle 加载比赛生命周期, 历史比赛没有记录时根据比赛状态与时间推导并补齐
+func (s *CompetitionLifecycleServiceImpl) loadLifecycle(ctx context.Context, competitionID uint64) (*model.CompetitionLifecycle, *ojmodel.Competition, error) {
+	var competition ojmodel.Competition
+	err := s.db.WithContext(ctx).
+		Where("id = ?", competitionID).
+		First(&competition).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("load competition %d failed: %w", competitionID, err)
+	}
+
+	var lifecycle model.CompetitionLifecycle
+	err = s.db.WithContext(ctx).
+		Where("competition_id = ?", competitionID).
+		First(&lifecycle).Error
+	if err == nil {
+		return &lifecycle, &competition, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, fmt.Errorf("load competition_lifecycle failed: %w", err)
+	}
+
+	now := time.Now()
+	lifecycle = model.CompetitionLifecycle{
+		CompetitionID: competitionID,
+		State:         model.CompetitionStateDraft,
+	}
+	switch {
+	case pointer.FromPtr(competition.Status) == ojmodel.CompetitionStatusDeleted:
+		lifecycle.State = model.CompetitionStateArchived
+	case pointer.FromPtr(competition.Status) == ojmodel.CompetitionStatusUnpublished:
+		lifecycle.State = model.CompetitionStateDraft
+	case now.Before(competition.StartTime):
+		lifecycle.State = model.CompetitionStateScheduled
+	case now.Before(competition.EndTime):
+		lifecycle.State = model.CompetitionStateRunning
+	default:
+		lifecycle.State = model.CompetitionStateEnded
+	}
+	// 并发补齐时以先写入者为准
+	err = s.db.WithContext(ctx).
+		Where("competition_id = ?", competitionID).
+		FirstOrCreate(&lifecycle).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("init competition_lifecycle failed: %w", err)
+	}
+	return &lifecycle, &competition, nil
+}
+
+func canTransitCompetition(from, to model.CompetitionState) bool {
+	for _, state := range competitionTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+)
+
+func TestCanTransitCompetition(t *testing.T) {
+	const (
+		draft     = model.CompetitionStateDraft
+		scheduled = model.CompetitionStateScheduled
+		running   = model.CompetitionStateRunning
+		frozen    = model.CompetitionStateFrozen
+		ended     = model.CompetitionStateEnded
+		finalized = model.CompetitionStateFinalized
+		archived  = model.CompetitionStateArchived
+	)
+	tests := []struct {
+		from, to model.CompetitionState
+		want     bool
+	}{
+		{draft, scheduled, true},
+		{draft, archived, true},
+		{draft, running, false},
+		{draft, draft, false},
+		{scheduled, draft, true},
+		{scheduled, running, true},
+		{scheduled, ended, false},
+		{running, frozen, true},
+		{running, ended, true},
+		{running, scheduled, false},
+		{frozen, ended, true},
+		{frozen, running, false},
+		{ended, finalized, true},
+		{ended, running, false},
+		{finalized, archived, true},
+		{finalized, ended, false},
+		{archived, draft, false},
+		{archived, finalized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from.String()+"->"+tt.to.String(), func(t *testing.T) {
+			if got := canTransitCompetition(tt.from, tt.to); got != tt.want {
+				t.Errorf("canTransitCompetition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCompetitionTransition(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		from, to  model.CompetitionState
+		startTime time.Time
+		wantErr   error
+	}{
+		{"已到开始时间可开始", model.CompetitionStateScheduled, model.CompetitionStateRunning, now.Add(-time.Minute), nil},
+		{"未到开始时间不能开始", model.CompetitionStateScheduled, model.CompetitionStateRunning, now.Add(time.Hour), ErrInvalidCompetitionTransition},
+		{"非法流转", model.CompetitionStateDraft, model.CompetitionStateRunning, now.Add(-time.Minute), ErrInvalidCompetitionTransition},
+		{"提前结束不检查时间", model.CompetitionStateRunning, model.CompetitionStateEnded, now.Add(-time.Minute), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCompetitionTransition(tt.from, tt.to, &ojmodel.Competition{StartTime: tt.startTime})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkCompetitionTransition() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
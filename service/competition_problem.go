@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/gotools/retry"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidProblemOrder = errors.New("problem order must contain every competition problem exactly once")
+
+const competitionProblemItemsKey = "competition:%d:problem:items"
+
+// ReorderCompetitionProblem 调整比赛题目顺序并重新生成标号
+func (s *CompetitionServiceImpl) ReorderCompetitionProblem(ctx context.Context, param *model.ReorderCompetitionProblemParam) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return syncCompetitionProblemLabels(tx, param.CompetitionID, param.ProblemIDs)
+	})
+	if err != nil {
+		return fmt.Errorf("ReorderCompetitionProblem transaction failed: %w", err)
+	}
+	s.deleteCompetitionProblemCache(ctx, param.CompetitionID)
+	return nil
+}
+
+// deleteCompetitionProblemCache 异步删除比赛题目列表相关缓存
+func (s *CompetitionServiceImpl) deleteCompetitionProblemCache(ctx context.Context, competitionID uint64) {
+	keys := []string{
+		fmt.Sprintf(competitionProblemListKey, competitionID),
+		fmt.Sprintf(competitionProblemItemsKey, competitionID),
+	}
+	retryCtx := context.WithValue(context.Background(), loggerv2.FieldsKey, ctx.Value(loggerv2.FieldsKey))
+	retry.Do(retryCtx, func() error {
+		return s.rdb.Del(retryCtx, keys...).Err()
+	}, retry.WithAsync(true), retry.WithCallback(func(err error) {
+		if err != nil {
+			s.log.ErrorContext(retryCtx, "delete competition problem cache failed", logger.Error(err))
+		}
+	}))
+}
+
+// syncCompetitionProblemLabels 重新生成比赛题目标号, 在修改比赛题目的事务中调用.
+// order 非空时按 order 排列, 且必须恰好包含比赛的全部题目; order 为空时保持现有顺序, 新增题目按添加顺序追加到末尾
+func syncCompetitionProblemLabels(tx *gorm.DB, competitionID uint64, order []uint64) error {
+	// 锁定比赛记录, 串行化同一比赛的标号同步, 避免并发的删除与插入丢失或重复标号
+	var competition ojmodel.Competition
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", competitionID).
+		Take(&competition).Error
+	if err != nil {
+		return fmt.Errorf("lock competition failed: %w", err)
+	}
+
+	var problemIDs []uint64
+	err = tx.Model(&ojmodel.CompetitionProblem{}).
+		Where("competition_id = ?", competitionID).
+		Order("id ASC").
+		Pluck("problem_id", &problemIDs).Error
+	if err != nil {
+		return fmt.Errorf("select from competition_problem failed: %w", err)
+	}
+	exists := make(map[uint64]bool, len(problemIDs))
+	for _, problemID := range problemIDs {
+		exists[problemID] = true
+	}
+
+	if len(order) != 0 {
+		if len(order) != len(problemIDs) {
+			return ErrInvalidProblemOrder
+		}
+		seen := make(map[uint64]bool, len(order))
+		for _, problemID := range order {
+			if !exists[problemID] || seen[problemID] {
+				return ErrInvalidProblemOrder
+			}
+			seen[problemID] = true
+		}
+	} else {
+		var labels []model.CompetitionProblemLabel
+		err = tx.Where("competition_id = ?", competitionID).
+			Order("position ASC").
+			Find(&labels).Error
+		if err != nil {
+			return fmt.Errorf("select from competition_problem_label failed: %w", err)
+		}
+		order = orderCompetitionProblems(problemIDs, labels)
+	}
+
+	err = tx.Where("competition_id = ?", competitionID).
+		Delete(&model.CompetitionProblemLabel{}).Error
+	if err != nil {
+		return fmt.Errorf("delete from competition_problem_label failed: %w", err)
+	}
+	if len(order) == 0 {
+		return nil
+	}
+	labels := make([]model.CompetitionProblemLabel, 0, len(order))
+	for position, problemID := range order {
+		labels = append(labels, model.CompetitionProblemLabel{
+			CompetitionID: competitionID,
+			ProblemID:     problemID,
+			Position:      position,
+			Label:         model.ProblemLabel(position),
+		})
+	}
+	err = tx.Create(&labels).Error
+	if err != nil {
+		return fmt.Errorf("insert into competition_problem_label failed: %w", err)
+	}
+	return nil
+}
+
+// orderCompetitionProblems 按已有标号的顺序排列比赛题目, 没有标号的题目按添加顺序追加到末尾.
+// problemIDs 按添加顺序排列, labels 按 position 升序排列
+func orderCompetitionProblems(problemIDs []uint64, labels []model.CompetitionProblemLabel) []uint64 {
+	exists := make(map[uint64]bool, len(problemIDs))
+	for _, problemID := range problemIDs {
+		exists[problemID] = true
+	}
+	seen := make(map[uint64]bool, len(problemIDs))
+	order := make([]uint64, 0, len(problemIDs))
+	for _, label := range labels {
+		if exists[label.ProblemID] && !seen[label.ProblemID] {
+			order = append(order, label.ProblemID)
+			seen[label.ProblemID] = true
+		}
+	}
+	for _, problemID := range problemIDs {
+		if !seen[problemID] {
+			order = append(order, problemID)
+		}
+	}
+	return order
+}
+
+// loadCompetitionProblemItems 从数据库加载比赛全部题目并按顺序附带标号.
+// 只读不写, 历史比赛缺少标号时在内存中按添加顺序补齐, 标号在修改比赛题目时同步写入
+func loadCompetitionProblemItems(ctx context.Context, db *gorm.DB, competitionID uint64) ([]model.CompetitionProblemItem, error) {
+	var problems []ojmodel.CompetitionProblem
+	err := db.WithContext(ctx).
+		Where("competition_id = ?", competitionID).
+		Order("id ASC").
+		Find(&problems).Error
+	if err != nil {
+		return nil, fmt.Errorf("select from competition_problem failed: %w", err)
+	}
+
+	var labels []model.CompetitionProblemLabel
+	err = db.WithContext(ctx).
+		Where("competition_id = ?", competitionID).
+		Order("position ASC").
+		Find(&labels).Error
+	if err != nil {
+		return nil, fmt.Errorf("select from competition_problem_label failed: %w", err)
+	}
+
+	problemMap := make(map[uint64]ojmodel.CompetitionProblem, len(problems))
+	problemIDs := make([]uint64, 0, len(problems))
+	for _, problem := range problems {
+		problemMap[problem.ProblemID] = problem
+		problemIDs = append(problemIDs, problem.ProblemID)
+	}
+	order := orderCompetitionProblems(problemIDs, labels)
+	items := make([]model.CompetitionProblemItem, 0, len(order))
+	for position, problemID := range order {
+		items = append(items, model.CompetitionProblemItem{
+			CompetitionProblem: problemMap[problemID],
+			Position:           position,
+			Label:              model.ProblemLabel(position),
+		})
+	}
+	return items, nil
+}
+
+// getCompetitionProblemItems 获取比赛全部题目及标号, 优先读取 Redis 缓存
+func getCompetitionProblemItems(ctx context.Context, db *gorm.DB, rdb redis.Cmdable, competitionID uint64) ([]model.CompetitionProblemItem, error) {
+	itemsKey := fmt.Sprintf(competitionProblemItemsKey, competitionID)
+
+	var items []model.CompetitionProblemItem
+	itemsBytes, err := rdb.Get(ctx, itemsKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(itemsBytes, &items); err == nil {
+			return items, nil
+		}
+	}
+
+	items, err = loadCompetitionProblemItems(ctx, db, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	if itemsBytes, err = json.Marshal(items); err == nil {
+		rdb.Set(ctx, itemsKey, itemsBytes, 8*time.Hour)
+	}
+	return items, nil
+}
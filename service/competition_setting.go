@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/gotools/retry"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"gorm.io/gorm"
+)
+
+const competitionSettingKey = "competition:%d:setting"
+
+// loadCompetitionSetting 获取比赛设置, 优先读取 Redis 缓存, 无记录时返回默认设置
+func loadCompetitionSetting(ctx context.Context, db *gorm.DB, rdb redis.Cmdable, competitionID uint64) (*model.CompetitionSetting, error) {
+	settingKey := fmt.Sprintf(competitionSettingKey, competitionID)
+
+	var setting model.CompetitionSetting
+	settingBytes, err := rdb.Get(ctx, settingKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(settingBytes, &setting); err == nil {
+			return &setting, nil
+		}
+	}
+
+	err = db.WithContext(ctx).
+		Where("competition_id = ?", competitionID).
+		First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) && competitionID == model.PracticeCompetitionID {
+		setting = *model.DefaultPracticeSetting()
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		setting = *model.DefaultCompetitionSetting(competitionID)
+	} else if err != nil {
+		return nil, fmt.Errorf("loadCompetitionSetting failed at select from competition_setting: %w", err)
+	}
+
+	if settingBytes, err = json.Marshal(setting); err == nil {
+		rdb.Set(ctx, settingKey, settingBytes, 8*time.Hour)
+	}
+	return &setting, nil
+}
+
+// GetCompetitionSetting 获取比赛设置
+func (s *CompetitionServiceImpl) GetCompetitionSetting(ctx context.Context, competitionID uint64) (*model.CompetitionSetting, error) {
+	return loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+}
+
+// UpdateCompetitionSetting 更新比赛设置
+func (s *CompetitionServiceImpl) UpdateCompetitionSetting(ctx context.Context, param *model.UpdateCompetitionSettingParam) error {
+	updates := map[string]any{
+		"updater_id": param.Operator,
+	}
+	if param.PenaltyMinutes != nil {
+		updates["penalty_minutes"] = *param.PenaltyMinutes
+	}
+	if param.PublicScoreboard != nil {
+		updates["public_scoreboard"] = *param.PublicScoreboard
+	}
+	if param.PublicAnonymous != nil {
+		updates["public_anonymous"] = *param.PublicAnonymous
+	}
+	if param.DisplayMode != nil {
+		updates["display_mode"] = *param.DisplayMode
+	}
+	if param.Languages != nil {
+		languages, err := json.Marshal(*param.Languages)
+		if err != nil {
+			return fmt.Errorf("UpdateCompetitionSetting failed at marshal languages: %w", err)
+		}
+		updates["languages"] = string(languages)
+	}
+	if param.MaxCodeSize != nil {
+		updates["max_code_size"] = *param.MaxCodeSize
+	}
+	if param.SubmitInterval != nil {
+		updates["submit_interval"] = *param.SubmitInterval
+	}
+	if param.SubmitWindow != nil {
+		updates["submit_window"] = *param.SubmitWindow
+	}
+	if param.UserSubmitLimit != nil {
+		updates["user_submit_limit"] = *param.UserSubmitLimit
+	}
+	if param.ProblemSubmitLimit != nil {
+		updates["problem_submit_limit"] = *param.ProblemSubmitLimit
+	}
+	if param.RunInterval != nil {
+		updates["run_interval"] = *param.RunInterval
+	}
+	if param.RunWindow != nil {
+		updates["run_window"] = *param.RunWindow
+	}
+	if param.RunLimit != nil {
+		updates["run_limit"] = *param.RunLimit
+	}
+	if param.DuplicateSubmitMode != nil {
+		updates["duplicate_submit_mode"] = *param.DuplicateSubmitMode
+	}
+	if param.JudgeReportVisibility != nil {
+		updates["judge_report_visibility"] = *param.JudgeReportVisibility
+	}
+	if param.AllowUpsolve != nil {
+		updates["allow_upsolve"] = *param.AllowUpsolve
+	}
+
+	// 检查是否有更新
+	if len(updates) == 1 {
+		return nil
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		setting := model.DefaultCompetitionSetting(param.CompetitionID)
+		err := tx.Where("competition_id = ?", param.CompetitionID).
+			FirstOrCreate(setting).Error
+		if err != nil {
+			return fmt.Errorf("init competition_setting failed: %w", err)
+		}
+		return tx.Model(&model.CompetitionSetting{}).
+			Where("competition_id = ?", param.CompetitionID).
+			Updates(updates).Error
+	})
+	if err != nil {
+		return fmt.Errorf("UpdateCompetitionSetting failed: %w", err)
+	}
+
+	key := fmt.Sprintf(competitionSettingKey, param.CompetitionID)
+	retryCtx := context.WithValue(context.Background(), loggerv2.FieldsKey, ctx.Value(loggerv2.FieldsKey))
+	retry.Do(retryCtx, func() error {
+		return s.rdb.Del(retryCtx, key).Err()
+	}, retry.WithAsync(true), retry.WithCallback(func(err error) {
+		if err != nil {
+			s.log.ErrorContext(retryCtx, "UpdateCompetitionSetting failed at delete competition setting cache", logger.Error(err))
+		}
+	}))
+	return nil
+}
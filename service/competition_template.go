@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/pointer"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCompetitionNotFound         = errors.New("competition not found")
+	ErrCompetitionTemplateNotFound = errors.New("competition template not found")
+	ErrProblemUnavailable          = errors.New("problem is not published")
+)
+
+type CompetitionTemplateService interface {
+	// SaveCompetitionTemplate 将已有比赛保存为模板
+	SaveCompetitionTemplate(ctx context.Context, param *model.SaveCompetitionTemplateParam) error
+	// GetCompetitionTemplateList 获取比赛模板列表
+	GetCompetitionTemplateList(ctx context.Context, name string, page, pageSize int) ([]model.CompetitionTemplate, int, error)
+	// DeleteCompetitionTemplate 删除比赛模板
+	DeleteCompetitionTemplate(ctx context.Context, templateID uint64) error
+	// CreateCompetitionFromTemplate 根据模板创建未发布的比赛, 返回新比赛 ID
+	CreateCompetitionFromTemplate(ctx context.Context, param *model.CreateCompetitionFromTemplateParam) (uint64, error)
+}
+
+type CompetitionTemplateServiceImpl struct {
+	db  *gorm.DB
+	rdb redis.Cmdable
+	log loggerv2.Logger
+}
+
+var _ CompetitionTemplateService = (*CompetitionTemplateServiceImpl)(nil)
+
+func NewCompetitionTemplateService(db *gorm.DB, rdb redis.Cmdable, log loggerv2.Logger) CompetitionTemplateService {
+	return &CompetitionTemplateServiceImpl{
+		db:  db,
+		rdb: rdb,
+		log: log,
+	}
+}
+
+// SaveCompetitionTemplate 将已有比赛保存为模板
+func (s *CompetitionTemplateServiceImpl) SaveCompetitionTemplate(ctx context.Context, param *model.SaveCompetitionTemplateParam) error {
+	blueprint, err := loadCompetitionBlueprint(ctx, s.db, s.rdb, param.CompetitionID)
+	if err != nil {
+		return fmt.Errorf("SaveCompetitionTemplate failed: %w", err)
+	}
+
+	err = s.db.WithContext(ctx).Create(&model.CompetitionTemplate{
+		Name:            param.Name,
+		DurationMinutes: int(blueprint.Duration / time.Minute),
+		Problems:        blueprint.Problems,
+		Users:           blueprint.Users,
+		Setting:         blueprint.Setting,
+		CreatorID:       param.Operator,
+		UpdaterID:       param.Operator,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("SaveCompetitionTemplate failed at insert into competition_template: %w", err)
+	}
+	return nil
+}
+
+// GetCompetitionTemplateList 获取比赛模板列表
+func (s *CompetitionTemplateServiceImpl) GetCompetitionTemplateList(ctx context.Context, name string, page, pageSize int) ([]model.CompetitionTemplate, int, error) {
+	var templates []model.CompetitionTemplate
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&model.CompetitionTemplate{})
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetCompetitionTemplateList failed at count: %w", err)
+	}
+	err = query.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&templates).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetCompetitionTemplateList failed at select: %w", err)
+	}
+	return templates, int(total), nil
+}
+
+// DeleteCompetitionTemplate 删除比赛模板
+func (s *CompetitionTemplateServiceImpl) DeleteCompetitionTemplate(ctx context.Context, templateID uint64) error {
+	res := s.db.WithContext(ctx).
+		Where("id = ?", templateID).
+		Delete(&model.CompetitionTemplate{})
+	if res.Error != nil {
+		return fmt.Errorf("DeleteCompetitionTemplate failed: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("DeleteCompetitionTemplate failed: %w", ErrCompetitionTemplateNotFound)
+	}
+	return nil
+}
+
+// CreateCompetitionFromTemplate 根据模板创建未发布的比赛, 返回新比赛 ID
+func (s *CompetitionTemplateServiceImpl) CreateCompetitionFromTemplate(ctx context.Context, param *model.CreateCompetitionFromTemplateParam) (uint64, error) {
+	var template model.CompetitionTemplate
+	err := s.db.WithContext(ctx).
+		Where("id = ?", param.TemplateID).
+		First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("CreateCompetitionFromTemplate failed: %w", ErrCompetitionTemplateNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("CreateCompetitionFromTemplate failed at select from competition_template: %w", err)
+	}
+
+	users := template.Users
+	if users == nil {
+		// 旧模板只保存了选手 ID, 按正常状态导入
+		users = make([]model.CompetitionTemplateUser, 0, len(template.UserIDs))
+		for _, userID := range template.UserIDs {
+			users = append(users, model.CompetitionTemplateUser{UserID: userID})
+		}
+	}
+	competitionID, err := createCompetitionFromBlueprint(ctx, s.db, &competitionBlueprint{
+		Duration: time.Duration(template.DurationMinutes) * time.Minute,
+		Problems: template.Problems,
+		Users:    users,
+		Setting:  template.Setting,
+	}, param.Name, param.StartTime, param.Operator)
+	if err != nil {
+		return 0, fmt.Errorf("CreateCompetitionFromTemplate failed: %w", err)
+	}
+	deleteCompetitionUserNicknameCache(ctx, s.rdb, s.log, competitionID)
+	return competitionID, nil
+}
+
+// competitionBlueprint 创建比赛所需的题目、选手与设置, 来源于已有比赛或模板
+type competitionBlueprint struct {
+	Duration time.Duration
+	Problems []model.CompetitionTemplateProblem
+	Users    []model.CompetitionTemplateUser
+	Setting  *model.CompetitionSetting
+}
+
+// loadCompetitionBlueprint 从已有比赛中提取题目 ( 保持顺序与启用状态 )、选手名单 ( 保持选手状态与分类 ) 与比赛设置
+func loadCompetitionBlueprint(ctx context.Context, db *gorm.DB, rdb redis.Cmdable, competitionID uint64) (*competitionBlueprint, error) {
+	var competition ojmodel.Competition
+	err := db.WithContext(ctx).
+		Where("id = ?", competitionID).
+		First(&competition).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCompetitionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select from competition failed: %w", err)
+	}
+
+	competitionProblems, err := loadCompetitionProblemItems(ctx, db, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]model.CompetitionTemplateProblem, 0, len(competitionProblems))
+	for _, cp := range competitionProblems {
+		problems = append(problems, model.CompetitionTemplateProblem{
+			ProblemID: cp.ProblemID,
+			Status:    cp.Status,
+		})
+	}
+
+	var competitionUsers []ojmodel.CompetitionUser
+	err = db.WithContext(ctx).Model(&ojmodel.CompetitionUser{}).
+		Where("competition_id = ?", competitionID).
+		Select("user_id", "status").
+		Order("id ASC").
+		Find(&competitionUsers).Error
+	if err != nil {
+		return nil, fmt.Errorf("select from competition_user failed: %w", err)
+	}
+	var categories []model.CompetitionUserCategory
+	err = db.WithContext(ctx).
+		Where("competition_id = ?", competitionID).
+		Find(&categories).Error
+	if err != nil {
+		return nil, fmt.Errorf("select from competition_user_category failed: %w", err)
+	}
+	categoryMap := make(map[uint64]model.CompetitionUserCategory, len(categories))
+	for _, category := range categories {
+		categoryMap[category.UserID] = category
+	}
+	users := make([]model.CompetitionTemplateUser, 0, len(competitionUsers))
+	for _, cu := range competitionUsers {
+		users = append(users, model.CompetitionTemplateUser{
+			UserID:     cu.UserID,
+			Status:     cu.Status,
+			Category:   categoryMap[cu.UserID].Category,
+			Unofficial: categoryMap[cu.UserID].Unofficial,
+		})
+	}
+
+	setting, err := loadCompetitionSetting(ctx, db, rdb, competitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &competitionBlueprint{
+		Duration: competition.EndTime.Sub(competition.StartTime),
+		Problems: problems,
+		Users:    users,
+		Setting:  setting,
+	}, nil
+}
+
+// createCompetitionFromBlueprint 在同一事务中创建草稿比赛及其题目、选手名单、选手分类与设置, 返回新比赛 ID
+func createCompetitionFromBlueprint(ctx context.Context, db *gorm.DB, blueprint *competitionBlueprint, name string, startTime time.Time, operator uint64) (uint64, error) {
+	competition := &ojmodel.Competition{
+		Name:      name,
+		StartTime: startTime,
+		EndTime:   startTime.Add(blueprint.Duration),
+		Status:    pointer.ToPtr(ojmodel.CompetitionStatusUnpublished),
+		CreatorID: operator,
+		UpdaterID: operator,
+	}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(competition).Error
+		if err != nil {
+			return fmt.Errorf("insert into competition failed: %w", err)
+		}
+
+		err = tx.Create(&model.CompetitionLifecycle{
+			CompetitionID: competition.ID,
+			State:         model.CompetitionStateDraft,
+			UpdaterID:     operator,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("insert into competition_lifecycle failed: %w", err)
+		}
+
+		if blueprint.Setting != nil {
+			setting := *blueprint.Setting
+			setting.CompetitionID = competition.ID
+			setting.UpdaterID = operator
+			setting.CreatedAt, setting.UpdatedAt = time.Time{}, time.Time{}
+			err = tx.Create(&setting).Error
+			if err != nil {
+				return fmt.Errorf("insert into competition_setting failed: %w", err)
+			}
+		}
+
+		if len(blueprint.Problems) != 0 {
+			problemIDs := make([]uint64, 0, len(blueprint.Problems))
+			for _, p := range blueprint.Problems {
+				problemIDs = append(problemIDs, p.ProblemID)
+			}
+			var problems []ojmodel.Problem
+			err = tx.Model(&ojmodel.Problem{}).
+				Where("id IN ?", problemIDs).
+				Where("status = ?", ojmodel.ProblemStatusPublished). // 只允许导入已发布的题目
+				Select("id", "title").
+				Find(&problems).Error
+			if err != nil {
+				return fmt.Errorf("query problem title failed: %w", err)
+			}
+			titles := make(map[uint64]string, len(problems))
+			for _, p := range problems {
+				titles[p.ID] = p.Title
+			}
+
+			// 按原顺序插入, 保持题目顺序
+			competitionProblems := make([]ojmodel.CompetitionProblem, 0, len(blueprint.Problems))
+			for _, p := range blueprint.Problems {
+				title, ok := titles[p.ProblemID]
+				if !ok {
+					return fmt.Errorf("%w: problem %d", ErrProblemUnavailable, p.ProblemID)
+				}
+				status := p.Status
+				if status == nil {
+					status = pointer.ToPtr(ojmodel.CompetitionProblemStatusEnabled)
+				}
+				competitionProblems = append(competitionProblems, ojmodel.CompetitionProblem{
+					CompetitionID: competition.ID,
+					ProblemID:     p.ProblemID,
+					ProblemTitle:  title,
+					Status:        status,
+				})
+			}
+			err = tx.Create(&competitionProblems).Error
+			if err != nil {
+				return fmt.Errorf("insert into competition_problem failed: %w", err)
+			}
+			err = syncCompetitionProblemLabels(tx, competition.ID, nil)
+			if err != nil {
+				return fmt.Errorf("generate problem label failed: %w", err)
+			}
+		}
+
+		if len(blueprint.Users) != 0 {
+			userIDs := make([]uint64, 0, len(blueprint.Users))
+			blueprintUsers := make(map[uint64]model.CompetitionTemplateUser, len(blueprint.Users))
+			for _, u := range blueprint.Users {
+				userIDs = append(userIDs, u.UserID)
+				blueprintUsers[u.UserID] = u
+			}
+			var users []ojmodel.User
+			err = tx.Model(&ojmodel.User{}).
+				Where("id IN ?", userIDs).
+				Where("status = ?", ojmodel.UserStatusNormal). // 跳过已禁用的用户
+				Where("role = ?", ojmodel.UserRoleNormal).
+				Select("id", "username", "realname").
+				Find(&users).Error
+			if err != nil {
+				return fmt.Errorf("query user failed: %w", err)
+			}
+			if len(users) != 0 {
+				competitionUsers := make([]ojmodel.CompetitionUser, 0, len(users))
+				categories := make([]model.CompetitionUserCategory, 0)
+				for _, user := range users {
+					// 保留选手在来源比赛中的状态, 被禁用的选手不会因复制而恢复
+					status := blueprintUsers[user.ID].Status
+					if status == nil {
+						status = pointer.ToPtr(ojmodel.CompetitionUserStatusNormal)
+					}
+					competitionUsers = append(competitionUsers, ojmodel.CompetitionUser{
+						CompetitionID: competition.ID,
+						UserID:        user.ID,
+						Username:      user.Username,
+						Realname:      user.Realname,
+						Status:        status,
+						StartTime:     startTime,
+					})
+					// 无分类记录即为默认分类的正式选手, 无需插入
+					if u := blueprintUsers[user.ID]; u.Category != "" || u.Unofficial {
+						categories = append(categories, model.CompetitionUserCategory{
+							CompetitionID: competition.ID,
+							UserID:        user.ID,
+							Category:      u.Category,
+							Unofficial:    u.Unofficial,
+						})
+					}
+				}
+				err = tx.Create(&competitionUsers).Error
+				if err != nil {
+					return fmt.Errorf("insert into competition_user failed: %w", err)
+				}
+				if len(categories) != 0 {
+					err = tx.Create(&categories).Error
+					if err != nil {
+						return fmt.Errorf("insert into competition_user_category failed: %w", err)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return competition.ID, nil
+}
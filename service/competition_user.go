@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/gotools/retry"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const competitionUserCategoryKey = "competition:%d:user:category"
+
+// loadCompetitionUserCategories 获取比赛选手分类, 优先读取 Redis 缓存, 未设置分类的选手不在结果中
+func loadCompetitionUserCategories(ctx context.Context, db *gorm.DB, rdb redis.Cmdable, competitionID uint64) (map[uint64]model.CompetitionUserCategory, error) {
+	categoryKey := fmt.Sprintf(competitionUserCategoryKey, competitionID)
+
+	categoryMap := make(map[uint64]model.CompetitionUserCategory)
+	categoryBytes, err := rdb.Get(ctx, categoryKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(categoryBytes, &categoryMap); err == nil {
+			return categoryMap, nil
+		}
+	}
+
+	var categories []model.CompetitionUserCategory
+	err = db.WithContext(ctx).
+		Where("competition_id = ?", competitionID).
+		Find(&categories).Error
+	if err != nil {
+		return nil, fmt.Errorf("loadCompetitionUserCategories failed at select from competition_user_category: %w", err)
+	}
+	for _, category := range categories {
+		categoryMap[category.UserID] = category
+	}
+
+	if categoryBytes, err = json.Marshal(categoryMap); err == nil {
+		rdb.Set(ctx, categoryKey, categoryBytes, 8*time.Hour)
+	}
+	return categoryMap, nil
+}
+
+// upsertCompetitionUserCategory 批量设置比赛选手分类
+func upsertCompetitionUserCategory(tx *gorm.DB, competitionID uint64, userIDList []uint64, category string, unofficial bool) error {
+	categories := make([]model.CompetitionUserCategory, 0, len(userIDList))
+	for _, userID := range userIDList {
+		categories = append(categories, model.CompetitionUserCategory{
+			CompetitionID: competitionID,
+			UserID:        userID,
+			Category:      category,
+			Unofficial:    unofficial,
+		})
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "competition_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"category", "unofficial"}),
+	}).Create(&categories).Error
+}
+
+// SetCompetitionUserCategory 设置比赛选手分类
+func (s *UserServiceImpl) SetCompetitionUserCategory(ctx context.Context, param *model.SetCompetitionUserCategoryParam) error {
+	err := upsertCompetitionUserCategory(s.db.WithContext(ctx), param.CompetitionID, param.UserIDList, param.Category, param.Unofficial)
+	if err != nil {
+		return fmt.Errorf("SetCompetitionUserCategory failed: %w", err)
+	}
+	s.deleteCompetitionUserCategoryCache(ctx, param.CompetitionID)
+	return nil
+}
+
+// GetCompetitionUserCategories 获取比赛选手分类, 未设置分类的选手不在结果中
+func (s *UserServiceImpl) GetCompetitionUserCategories(ctx context.Context, competitionID uint64) (map[uint64]model.CompetitionUserCategory, error) {
+	return loadCompetitionUserCategories(ctx, s.db, s.rdb, competitionID)
+}
+
+// deleteCompetitionUserCategoryCache 异步删除比赛选手分类缓存
+func (s *UserServiceImpl) deleteCompetitionUserCategoryCache(ctx context.Context, competitionID uint64) {
+	key := fmt.Sprintf(competitionUserCategoryKey, competitionID)
+	retryCtx := context.WithValue(context.Background(), loggerv2.FieldsKey, ctx.Value(loggerv2.FieldsKey))
+	retry.Do(retryCtx, func() error {
+		return s.rdb.Del(retryCtx, key).Err()
+	}, retry.WithAsync(true), retry.WithCallback(func(err error) {
+		if err != nil {
+			s.log.ErrorContext(retryCtx, "delete competition user category cache failed", logger.Error(err))
+		}
+	}))
+}
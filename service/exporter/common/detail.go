@@ -30,6 +30,8 @@ WITH ranked_submissions AS (
         ) AS accepted_order
     FROM submission
     WHERE competition_id = ?
+        -- 赛后补题不计入正式成绩
+        AND id NOT IN (SELECT submission_id FROM submission_upsolve WHERE competition_id = ?)
 ),
 first_accepted AS (
     SELECT
@@ -48,9 +50,18 @@ SELECT
     u.username AS username,
     u.realname AS realname,
     fa.accepted_time AS accepted_time,
-    fa.attempts_before_accepted AS attempts_before_accepted
+    fa.attempts_before_accepted AS attempts_before_accepted,
+    IFNULL(c.category, '') AS category,
+    IFNULL(p.nickname, '') AS nickname,
+    EXISTS (
+        SELECT 1 FROM competition_disqualification d
+        WHERE d.competition_id = fa.competition_id AND d.user_id = fa.user_id AND d.revoked_at IS NULL
+    ) AS disqualified
 FROM first_accepted fa
 LEFT JOIN user u ON fa.user_id = u.id
+LEFT JOIN competition_user_category c
+    ON c.competition_id = fa.competition_id AND c.user_id = fa.user_id
+LEFT JOIN user_profile p ON fa.user_id = p.user_id
 ORDER BY fa.user_id, fa.problem_id
 `
 
@@ -62,11 +73,14 @@ type AcceptedDetail struct {
 	Realname               string    `gorm:"realname" json:"realname"`
 	AcceptedTime           time.Time `gorm:"accepted_time" json:"accepted_time"`
 	AttemptsBeforeAccepted int       `gorm:"attempts_before_accepted" json:"attempts_before_accepted"`
+	Category               string    `gorm:"category" json:"category"`
+	Disqualified           bool      `gorm:"disqualified" json:"disqualified"`
+	Nickname               string    `gorm:"nickname" json:"nickname"`
 }
 
 func FetchDetail(db *gorm.DB, ctx context.Context, competitionID uint64) ([]AcceptedDetail, error) {
 	var details []AcceptedDetail
-	err := db.WithContext(ctx).Raw(fmt.Sprintf(detailSql), competitionID).Scan(&details).Error
+	err := db.WithContext(ctx).Raw(fmt.Sprintf(detailSql), competitionID, competitionID).Scan(&details).Error
 	if err != nil {
 		return nil, fmt.Errorf("fetch detail failed: %w", err)
 	}
@@ -76,3 +90,11 @@ func FetchDetail(db *gorm.DB, ctx context.Context, competitionID uint64) ([]Acce
 func (d *AcceptedDetail) GetAcceptTime() string {
 	return d.AcceptedTime.Format("15:04:05.000")
 }
+
+// GetCellValue 导出单元格中的通过情况, 格式为 "通过时间 (+通过前错误次数)"
+func (d *AcceptedDetail) GetCellValue() string {
+	if d.AttemptsBeforeAccepted == 0 {
+		return d.GetAcceptTime()
+	}
+	return fmt.Sprintf("%s (+%d)", d.GetAcceptTime(), d.AttemptsBeforeAccepted)
+}
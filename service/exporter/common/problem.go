@@ -0,0 +1,59 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+const problemSql = `
+SELECT
+    cp.problem_id AS problem_id,
+    cp.problem_title AS problem_title,
+    IFNULL(l.label, '') AS label
+FROM competition_problem cp
+LEFT JOIN competition_problem_label l
+    ON l.competition_id = cp.competition_id AND l.problem_id = cp.problem_id
+WHERE cp.competition_id = ? AND cp.status = 1
+ORDER BY l.position IS NULL, l.position, cp.id
+`
+
+// ProblemHeader 导出表头中的比赛题目
+type ProblemHeader struct {
+	ProblemID    uint64 `gorm:"problem_id" json:"problem_id"`
+	ProblemTitle string `gorm:"problem_title" json:"problem_title"`
+	Label        string `gorm:"label" json:"label"`
+}
+
+// FetchProblemList 按题目顺序获取比赛中启用的题目及其标号, 未生成标号的题目以题目 ID 代替
+func FetchProblemList(db *gorm.DB, ctx context.Context, competitionID uint64) ([]ProblemHeader, error) {
+	var problems []ProblemHeader
+	err := db.WithContext(ctx).Raw(problemSql, competitionID).Scan(&problems).Error
+	if err != nil {
+		return nil, fmt.Errorf("fetch problem list failed: %w", err)
+	}
+	for i := range problems {
+		if problems[i].Label == "" {
+			problems[i].Label = strconv.FormatUint(problems[i].ProblemID, 10)
+		}
+	}
+	return problems, nil
+}
+
+// FetchDetailMap 获取比赛通过详情, 按用户 ID 与题目 ID 索引
+func FetchDetailMap(db *gorm.DB, ctx context.Context, competitionID uint64) (map[uint64]map[uint64]AcceptedDetail, error) {
+	details, err := FetchDetail(db, ctx, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	detailMap := make(map[uint64]map[uint64]AcceptedDetail)
+	for _, detail := range details {
+		if detailMap[detail.UserID] == nil {
+			detailMap[detail.UserID] = make(map[uint64]AcceptedDetail)
+		}
+		detailMap[detail.UserID][detail.ProblemID] = detail
+	}
+	return detailMap, nil
+}
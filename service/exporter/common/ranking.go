@@ -3,22 +3,128 @@ package common
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	ojmodel "github.com/to404hanga/online_judge_common/model"
 	"gorm.io/gorm"
 )
 
+const disqualifiedColumn = `EXISTS (
+    SELECT 1 FROM competition_disqualification d
+    WHERE d.competition_id = cu.competition_id AND d.user_id = cu.user_id AND d.revoked_at IS NULL
+) AS disqualified`
+
+// adjustmentJoin 选手罚时调整分钟数之和, 排序表达式中不能引用列别名, 通过关联派生表在 SELECT 与 ORDER BY 中共用
+const adjustmentJoin = `LEFT JOIN (
+    SELECT user_id, SUM(minutes) AS minutes FROM competition_time_adjustment
+    WHERE competition_id = ? GROUP BY user_id
+) a ON a.user_id = cu.user_id`
+
+const adjustmentColumn = `IFNULL(a.minutes, 0) AS adjustment_minutes,
+(
+    SELECT COUNT(*) FROM submission_verdict_override o
+    WHERE o.competition_id = cu.competition_id AND o.user_id = cu.user_id
+) AS override_count`
+
+// RankingRow 导出用的排名数据, 附带选手分类、取消资格状态与裁判调整,
+// TotalTime 已包含罚时调整
+type RankingRow struct {
+	ojmodel.CompetitionUser
+	Category          string `gorm:"column:category" json:"category"`
+	Unofficial        bool   `gorm:"column:unofficial" json:"unofficial"`
+	Disqualified      bool   `gorm:"column:disqualified" json:"disqualified"`
+	AdjustmentMinutes int64  `gorm:"column:adjustment_minutes" json:"adjustment_minutes"`
+	OverrideCount     int    `gorm:"column:override_count" json:"override_count"`
+	Nickname          string `gorm:"column:nickname" json:"nickname"`
+}
+
+// GetAdjustment 导出单元格中的裁判调整, 格式为 "罚时调整分钟数 / 人工改判次数"
+func (r *RankingRow) GetAdjustment() string {
+	if r.AdjustmentMinutes == 0 && r.OverrideCount == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%+d分钟 / 改判%d次", r.AdjustmentMinutes, r.OverrideCount)
+}
+
 // FetchRanking 从数据库中获取排名数据
-func FetchRanking(db *gorm.DB, ctx context.Context, competitionID uint64, page, limit int) ([]ojmodel.CompetitionUser, error) {
-	var ranks []ojmodel.CompetitionUser
+func FetchRanking(db *gorm.DB, ctx context.Context, competitionID uint64, page, limit int) ([]RankingRow, error) {
+	var ranks []RankingRow
 	if err := db.WithContext(ctx).
-		Model(&ojmodel.CompetitionUser{}).
-		Where("competition_id = ?", competitionID).
-		Order("pass_count DESC, total_time ASC").
+		Table("competition_user cu").
+		Select("cu.*, IFNULL(c.category, '') AS category, IFNULL(c.unofficial, 0) AS unofficial, IFNULL(p.nickname, '') AS nickname, "+disqualifiedColumn+", "+adjustmentColumn).
+		Joins("LEFT JOIN competition_user_category c ON c.competition_id = cu.competition_id AND c.user_id = cu.user_id").
+		Joins("LEFT JOIN user_profile p ON p.user_id = cu.user_id").
+		Joins(adjustmentJoin, competitionID).
+		Where("cu.competition_id = ?", competitionID).
+		Order("cu.pass_count DESC, cu.total_time + IFNULL(a.minutes, 0) * 60000 ASC, cu.id ASC").
 		Limit(limit).
 		Offset((page - 1) * limit).
-		Find(&ranks).Error; err != nil {
+		Scan(&ranks).Error; err != nil {
 		return nil, fmt.Errorf("fetch ranking failed: %w", err)
 	}
+	for i := range ranks {
+		ranks[i].TotalTime += ranks[i].AdjustmentMinutes * 60000
+	}
 	return ranks, nil
 }
+
+// RankCounter 按排名顺序依次计算正式排名与分类排名, 成绩相同的选手排名相同, 打星与取消资格的选手不占用排名
+type RankCounter struct {
+	overall    rankCounter
+	categories map[string]*rankCounter
+}
+
+type rankCounter struct {
+	count     int
+	rank      int
+	passCount int
+	totalTime int64
+}
+
+func (c *rankCounter) next(passCount int, totalTime int64) int {
+	c.count++
+	if c.count == 1 || c.passCount != passCount || c.totalTime != totalTime {
+		c.rank = c.count
+		c.passCount, c.totalTime = passCount, totalTime
+	}
+	return c.rank
+}
+
+func NewRankCounter() *RankCounter {
+	return &RankCounter{
+		categories: make(map[string]*rankCounter),
+	}
+}
+
+// Next 返回当前选手的正式排名与分类排名, 打星与取消资格的选手均为 0
+func (r *RankCounter) Next(rank *RankingRow) (int, int) {
+	if rank.Unofficial || rank.Disqualified {
+		return 0, 0
+	}
+	counter, ok := r.categories[rank.Category]
+	if !ok {
+		counter = &rankCounter{}
+		r.categories[rank.Category] = counter
+	}
+	return r.overall.next(rank.PassCount, rank.TotalTime), counter.next(rank.PassCount, rank.TotalTime)
+}
+
+// FormatRank 格式化排名, 取消资格的选手显示为 DQ, 打星选手显示为 *
+func FormatRank(row *RankingRow, rank int) string {
+	switch {
+	case row.Disqualified:
+		return "DQ"
+	case rank == 0:
+		return "*"
+	default:
+		return strconv.Itoa(rank)
+	}
+}
+
+// CategoryName 导出中显示的分类名称
+func CategoryName(category string) string {
+	if category == "" {
+		return "默认"
+	}
+	return category
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"testing"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+)
+
+func TestRankCounter(t *testing.T) {
+	row := func(passCount int, totalTime int64, category string, unofficial, disqualified bool) RankingRow {
+		return RankingRow{
+			CompetitionUser: ojmodel.CompetitionUser{PassCount: passCount, TotalTime: totalTime},
+			Category:        category,
+			Unofficial:      unofficial,
+			Disqualified:    disqualified,
+		}
+	}
+	tests := []struct {
+		name             string
+		rows             []RankingRow
+		wantRanks        []int
+		wantCategoryRank []int
+		wantFormatted    []string
+	}{
+		{
+			name:             "成绩相同的选手排名相同",
+			rows:             []RankingRow{row(3, 100, "", false, false), row(3, 100, "", false, false), row(2, 50, "", false, false)},
+			wantRanks:        []int{1, 1, 3},
+			wantCategoryRank: []int{1, 1, 3},
+			wantFormatted:    []string{"1", "1", "3"},
+		},
+		{
+			name:             "打星与取消资格的选手不占用排名",
+			rows:             []RankingRow{row(5, 10, "", false, true), row(4, 10, "", true, false), row(3, 10, "", false, false)},
+			wantRanks:        []int{0, 0, 1},
+			wantCategoryRank: []int{0, 0, 1},
+			wantFormatted:    []string{"DQ", "*", "1"},
+		},
+		{
+			name:             "分类排名独立计算",
+			rows:             []RankingRow{row(3, 10, "本科", false, false), row(3, 20, "专科", false, false), row(2, 10, "本科", false, false)},
+			wantRanks:        []int{1, 2, 3},
+			wantCategoryRank: []int{1, 1, 2},
+			wantFormatted:    []string{"1", "2", "3"},
+		},
+		{
+			name:             "取消资格的打星选手显示为 DQ",
+			rows:             []RankingRow{row(1, 10, "", true, true)},
+			wantRanks:        []int{0},
+			wantCategoryRank: []int{0},
+			wantFormatted:    []string{"DQ"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := NewRankCounter()
+			for i := range tt.rows {
+				rank, categoryRank := counter.Next(&tt.rows[i])
+				if rank != tt.wantRanks[i] || categoryRank != tt.wantCategoryRank[i] {
+					t.Errorf("row %d: Next() = (%d, %d), want (%d, %d)", i, rank, categoryRank, tt.wantRanks[i], tt.wantCategoryRank[i])
+				}
+				if got := FormatRank(&tt.rows[i], rank); got != tt.wantFormatted[i] {
+					t.Errorf("row %d: FormatRank() = %q, want %q", i, got, tt.wantFormatted[i])
+				}
+			}
+		})
+	}
+}
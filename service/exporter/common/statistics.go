@@ -0,0 +1,76 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// statisticsSql 按题目统计已判题的提交, 不包含赛后补题与被取消资格选手的提交
+const statisticsSql = `
+SELECT
+    s.problem_id AS problem_id,
+    COUNT(*) AS submissions,
+    COUNT(DISTINCT s.user_id) AS tried,
+    COUNT(DISTINCT CASE WHEN s.result = 1 THEN s.user_id END) AS solved,
+    IFNULL(TIMESTAMPDIFF(MICROSECOND, MIN(c.start_time), MIN(CASE WHEN s.result = 1 THEN s.created_at END)) DIV 1000, -1) AS first_solve_time,
+    SUM(s.result = 1) AS accepted,
+    SUM(s.result = 2) AS wrong_answer,
+    SUM(s.result = 3) AS compile_error,
+    SUM(s.result = 4) AS runtime_error,
+    SUM(s.result = 5) AS time_limit_exceeded,
+    SUM(s.result = 6) AS memory_limit_exceeded,
+    SUM(s.result = 7) AS output_limit_exceeded
+FROM submission s
+JOIN competition c ON c.id = s.competition_id
+WHERE s.competition_id = ? AND s.status = 2 AND s.result != 0
+    AND s.id NOT IN (SELECT submission_id FROM submission_upsolve WHERE competition_id = ?)
+    AND NOT EXISTS (
+        SELECT 1 FROM competition_disqualification d
+        WHERE d.competition_id = s.competition_id AND d.user_id = s.user_id AND d.revoked_at IS NULL
+    )
+GROUP BY s.problem_id
+`
+
+// ProblemStatisticsRow 导出的题目统计
+type ProblemStatisticsRow struct {
+	ProblemID           uint64 `gorm:"column:problem_id" json:"problem_id"`
+	Submissions         int    `gorm:"column:submissions" json:"submissions"`
+	Tried               int    `gorm:"column:tried" json:"tried"`
+	Solved              int    `gorm:"column:solved" json:"solved"`
+	FirstSolveTime      int64  `gorm:"column:first_solve_time" json:"first_solve_time"` // 首次通过距比赛开始的毫秒数, -1 表示无人通过
+	Accepted            int    `gorm:"column:accepted" json:"accepted"`
+	WrongAnswer         int    `gorm:"column:wrong_answer" json:"wrong_answer"`
+	CompileError        int    `gorm:"column:compile_error" json:"compile_error"`
+	RuntimeError        int    `gorm:"column:runtime_error" json:"runtime_error"`
+	TimeLimitExceeded   int    `gorm:"column:time_limit_exceeded" json:"time_limit_exceeded"`
+	MemoryLimitExceeded int    `gorm:"column:memory_limit_exceeded" json:"memory_limit_exceeded"`
+	OutputLimitExceeded int    `gorm:"column:output_limit_exceeded" json:"output_limit_exceeded"`
+}
+
+// FetchProblemStatistics 获取比赛各题统计, 按题目 ID 索引, 没有提交的题目不在结果中
+func FetchProblemStatistics(db *gorm.DB, ctx context.Context, competitionID uint64) (map[uint64]ProblemStatisticsRow, error) {
+	var rows []ProblemStatisticsRow
+	err := db.WithContext(ctx).Raw(statisticsSql, competitionID, competitionID).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("fetch problem statistics failed: %w", err)
+	}
+	statistics := make(map[uint64]ProblemStatisticsRow, len(rows))
+	for _, row := range rows {
+		statistics[row.ProblemID] = row
+	}
+	return statistics, nil
+}
+
+// GetFirstSolveTime 首次通过时间, 格式为 "时:分:秒.毫秒", 无人通过时为空
+func (r *ProblemStatisticsRow) GetFirstSolveTime() string {
+	if r.Solved == 0 || r.FirstSolveTime < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d:%02d.%03d",
+		r.FirstSolveTime/3600000,
+		(r.FirstSolveTime%3600000)/60000,
+		(r.FirstSolveTime%60000)/1000,
+		r.FirstSolveTime%1000)
+}
@@ -7,7 +7,6 @@ import (
 	"io"
 	"strconv"
 
-	ojmodel "github.com/to404hanga/online_judge_common/model"
 	"github.com/to404hanga/online_judge_controller/service/exporter"
 	"github.com/to404hanga/online_judge_controller/service/exporter/common"
 	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
@@ -28,25 +27,20 @@ func NewCSVDetailExporter(db *gorm.DB, log loggerv2.Logger) exporter.Exporter {
 	}
 }
 
-func (e *CSVDetailExporter) Export(ctx context.Context, competitionID uint64, writer io.Writer) error {
+func (e *CSVDetailExporter) Export(ctx context.Context, competitionID uint64, writer io.Writer, opts *exporter.Options) error {
 	csvWriter := csv.NewWriter(writer)
 	defer csvWriter.Flush()
 
-	var problemIDList []uint64
-	err := e.db.WithContext(ctx).Model(&ojmodel.CompetitionProblem{}).
-		Where("competition_id = ?", competitionID).
-		Where("status = ?", ojmodel.CompetitionProblemStatusEnabled).
-		Order("problem_id ASC").
-		Pluck("problem_id", &problemIDList).Error
+	problems, err := common.FetchProblemList(e.db, ctx, competitionID)
 	if err != nil {
-		return fmt.Errorf("get problem id list failed: %w", err)
+		return fmt.Errorf("get problem list failed: %w", err)
 	}
-	headers := make([]string, 0, (len(problemIDList)+1)*2)
-	headers = append(headers, "学号", "姓名")
-	for _, problemID := range problemIDList {
+	headers := make([]string, 0, (len(problems)+1)*2+2)
+	headers = append(headers, "学号", "姓名", "类别", "备注")
+	for _, problem := range problems {
 		headers = append(headers,
-			fmt.Sprintf("%d题-通过时间", problemID),
-			fmt.Sprintf("%d题-尝试次数", problemID))
+			fmt.Sprintf("%s题-通过时间", problem.Label),
+			fmt.Sprintf("%s题-尝试次数", problem.Label))
 	}
 
 	err = csvWriter.Write(headers)
@@ -58,12 +52,27 @@ func (e *CSVDetailExporter) Export(ctx context.Context, competitionID uint64, wr
 	if err != nil {
 		return fmt.Errorf("csv exporter fetch detail failed: %w", err)
 	}
+	// 通过详情按用户 ID 排序, 同一用户的记录合并为一行
 	record := make([]string, 0, len(headers))
-	for _, detail := range details {
+	for i := 0; i < len(details); {
+		j := i
+		userDetails := make(map[uint64]common.AcceptedDetail)
+		for ; j < len(details) && details[j].UserID == details[i].UserID; j++ {
+			userDetails[details[j].ProblemID] = details[j]
+		}
+		if !opts.Match(details[i].Category, details[i].Disqualified) {
+			i = j
+			continue
+		}
 		record = record[:0] // 清空记录
-		record = append(record, detail.Username, detail.Realname)
-		for _, problemID := range problemIDList {
-			if detail.ProblemID == problemID {
+		remark := ""
+		if details[i].Disqualified {
+			remark = "DQ"
+		}
+		username, realname := opts.Display(details[i].Username, details[i].Realname, details[i].Nickname)
+		record = append(record, username, realname, common.CategoryName(details[i].Category), remark)
+		for _, problem := range problems {
+			if detail, ok := userDetails[problem.ProblemID]; ok {
 				record = append(record, detail.GetAcceptTime(), strconv.Itoa(detail.AttemptsBeforeAccepted))
 			} else {
 				record = append(record, "", "")
@@ -73,6 +82,7 @@ func (e *CSVDetailExporter) Export(ctx context.Context, competitionID uint64, wr
 		if err != nil {
 			return fmt.Errorf("write record failed: %w", err)
 		}
+		i = j
 	}
 	return nil
 }
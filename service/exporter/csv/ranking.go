@@ -8,10 +8,8 @@ import (
 	"strconv"
 	"strings"
 
-	ojmodel "github.com/to404hanga/online_judge_common/model"
 	"github.com/to404hanga/online_judge_controller/service/exporter"
 	"github.com/to404hanga/online_judge_controller/service/exporter/common"
-	"github.com/to404hanga/pkg404/gotools/transform"
 	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
 	"gorm.io/gorm"
 )
@@ -30,13 +28,22 @@ func NewStreamableCSVRankingExporter(db *gorm.DB, log loggerv2.Logger) exporter.
 	}
 }
 
-func (e *StreamableCSVRankingExporter) Export(ctx context.Context, competitionID uint64, writer io.Writer) error {
+func (e *StreamableCSVRankingExporter) Export(ctx context.Context, competitionID uint64, writer io.Writer, opts *exporter.Options) error {
+	problems, err := common.FetchProblemList(e.db, ctx, competitionID)
+	if err != nil {
+		return fmt.Errorf("get problem list failed: %w", err)
+	}
+	detailMap, err := common.FetchDetailMap(e.db, ctx, competitionID)
+	if err != nil {
+		return fmt.Errorf("csv exporter fetch detail failed: %w", err)
+	}
+
 	ectx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	batchSize := 1000
 	page := 1
-	rankCh := make(chan []ojmodel.CompetitionUser, 3)
+	rankCh := make(chan []common.RankingRow, 3)
 	errCh := make(chan error, 1)
 
 	go func() {
@@ -65,13 +72,14 @@ func (e *StreamableCSVRankingExporter) Export(ctx context.Context, competitionID
 	csvWriter := csv.NewWriter(writer)
 	defer csvWriter.Flush()
 
-	err := e.writeHeader(csvWriter)
+	err = e.writeHeader(csvWriter, problems)
 	if err != nil {
 		return fmt.Errorf("write header failed: %w", err)
 	}
 
 	timeBuilder := &strings.Builder{}
 	timeBuilder.Grow(12) // %02d:%02d:%02d.%03d 最小长度为 12 字节
+	rankCounter := common.NewRankCounter()
 
 	var goroutineErr error
 	for {
@@ -83,7 +91,7 @@ func (e *StreamableCSVRankingExporter) Export(ctx context.Context, competitionID
 				}
 				return nil
 			}
-			if err = e.processRanks(timeBuilder, csvWriter, ranks); err != nil {
+			if err = e.processRanks(timeBuilder, csvWriter, ranks, problems, detailMap, rankCounter, opts); err != nil {
 				return fmt.Errorf("process ranks failed: %w", err)
 			}
 		case err = <-errCh:
@@ -94,32 +102,60 @@ func (e *StreamableCSVRankingExporter) Export(ctx context.Context, competitionID
 	}
 }
 
-// processRanks 处理排名数据，将其转换为 CSV 记录
-func (e *StreamableCSVRankingExporter) processRanks(timeBuilder *strings.Builder, csvWriter *csv.Writer, ranks []ojmodel.CompetitionUser) error {
-	records := transform.SliceFromSlice(ranks, func(idx int, rank ojmodel.CompetitionUser) []string {
+// processRanks 处理排名数据，将其转换为 CSV 记录, 排名基于全部选手计算, 再按分类筛选
+func (e *StreamableCSVRankingExporter) processRanks(timeBuilder *strings.Builder, csvWriter *csv.Writer, ranks []common.RankingRow, problems []common.ProblemHeader, detailMap map[uint64]map[uint64]common.AcceptedDetail, rankCounter *common.RankCounter, opts *exporter.Options) error {
+	records := make([][]string, 0, len(ranks))
+	for _, rank := range ranks {
+		overallRank, categoryRank := rankCounter.Next(&rank)
+		if !opts.Match(rank.Category, rank.Disqualified) {
+			continue
+		}
+		username, realname := opts.Display(rank.Username, rank.Realname, rank.Nickname)
 		timeBuilder.Reset()
 		fmt.Fprintf(timeBuilder, "%02d:%02d:%02d.%03d",
 			rank.TotalTime/3600000,
 			(rank.TotalTime%3600000)/60000,
 			(rank.TotalTime%60000)/1000,
 			rank.TotalTime%1000)
-		return []string{
-			rank.Username,                // 学号
-			rank.Realname,                // 姓名
-			strconv.Itoa(rank.PassCount), // 通过题目数
-			timeBuilder.String(),         // 总耗时
+		record := make([]string, 0, 8+len(problems))
+		record = append(record,
+			common.FormatRank(&rank, overallRank),  // 排名
+			common.FormatRank(&rank, categoryRank), // 分类排名
+			common.CategoryName(rank.Category),     // 类别
+			username,                               // 学号
+			realname,                               // 姓名
+			strconv.Itoa(rank.PassCount),           // 通过题目数
+			timeBuilder.String(),                   // 总耗时
+			rank.GetAdjustment(),                   // 裁判调整
+		)
+		// 各题通过情况, 按题目顺序排列
+		for _, problem := range problems {
+			detail, ok := detailMap[rank.UserID][problem.ProblemID]
+			if !ok {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, detail.GetCellValue())
 		}
-	})
+		records = append(records, record)
+	}
 	return csvWriter.WriteAll(records)
 }
 
 // writeHeader 写入 CSV 头部
-func (e *StreamableCSVRankingExporter) writeHeader(csvWriter *csv.Writer) error {
+func (e *StreamableCSVRankingExporter) writeHeader(csvWriter *csv.Writer, problems []common.ProblemHeader) error {
 	headers := []string{
+		"排名",
+		"分类排名",
+		"类别",
 		"学号",
 		"姓名",
 		"通过题目数",
 		"总耗时",
+		"裁判调整",
+	}
+	for _, problem := range problems {
+		headers = append(headers, problem.Label)
 	}
 	return csvWriter.Write(headers)
 }
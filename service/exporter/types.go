@@ -3,8 +3,39 @@ package exporter
 import (
 	"context"
 	"io"
+
+	"github.com/to404hanga/online_judge_controller/pkg/privacy"
 )
 
 type Exporter interface {
-	Export(ctx context.Context, competitionID uint64, writer io.Writer) error
+	Export(ctx context.Context, competitionID uint64, writer io.Writer, opts *Options) error
+}
+
+// Options 导出选项
+type Options struct {
+	Category        *string // 只导出指定分类的选手, 空字符串表示默认分类, nil 表示全部
+	SplitByCategory bool    // 按分类拆分为多个工作表, 仅 XLSX 排名导出支持
+	// IncludeDisqualified 是否导出被取消资格的选手, 导出时标记为 DQ 且不占用排名
+	IncludeDisqualified bool
+	// DisplayMode 学号与姓名的展示模式, 默认导出完整信息, 用于公示时按比赛设置脱敏
+	DisplayMode privacy.DisplayMode
+}
+
+// Match 判断选手是否满足筛选条件
+func (o *Options) Match(category string, disqualified bool) bool {
+	if o == nil {
+		return !disqualified
+	}
+	if disqualified && !o.IncludeDisqualified {
+		return false
+	}
+	return o.Category == nil || *o.Category == category
+}
+
+// Display 按展示模式返回导出的学号与姓名
+func (o *Options) Display(username, realname, nickname string) (string, string) {
+	if o == nil {
+		return username, realname
+	}
+	return o.DisplayMode.Apply(username, realname, nickname)
 }
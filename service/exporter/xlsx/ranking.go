@@ -7,7 +7,6 @@ import (
 	"strconv"
 	"strings"
 
-	ojmodel "github.com/to404hanga/online_judge_common/model"
 	"github.com/to404hanga/online_judge_controller/service/exporter"
 	"github.com/to404hanga/online_judge_controller/service/exporter/common"
 	"github.com/to404hanga/pkg404/logger"
@@ -30,7 +29,7 @@ func NewStreamableXLSXRankingExporter(db *gorm.DB, log loggerv2.Logger) exporter
 	}
 }
 
-func (e *StreamableXLSXRankingExporter) Export(ctx context.Context, competitionID uint64, writer io.Writer) error {
+func (e *StreamableXLSXRankingExporter) Export(ctx context.Context, competitionID uint64, writer io.Writer, opts *exporter.Options) error {
 	ectx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -49,13 +48,25 @@ func (e *StreamableXLSXRankingExporter) Export(ctx context.Context, competitionI
 	}
 	f.SetActiveSheet(index)
 
-	if err = e.writeHeader(f, sheetName); err != nil {
+	problems, err := common.FetchProblemList(e.db, ctx, competitionID)
+	if err != nil {
+		return fmt.Errorf("get problem list failed: %w", err)
+	}
+	detailMap, err := common.FetchDetailMap(e.db, ctx, competitionID)
+	if err != nil {
+		return fmt.Errorf("xlsx exporter fetch detail failed: %w", err)
+	}
+
+	if err = e.writeHeader(f, sheetName, problems); err != nil {
 		return fmt.Errorf("write header failed: %w", err)
 	}
+	if err = e.writeStatistics(ctx, f, competitionID, problems); err != nil {
+		return fmt.Errorf("write statistics failed: %w", err)
+	}
 
 	batchSize := 1000
 	page := 1
-	rankCh := make(chan []ojmodel.CompetitionUser, 3)
+	rankCh := make(chan []common.RankingRow, 3)
 	errCh := make(chan error, 1)
 
 	go func() {
@@ -84,7 +95,14 @@ func (e *StreamableXLSXRankingExporter) Export(ctx context.Context, competitionI
 	timeBuilder := &strings.Builder{}
 	timeBuilder.Grow(12) // %02d:%02d:%02d.%03d 最小长度为 12 字节
 
-	currentRow := 2 // 从第二行开始写入数据（第一行是表头）
+	mainSheet := &rankingSheet{name: sheetName, row: 2} // 从第二行开始写入数据（第一行是表头）
+	sheets := &rankingSheets{
+		main:       mainSheet,
+		categories: make(map[string]*rankingSheet),
+		names:      map[string]struct{}{sheetName: {}},
+		split:      opts != nil && opts.SplitByCategory,
+	}
+	rankCounter := common.NewRankCounter()
 	var goroutineErr error
 
 	for {
@@ -100,8 +118,7 @@ func (e *StreamableXLSXRankingExporter) Export(ctx context.Context, competitionI
 				}
 				return nil
 			}
-			if err = e.processRanks(timeBuilder, f, sheetName, ranks, &currentRow); err != nil {
-
+			if err = e.processRanks(timeBuilder, f, sheets, ranks, problems, detailMap, rankCounter, opts); err != nil {
 				return fmt.Errorf("process ranks failed: %w", err)
 			}
 		case err = <-errCh:
@@ -112,9 +129,66 @@ func (e *StreamableXLSXRankingExporter) Export(ctx context.Context, competitionI
 	}
 }
 
-// processRanks 处理排名数据，将其写 Excel 文件
-func (e *StreamableXLSXRankingExporter) processRanks(timeBuilder *strings.Builder, f *excelize.File, sheetName string, ranks []ojmodel.CompetitionUser, currentRow *int) error {
+// rankingSheet 排名工作表及其下一行行号
+type rankingSheet struct {
+	name string
+	row  int
+}
+
+// rankingSheets 总排名工作表与按分类拆分的工作表
+type rankingSheets struct {
+	main       *rankingSheet
+	categories map[string]*rankingSheet
+	names      map[string]struct{}
+	split      bool
+}
+
+// categorySheet 获取分类对应的工作表, 不存在时创建并写入表头
+func (e *StreamableXLSXRankingExporter) categorySheet(f *excelize.File, sheets *rankingSheets, category string, problems []common.ProblemHeader) (*rankingSheet, error) {
+	if sheet, ok := sheets.categories[category]; ok {
+		return sheet, nil
+	}
+	name := sheetNameOf(category)
+	for i := 2; ; i++ {
+		if _, ok := sheets.names[name]; !ok {
+			break
+		}
+		name = sheetNameOf(fmt.Sprintf("%s(%d)", category, i))
+	}
+	if _, err := f.NewSheet(name); err != nil {
+		return nil, fmt.Errorf("create sheet failed: %w", err)
+	}
+	if err := e.writeHeader(f, name, problems); err != nil {
+		return nil, fmt.Errorf("write header failed: %w", err)
+	}
+	sheet := &rankingSheet{name: name, row: 2}
+	sheets.categories[category] = sheet
+	sheets.names[name] = struct{}{}
+	return sheet, nil
+}
+
+// sheetNameOf 将分类名转换为合法的工作表名称, 去除非法字符并截断至 31 个字符
+func sheetNameOf(category string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '_'
+		}
+		return r
+	}, common.CategoryName(category))
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	return name
+}
+
+// processRanks 处理排名数据，将其写 Excel 文件, 排名基于全部选手计算, 再按分类筛选
+func (e *StreamableXLSXRankingExporter) processRanks(timeBuilder *strings.Builder, f *excelize.File, sheets *rankingSheets, ranks []common.RankingRow, problems []common.ProblemHeader, detailMap map[uint64]map[uint64]common.AcceptedDetail, rankCounter *common.RankCounter, opts *exporter.Options) error {
 	for _, rank := range ranks {
+		overallRank, categoryRank := rankCounter.Next(&rank)
+		if !opts.Match(rank.Category, rank.Disqualified) {
+			continue
+		}
+		username, realname := opts.Display(rank.Username, rank.Realname, rank.Nickname)
 		timeBuilder.Reset()
 		fmt.Fprintf(timeBuilder, "%02d:%02d:%02d.%03d",
 			rank.TotalTime/3600000,
@@ -124,52 +198,76 @@ func (e *StreamableXLSXRankingExporter) processRanks(timeBuilder *strings.Builde
 
 		// 写入每一行数据
 		rowData := []interface{}{
-			rank.Username,                // 学号
-			rank.Realname,                // 姓名
-			strconv.Itoa(rank.PassCount), // 通过题目数
-			timeBuilder.String(),         // 总耗时
+			common.FormatRank(&rank, overallRank),  // 排名
+			common.FormatRank(&rank, categoryRank), // 分类排名
+			common.CategoryName(rank.Category),     // 类别
+			username,                               // 学号
+			realname,                               // 姓名
+			strconv.Itoa(rank.PassCount),           // 通过题目数
+			timeBuilder.String(),                   // 总耗时
+			rank.GetAdjustment(),                   // 裁判调整
+		}
+		// 各题通过情况, 按题目顺序排列
+		for _, problem := range problems {
+			detail, ok := detailMap[rank.UserID][problem.ProblemID]
+			if !ok {
+				rowData = append(rowData, "")
+				continue
+			}
+			rowData = append(rowData, detail.GetCellValue())
 		}
 
-		for col, value := range rowData {
-			cell, err := excelize.CoordinatesToCellName(col+1, *currentRow)
+		if err := e.writeRow(f, sheets.main, rowData); err != nil {
+			return err
+		}
+		if sheets.split {
+			sheet, err := e.categorySheet(f, sheets, rank.Category, problems)
 			if err != nil {
-				return fmt.Errorf("get cell name failed: %w", err)
+				return err
 			}
-			if err := f.SetCellValue(sheetName, cell, value); err != nil {
-				return fmt.Errorf("set cell value failed: %w", err)
+			if err = e.writeRow(f, sheet, rowData); err != nil {
+				return err
 			}
 		}
-		*currentRow++
 	}
 	return nil
 }
 
+// writeRow 在工作表末尾写入一行数据
+func (e *StreamableXLSXRankingExporter) writeRow(f *excelize.File, sheet *rankingSheet, rowData []interface{}) error {
+	for col, value := range rowData {
+		cell, err := excelize.CoordinatesToCellName(col+1, sheet.row)
+		if err != nil {
+			return fmt.Errorf("get cell name failed: %w", err)
+		}
+		if err := f.SetCellValue(sheet.name, cell, value); err != nil {
+			return fmt.Errorf("set cell value failed: %w", err)
+		}
+	}
+	sheet.row++
+	return nil
+}
+
 // writeHeader 写入Excel表头
-func (e *StreamableXLSXRankingExporter) writeHeader(f *excelize.File, sheetName string) error {
+func (e *StreamableXLSXRankingExporter) writeHeader(f *excelize.File, sheetName string, problems []common.ProblemHeader) error {
 	headers := []string{
+		"排名",
+		"分类排名",
+		"类别",
 		"学号",
 		"姓名",
 		"通过题目数",
 		"总耗时",
+		"裁判调整",
+	}
+	for _, problem := range problems {
+		headers = append(headers, problem.Label)
 	}
 
 	// 设置表头样式
-	headerStyle, err := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{
-			Bold: true,
-		},
-		Fill: excelize.Fill{
-			Type:    "pattern",
-			Color:   []string{"#E0E0E0"},
-			Pattern: 1,
-		},
-		Alignment: &excelize.Alignment{
-			Horizontal: "center",
-			Vertical:   "center",
-		},
-	})
+	headerStyle, err := newHeaderStyle(f)
 	if err != nil {
-		return fmt.Errorf("create header style failed: %w", err)
+		return err
 	}
 
 	for col, header := range headers {
@@ -187,10 +285,14 @@ func (e *StreamableXLSXRankingExporter) writeHeader(f *excelize.File, sheetName
 
 	// 设置列宽
 	columnWidths := map[string]float64{
-		"A": 20, // 学号
-		"B": 15, // 姓名
-		"C": 15, // 通过题目数
-		"D": 20, // 总耗时
+		"A": 10, // 排名
+		"B": 10, // 分类排名
+		"C": 15, // 类别
+		"D": 20, // 学号
+		"E": 15, // 姓名
+		"F": 15, // 通过题目数
+		"G": 20, // 总耗时
+		"H": 25, // 裁判调整
 	}
 
 	for col, width := range columnWidths {
@@ -198,6 +300,38 @@ func (e *StreamableXLSXRankingExporter) writeHeader(f *excelize.File, sheetName
 			return fmt.Errorf("set column width failed: %w", err)
 		}
 	}
+	// 题目列宽
+	for i := range problems {
+		col, err := excelize.ColumnNumberToName(len(columnWidths) + i + 1)
+		if err != nil {
+			return fmt.Errorf("get column name failed: %w", err)
+		}
+		if err := f.SetColWidth(sheetName, col, col, 20); err != nil {
+			return fmt.Errorf("set column width failed: %w", err)
+		}
+	}
 
 	return nil
 }
+
+// newHeaderStyle 创建表头样式
+func newHeaderStyle(f *excelize.File) (int, error) {
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{
+			Bold: true,
+		},
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{"#E0E0E0"},
+			Pattern: 1,
+		},
+		Alignment: &excelize.Alignment{
+			Horizontal: "center",
+			Vertical:   "center",
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("create header style failed: %w", err)
+	}
+	return headerStyle, nil
+}
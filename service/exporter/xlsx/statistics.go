@@ -0,0 +1,71 @@
+package xlsx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/to404hanga/online_judge_controller/service/exporter/common"
+	"github.com/xuri/excelize/v2"
+)
+
+const statisticsSheetName = "题目统计"
+
+// writeStatistics 写入题目统计工作表, 每道启用的题目一行
+func (e *StreamableXLSXRankingExporter) writeStatistics(ctx context.Context, f *excelize.File, competitionID uint64, problems []common.ProblemHeader) error {
+	statistics, err := common.FetchProblemStatistics(e.db, ctx, competitionID)
+	if err != nil {
+		return err
+	}
+	if _, err = f.NewSheet(statisticsSheetName); err != nil {
+		return fmt.Errorf("create sheet failed: %w", err)
+	}
+
+	headers := []interface{}{
+		"题目", "标题", "通过人数", "尝试人数", "提交数", "首次通过时间",
+		"通过", "答案错误", "编译错误", "运行错误", "超时", "超内存", "输出超限",
+	}
+	sheet := &rankingSheet{name: statisticsSheetName, row: 1}
+	if err = e.writeRow(f, sheet, headers); err != nil {
+		return err
+	}
+	headerStyle, err := newHeaderStyle(f)
+	if err != nil {
+		return err
+	}
+	lastHeader, err := excelize.CoordinatesToCellName(len(headers), 1)
+	if err != nil {
+		return fmt.Errorf("get cell name failed: %w", err)
+	}
+	if err = f.SetCellStyle(statisticsSheetName, "A1", lastHeader, headerStyle); err != nil {
+		return fmt.Errorf("set header style failed: %w", err)
+	}
+	if err = f.SetColWidth(statisticsSheetName, "B", "B", 30); err != nil {
+		return fmt.Errorf("set column width failed: %w", err)
+	}
+	if err = f.SetColWidth(statisticsSheetName, "F", "F", 20); err != nil {
+		return fmt.Errorf("set column width failed: %w", err)
+	}
+
+	for _, problem := range problems {
+		stat := statistics[problem.ProblemID]
+		rowData := []interface{}{
+			problem.Label,
+			problem.ProblemTitle,
+			stat.Solved,
+			stat.Tried,
+			stat.Submissions,
+			stat.GetFirstSolveTime(),
+			stat.Accepted,
+			stat.WrongAnswer,
+			stat.CompileError,
+			stat.RuntimeError,
+			stat.TimeLimitExceeded,
+			stat.MemoryLimitExceeded,
+			stat.OutputLimitExceeded,
+		}
+		if err = e.writeRow(f, sheet, rowData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
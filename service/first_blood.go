@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/constants"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+// GetCompetitionFirstBloodList 获取比赛各题的首个通过者, 只包含已有人通过的启用题目, 封榜期间返回封榜时的结果
+func (s *RankingServiceImpl) GetCompetitionFirstBloodList(ctx context.Context, competitionID uint64) ([]model.FirstBlood, error) {
+	statistics, err := s.GetCompetitionProblemStatistics(ctx, competitionID, false)
+	if err != nil {
+		return nil, fmt.Errorf("GetCompetitionFirstBloodList failed: %w", err)
+	}
+
+	list := make([]model.FirstBlood, 0, len(statistics))
+	userIDs := make([]uint64, 0, len(statistics))
+	for _, stat := range statistics {
+		if stat.FirstSolveUserID == 0 {
+			continue
+		}
+		list = append(list, model.FirstBlood{
+			ProblemID: stat.ProblemID,
+			Label:     stat.Label,
+			UserID:    stat.FirstSolveUserID,
+			SolveTime: stat.FirstSolveTime,
+			Tries:     stat.FirstSolveTries,
+		})
+		userIDs = append(userIDs, stat.FirstSolveUserID)
+	}
+	if len(list) == 0 {
+		return list, nil
+	}
+
+	var users []ojmodel.CompetitionUser
+	err = s.db.WithContext(ctx).
+		Model(&ojmodel.CompetitionUser{}).
+		Select("user_id", "username", "realname").
+		Where("competition_id = ?", competitionID).
+		Where("user_id IN ?", userIDs).
+		Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("GetCompetitionFirstBloodList failed at select from competition_user: %w", err)
+	}
+	userMap := make(map[uint64]ojmodel.CompetitionUser, len(users))
+	for _, user := range users {
+		userMap[user.UserID] = user
+	}
+	for i := range list {
+		user := userMap[list[i].UserID]
+		list[i].Username, list[i].Realname = user.Username, user.Realname
+	}
+	return list, nil
+}
+
+// SubscribeFirstBlood 订阅首个通过者的变化, 订阅后先推送当前全部首个通过者, 之后推送新增、变更与撤销
+func (s *RankingServiceImpl) SubscribeFirstBlood(ctx context.Context, competitionID uint64) chan *model.FirstBlood {
+	ch := make(chan *model.FirstBlood, 1)
+	uc, ok := s.rdb.(redis.UniversalClient)
+	if !ok {
+		s.log.ErrorContext(ctx, "SubscribeFirstBlood: redis cmdable not universal client")
+		close(ch)
+		return ch
+	}
+	go func() {
+		pubsub := uc.Subscribe(ctx, fmt.Sprintf(constants.RedisPubSubRankingVersionEventKey, competitionID))
+		ticker := time.NewTicker(rankingEventSyncTicker)
+		defer pubsub.Close()
+		defer ticker.Stop()
+		defer close(ch)
+
+		sent := make(map[uint64]model.FirstBlood)
+		send := func(fb model.FirstBlood) bool {
+			select {
+			case ch <- &fb:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		push := func() bool {
+			list, err := s.GetCompetitionFirstBloodList(ctx, competitionID)
+			if err != nil {
+				s.log.ErrorContext(ctx, "SubscribeFirstBlood: get first blood list failed", logger.Error(err))
+				return true
+			}
+			current := make(map[uint64]struct{}, len(list))
+			for _, fb := range list {
+				current[fb.ProblemID] = struct{}{}
+				if prev, ok := sent[fb.ProblemID]; ok && prev == fb {
+					continue
+				}
+				sent[fb.ProblemID] = fb
+				if !send(fb) {
+					return false
+				}
+			}
+			// 重判或取消资格后无人通过的题目, 推送 UserID 为 0 的撤销事件
+			for problemID, prev := range sent {
+				if _, ok := current[problemID]; ok {
+					continue
+				}
+				delete(sent, problemID)
+				if !send(model.FirstBlood{ProblemID: problemID, Label: prev.Label}) {
+					return false
+				}
+			}
+			return true
+		}
+
+		if !push() {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				s.log.InfoContext(ctx, "SubscribeFirstBlood: client closed")
+				return
+			case _, ok := <-pubsub.Channel():
+				if !ok {
+					return
+				}
+				if !push() {
+					return
+				}
+			case <-ticker.C:
+				// 判题服务写入判题结果时不会发布通知, 定时同步以发现变化
+				if !push() {
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+	json "github.com/bytedance/sonic"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/event"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrJudgeReportNotFound = errors.New("judge report not found")
+
+// HandleJudgeReportMessage 消费判题服务投递的详细判题报告, 同一提交重判时覆盖之前的报告
+func (s *SubmissionServiceImpl) HandleJudgeReportMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	var report model.JudgeReport
+	if err := json.Unmarshal(msg.Value, &report); err != nil {
+		return fmt.Errorf("HandleJudgeReportMessage failed at unmarshal report: %w: %w", event.ErrInvalidMessage, err)
+	}
+	if report.SubmissionID == 0 {
+		return fmt.Errorf("HandleJudgeReportMessage failed: %w: submission id is empty", event.ErrInvalidMessage)
+	}
+
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "submission_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"compile_log", "testcases", "updated_at"}),
+	}).Create(&report).Error
+	if err != nil {
+		return fmt.Errorf("HandleJudgeReportMessage failed at upsert judge_report: %w", err)
+	}
+	s.log.DebugContext(ctx, "HandleJudgeReportMessage saved judge report",
+		logger.Uint64("submission_id", report.SubmissionID),
+		logger.Int("testcase_count", len(report.Testcases)))
+	return nil
+}
+
+// GetJudgeReport 获取提交的完整判题报告
+func (s *SubmissionServiceImpl) GetJudgeReport(ctx context.Context, submissionID uint64) (*model.JudgeReport, error) {
+	var report model.JudgeReport
+	err := s.db.WithContext(ctx).
+		Where("submission_id = ?", submissionID).
+		First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("GetJudgeReport failed: %w", ErrJudgeReportNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("GetJudgeReport failed at select from judge_report: %w", err)
+	}
+	return &report, nil
+}
+
+// UserGetJudgeReport 按比赛设置获取选手可见的判题报告, 只能查看自己在当前比赛中的提交
+func (s *SubmissionServiceImpl) UserGetJudgeReport(ctx context.Context, competitionID, userID, submissionID uint64) (*model.UserJudgeReport, error) {
+	var submission ojmodel.Submission
+	err := s.db.WithContext(ctx).
+		Select("id", "competition_id", "user_id").
+		Where("id = ?", submissionID).
+		First(&submission).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && (submission.UserID != userID || submission.CompetitionID != competitionID)) {
+		// 不暴露他人提交是否存在
+		return nil, fmt.Errorf("UserGetJudgeReport failed: %w", ErrSubmissionNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("UserGetJudgeReport failed at select from submission: %w", err)
+	}
+
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("UserGetJudgeReport failed: %w", err)
+	}
+	visibility := setting.JudgeReportVisibility
+	if visibility == model.JudgeReportVisibilityNone {
+		return nil, fmt.Errorf("UserGetJudgeReport failed: %w", ErrJudgeReportNotFound)
+	}
+	if visibility == model.JudgeReportVisibilitySampleUntil {
+		var competition ojmodel.Competition
+		err = s.db.WithContext(ctx).
+			Select("id", "end_time").
+			Where("id = ?", competitionID).
+			First(&competition).Error
+		if err != nil {
+			return nil, fmt.Errorf("UserGetJudgeReport failed at select from competition: %w", err)
+		}
+		visibility = model.JudgeReportVisibilitySample
+		if time.Now().After(competition.EndTime) {
+			visibility = model.JudgeReportVisibilityFull
+		}
+	}
+
+	report, err := s.GetJudgeReport(ctx, submissionID)
+	if err != nil {
+		return nil, fmt.Errorf("UserGetJudgeReport failed: %w", err)
+	}
+	userReport := &model.UserJudgeReport{
+		SubmissionID: report.SubmissionID,
+		CompileLog:   report.CompileLog,
+		Testcases:    make([]model.TestcaseReport, 0, len(report.Testcases)),
+		TotalCount:   len(report.Testcases),
+	}
+	for _, testcase := range report.Testcases {
+		if testcase.Result == int8(ojmodel.SubmissionResultAccepted) {
+			userReport.AcceptedCount++
+		}
+		if visibility == model.JudgeReportVisibilityFull || testcase.Sample {
+			userReport.Testcases = append(userReport.Testcases, testcase)
+		}
+	}
+	return userReport, nil
+}
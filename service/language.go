@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/gotools/retry"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	languageRegistryKey                = "language:registry"
+	competitionProblemSubmitSettingKey = "competition:%d:problem:%d:submit:setting"
+)
+
+var (
+	ErrLanguageNotAllowed = errors.New("language not allowed")
+	ErrCodeTooLarge       = errors.New("code too large")
+)
+
+type LanguageService interface {
+	// GetLanguageList 获取语言注册表, 包含已禁用的语言
+	GetLanguageList(ctx context.Context) ([]model.Language, error)
+	// SaveLanguage 新增或覆盖语言定义
+	SaveLanguage(ctx context.Context, param *model.SaveLanguageParam) error
+	// GetCompetitionProblemSubmitSetting 获取比赛题目的提交限制
+	GetCompetitionProblemSubmitSetting(ctx context.Context, competitionID, problemID uint64) (*model.CompetitionProblemSubmitSetting, error)
+	// UpdateCompetitionProblemSubmitSetting 更新比赛题目的提交限制
+	UpdateCompetitionProblemSubmitSetting(ctx context.Context, param *model.UpdateCompetitionProblemSubmitSettingParam) error
+	// GetSubmitLimit 获取比赛题目最终生效的提交限制, 为注册表、比赛设置与题目设置的交集
+	GetSubmitLimit(ctx context.Context, competitionID, problemID uint64) (*model.SubmitLimit, error)
+	// CheckSubmitLimit 检查提交的语言与代码大小是否满足比赛题目的提交限制
+	CheckSubmitLimit(ctx context.Context, competitionID, problemID uint64, language int8, codeSize int) error
+}
+
+type LanguageServiceImpl struct {
+	db  *gorm.DB
+	rdb redis.Cmdable
+	log loggerv2.Logger
+}
+
+var _ LanguageService = (*LanguageServiceImpl)(nil)
+
+func NewLanguageService(db *gorm.DB, rdb redis.Cmdable, log loggerv2.Logger) LanguageService {
+	return &LanguageServiceImpl{
+		db:  db,
+		rdb: rdb,
+		log: log,
+	}
+}
+
+// GetLanguageList 获取语言注册表, 优先读取 Redis 缓存, 注册表记录覆盖同编号的内置语言
+func (s *LanguageServiceImpl) GetLanguageList(ctx context.Context) ([]model.Language, error) {
+	languages, err := loadLanguageRegistry(ctx, s.db, s.rdb)
+	if err != nil {
+		return nil, fmt.Errorf("GetLanguageList failed: %w", err)
+	}
+	return languages, nil
+}
+
+// loadLanguageRegistry 获取语言注册表, 优先读取 Redis 缓存, 注册表记录覆盖同编号的内置语言
+func loadLanguageRegistry(ctx context.Context, db *gorm.DB, rdb redis.Cmdable) ([]model.Language, error) {
+	var languages []model.Language
+	languagesBytes, err := rdb.Get(ctx, languageRegistryKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(languagesBytes, &languages); err == nil {
+			return languages, nil
+		}
+	}
+
+	var records []model.Language
+	err = db.WithContext(ctx).Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("select from language failed: %w", err)
+	}
+
+	registry := make(map[int8]model.Language)
+	for _, lang := range model.DefaultLanguages() {
+		registry[lang.ID] = lang
+	}
+	for _, lang := range records {
+		registry[lang.ID] = lang
+	}
+	languages = make([]model.Language, 0, len(registry))
+	for _, lang := range registry {
+		languages = append(languages, lang)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		return languages[i].ID < languages[j].ID
+	})
+
+	if languagesBytes, err = json.Marshal(languages); err == nil {
+		rdb.Set(ctx, languageRegistryKey, languagesBytes, 8*time.Hour)
+	}
+	return languages, nil
+}
+
+// loadLanguage 获取语言定义, 注册表中不存在时按倍率为 1 处理
+func loadLanguage(ctx context.Context, db *gorm.DB, rdb redis.Cmdable, id int8) (*model.Language, error) {
+	languages, err := loadLanguageRegistry(ctx, db, rdb)
+	if err != nil {
+		return nil, err
+	}
+	for i := range languages {
+		if languages[i].ID == id {
+			return &languages[i], nil
+		}
+	}
+	return &model.Language{ID: id, TimeMultiplier: 1, MemoryMultiplier: 1}, nil
+}
+
+// SaveLanguage 新增或覆盖语言定义
+func (s *LanguageServiceImpl) SaveLanguage(ctx context.Context, param *model.SaveLanguageParam) error {
+	language := model.Language{
+		ID:               *param.ID,
+		Name:             param.Name,
+		Version:          param.Version,
+		FileExt:          param.FileExt,
+		TimeMultiplier:   param.TimeMultiplier,
+		MemoryMultiplier: param.MemoryMultiplier,
+		Enabled:          *param.Enabled,
+		UpdaterID:        param.Operator,
+	}
+	err := s.db.WithContext(ctx).Save(&language).Error
+	if err != nil {
+		return fmt.Errorf("SaveLanguage failed: %w", err)
+	}
+
+	s.deleteCacheAsync(ctx, "SaveLanguage", languageRegistryKey)
+	return nil
+}
+
+// GetCompetitionProblemSubmitSetting 获取比赛题目的提交限制, 无记录时返回不限制的设置
+func (s *LanguageServiceImpl) GetCompetitionProblemSubmitSetting(ctx context.Context, competitionID, problemID uint64) (*model.CompetitionProblemSubmitSetting, error) {
+	settingKey := fmt.Sprintf(competitionProblemSubmitSettingKey, competitionID, problemID)
+
+	var setting model.CompetitionProblemSubmitSetting
+	settingBytes, err := s.rdb.Get(ctx, settingKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(settingBytes, &setting); err == nil {
+			return &setting, nil
+		}
+	}
+
+	err = s.db.WithContext(ctx).
+		Where("competition_id = ?", competitionID).
+		Where("problem_id = ?", problemID).
+		First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		setting = model.CompetitionProblemSubmitSetting{
+			CompetitionID: competitionID,
+			ProblemID:     problemID,
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("GetCompetitionProblemSubmitSetting failed at select from competition_problem_submit_setting: %w", err)
+	}
+
+	if settingBytes, err = json.Marshal(setting); err == nil {
+		s.rdb.Set(ctx, settingKey, settingBytes, 8*time.Hour)
+	}
+	return &setting, nil
+}
+
+// UpdateCompetitionProblemSubmitSetting 更新比赛题目的提交限制
+func (s *LanguageServiceImpl) UpdateCompetitionProblemSubmitSetting(ctx context.Context, param *model.UpdateCompetitionProblemSubmitSettingParam) error {
+	updates := map[string]any{
+		"updater_id": param.Operator,
+	}
+	if param.Languages != nil {
+		languages, err := json.Marshal(*param.Languages)
+		if err != nil {
+			return fmt.Errorf("UpdateCompetitionProblemSubmitSetting failed at marshal languages: %w", err)
+		}
+		updates["languages"] = string(languages)
+	}
+	if param.MaxCodeSize != nil {
+		updates["max_code_size"] = *param.MaxCodeSize
+	}
+
+	// 检查是否有更新
+	if len(updates) == 1 {
+		return nil
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		setting := &model.CompetitionProblemSubmitSetting{
+			CompetitionID: param.CompetitionID,
+			ProblemID:     param.ProblemID,
+		}
+		err := tx.Where("competition_id = ?", param.CompetitionID).
+			Where("problem_id = ?", param.ProblemID).
+			FirstOrCreate(setting).Error
+		if err != nil {
+			return fmt.Errorf("init competition_problem_submit_setting failed: %w", err)
+		}
+		return tx.Model(&model.CompetitionProblemSubmitSetting{}).
+			Where("competition_id = ?", param.CompetitionID).
+			Where("problem_id = ?", param.ProblemID).
+			Updates(updates).Error
+	})
+	if err != nil {
+		return fmt.Errorf("UpdateCompetitionProblemSubmitSetting failed: %w", err)
+	}
+
+	s.deleteCacheAsync(ctx, "UpdateCompetitionProblemSubmitSetting", fmt.Sprintf(competitionProblemSubmitSettingKey, param.CompetitionID, param.ProblemID))
+	return nil
+}
+
+// GetSubmitLimit 获取比赛题目最终生效的提交限制
+// 允许的语言为注册表中启用的语言与比赛、题目白名单的交集, 代码大小上限取比赛与题目设置中较小的非零值
+func (s *LanguageServiceImpl) GetSubmitLimit(ctx context.Context, competitionID, problemID uint64) (*model.SubmitLimit, error) {
+	languages, err := s.GetLanguageList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetSubmitLimit failed: %w", err)
+	}
+	competitionSetting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("GetSubmitLimit failed: %w", err)
+	}
+	problemSetting, err := s.GetCompetitionProblemSubmitSetting(ctx, competitionID, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("GetSubmitLimit failed: %w", err)
+	}
+
+	limit := &model.SubmitLimit{
+		Languages:   make([]model.Language, 0, len(languages)),
+		MaxCodeSize: model.DefaultMaxCodeSize,
+	}
+	for _, lang := range languages {
+		if !lang.Enabled {
+			continue
+		}
+		if len(competitionSetting.Languages) > 0 && !slices.Contains(competitionSetting.Languages, lang.ID) {
+			continue
+		}
+		if len(problemSetting.Languages) > 0 && !slices.Contains(problemSetting.Languages, lang.ID) {
+			continue
+		}
+		limit.Languages = append(limit.Languages, lang)
+	}
+	if competitionSetting.MaxCodeSize > 0 {
+		limit.MaxCodeSize = competitionSetting.MaxCodeSize
+	}
+	if problemSetting.MaxCodeSize > 0 && problemSetting.MaxCodeSize < limit.MaxCodeSize {
+		limit.MaxCodeSize = problemSetting.MaxCodeSize
+	}
+	return limit, nil
+}
+
+// CheckSubmitLimit 检查提交的语言与代码大小是否满足比赛题目的提交限制
+func (s *LanguageServiceImpl) CheckSubmitLimit(ctx context.Context, competitionID, problemID uint64, language int8, codeSize int) error {
+	limit, err := s.GetSubmitLimit(ctx, competitionID, problemID)
+	if err != nil {
+		return fmt.Errorf("CheckSubmitLimit failed: %w", err)
+	}
+	if !limit.AllowLanguage(language) {
+		return fmt.Errorf("CheckSubmitLimit failed: %w", ErrLanguageNotAllowed)
+	}
+	if codeSize > limit.MaxCodeSize {
+		return fmt.Errorf("CheckSubmitLimit failed: %w, limit %d bytes", ErrCodeTooLarge, limit.MaxCodeSize)
+	}
+	return nil
+}
+
+// deleteCacheAsync 异步删除缓存, 失败时记录日志
+func (s *LanguageServiceImpl) deleteCacheAsync(ctx context.Context, method, key string) {
+	retryCtx := context.WithValue(context.Background(), loggerv2.FieldsKey, ctx.Value(loggerv2.FieldsKey))
+	retry.Do(retryCtx, func() error {
+		return s.rdb.Del(retryCtx, key).Err()
+	}, retry.WithAsync(true), retry.WithCallback(func(err error) {
+		if err != nil {
+			s.log.ErrorContext(retryCtx, method+" failed at delete cache", logger.Error(err), logger.String("key", key))
+		}
+	}))
+}
@@ -0,0 +1,19 @@
+-- KEYS[1]: 题目统计哈希
+-- ARGV: 题目 ID, 判题结果名称, 是否首次提交该题, 是否首次通过该题, 通过用时, 选手 ID, 通过时的提交次数
+if redis.call('HEXISTS', KEYS[1], 'ready') == 0 then
+    return 0
+end
+local p = ARGV[1] .. ':'
+redis.call('HINCRBY', KEYS[1], p .. 'submissions', 1)
+redis.call('HINCRBY', KEYS[1], p .. 'verdict:' .. ARGV[2], 1)
+if ARGV[3] == '1' then
+    redis.call('HINCRBY', KEYS[1], p .. 'tried', 1)
+end
+if ARGV[4] == '1' then
+    redis.call('HINCRBY', KEYS[1], p .. 'solved', 1)
+    local prev = redis.call('HGET', KEYS[1], p .. 'first_solve_time')
+    if not prev or tonumber(ARGV[5]) < tonumber(prev) then
+        redis.call('HSET', KEYS[1], p .. 'first_solve_time', ARGV[5], p .. 'first_solve_user_id', ARGV[6], p .. 'first_solve_tries', ARGV[7])
+    end
+end
+return 1
@@ -0,0 +1,48 @@
+-- 令牌桶限流, 所有桶都有令牌时才同时扣减, 否则不扣减
+-- KEYS[i]: 令牌桶 key
+-- ARGV[2i-1]: 桶容量
+-- ARGV[2i]: 补充一个令牌所需的时间 ( 单位: 毫秒 )
+-- 返回 { 需要等待的毫秒数, 触发限流的桶下标 }, 未触发限流时返回 { 0, 0 }
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local buckets = {}
+local wait, index = 0, 0
+for i, key in ipairs(KEYS) do
+    local capacity = tonumber(ARGV[2 * i - 1])
+    local interval = tonumber(ARGV[2 * i])
+    local state = redis.call('HMGET', key, 'tokens', 'ts')
+    local tokens = tonumber(state[1])
+    local ts = tonumber(state[2])
+    if tokens == nil or ts == nil then
+        tokens = capacity
+        ts = now
+    end
+
+    local refill = math.floor((now - ts) / interval)
+    if refill > 0 then
+        tokens = math.min(capacity, tokens + refill)
+        ts = ts + refill * interval
+    end
+    if tokens >= capacity then
+        ts = now
+    end
+
+    if tokens < 1 then
+        local w = ts + interval - now
+        if w > wait then
+            wait, index = w, i
+        end
+    end
+    buckets[i] = { tokens, ts, capacity * interval }
+end
+
+if wait > 0 then
+    return { wait, index }
+end
+
+for i, key in ipairs(KEYS) do
+    redis.call('HSET', key, 'tokens', buckets[i][1] - 1, 'ts', buckets[i][2])
+    redis.call('PEXPIRE', key, buckets[i][3])
+end
+return { 0, 0 }
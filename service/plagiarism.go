@@ -0,0 +1,612 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/plagiarism"
+	"github.com/to404hanga/online_judge_controller/pkg/textdiff"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCompetitionProblemNotFound = errors.New("problem is not in competition")
+	ErrPlagiarismTaskRunning      = errors.New("plagiarism task is already running for this problem")
+	ErrPlagiarismTaskNotFound     = errors.New("plagiarism task not found")
+	ErrPlagiarismTaskNotPending   = errors.New("plagiarism task is not pending")
+	ErrPlagiarismTaskTimedOut     = errors.New("plagiarism task timed out")
+	ErrPlagiarismPairNotFound     = errors.New("plagiarism pair not found")
+	ErrPlagiarismUserNotInPair    = errors.New("user is not in plagiarism pair")
+	ErrPlagiarismPairNotConfirmed = errors.New("only confirmed plagiarism pair can disqualify users")
+)
+
+const (
+	DefaultPlagiarismThreshold = 0.8       // 默认相似度阈值
+	plagiarismTaskTimeout      = time.Hour // 等待执行或执行中的任务超过该时间未更新时视为已中断
+)
+
+type PlagiarismService interface {
+	// CreatePlagiarismTask 创建比赛题目的查重任务并在后台执行, 返回任务 ID
+	CreatePlagiarismTask(ctx context.Context, param *model.CreatePlagiarismTaskParam) (uint64, error)
+	// RunPlagiarismTask 执行查重任务, 结果写入 plagiarism_pair
+	RunPlagiarismTask(ctx context.Context, taskID uint64) error
+	// DetectEndedCompetitions 对给定时间之后结束的比赛中尚未查重的启用题目执行查重, 供定时任务调用
+	DetectEndedCompetitions(ctx context.Context, since time.Time, threshold float64) error
+	// GetPlagiarismTaskList 获取比赛的查重任务
+	GetPlagiarismTaskList(ctx context.Context, competitionID uint64, problemID *uint64) ([]model.PlagiarismTask, error)
+	// GetPlagiarismPairList 分页获取查重任务中的可疑代码对, 按相似度降序
+	GetPlagiarismPairList(ctx context.Context, param *model.GetPlagiarismPairListParam) ([]model.PlagiarismPairItem, int, error)
+	// GetPlagiarismPairDiff 获取可疑代码对的左右对照, 代码超过对比上限时返回 textdiff.ErrTooLarge
+	GetPlagiarismPairDiff(ctx context.Context, pairID uint64) (*model.GetPlagiarismPairDiffResponse, error)
+	// ReviewPlagiarismPair 复核可疑代码对, 确认抄袭时可同时取消相关选手的比赛资格
+	ReviewPlagiarismPair(ctx context.Context, param *model.ReviewPlagiarismPairParam) error
+}
+
+type PlagiarismServiceImpl struct {
+	db         *gorm.DB
+	rankingSvc RankingService
+	log        loggerv2.Logger
+}
+
+var _ PlagiarismService = (*PlagiarismServiceImpl)(nil)
+
+func NewPlagiarismService(db *gorm.DB, rankingSvc RankingService, log loggerv2.Logger) PlagiarismService {
+	return &PlagiarismServiceImpl{
+		db:         db,
+		rankingSvc: rankingSvc,
+		log:        log,
+	}
+}
+
+// CreatePlagiarismTask 创建比赛题目的查重任务并在后台执行, 返回任务 ID
+func (s *PlagiarismServiceImpl) CreatePlagiarismTask(ctx context.Context, param *model.CreatePlagiarismTaskParam) (uint64, error) {
+	threshold := param.Threshold
+	if threshold == 0 {
+		threshold = DefaultPlagiarismThreshold
+	}
+	task, err := s.createTask(ctx, param.CompetitionID, param.ProblemID, threshold, param.Operator)
+	if err != nil {
+		return 0, fmt.Errorf("CreatePlagiarismTask failed: %w", err)
+	}
+
+	runCtx := context.WithValue(context.Background(), loggerv2.FieldsKey, ctx.Value(loggerv2.FieldsKey))
+	go func() {
+		if err := s.RunPlagiarismTask(runCtx, task.ID); err != nil {
+			s.log.ErrorContext(runCtx, "run plagiarism task failed", logger.Error(err), logger.Uint64("task_id", task.ID))
+		}
+	}()
+	return task.ID, nil
+}
+
+// createTask 创建等待执行的查重任务, 同一题目已有未完成的任务时返回 ErrPlagiarismTaskRunning
+func (s *PlagiarismServiceImpl) createTask(ctx context.Context, competitionID, problemID uint64, threshold float64, operator uint64) (*model.PlagiarismTask, error) {
+	task := &model.PlagiarismTask{
+		CompetitionID: competitionID,
+		ProblemID:     problemID,
+		Status:        model.PlagiarismTaskStatusPending,
+		Threshold:     threshold,
+		CreatorID:     operator,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定比赛题目行, 同一题目的任务创建串行执行, 避免并发检查后重复创建
+		var problems []ojmodel.CompetitionProblem
+		err := tx.Model(&ojmodel.CompetitionProblem{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("competition_id = ?", competitionID).
+			Where("problem_id = ?", problemID).
+			Select("id").
+			Find(&problems).Error
+		if err != nil {
+			return fmt.Errorf("select from competition_problem failed: %w", err)
+		}
+		if len(problems) == 0 {
+			return ErrCompetitionProblemNotFound
+		}
+
+		if err = failStalePlagiarismTasks(tx, competitionID, problemID); err != nil {
+			return err
+		}
+		var count int64
+		err = tx.Model(&model.PlagiarismTask{}).
+			Where("competition_id = ?", competitionID).
+			Where("problem_id = ?", problemID).
+			Where("status IN ?", []model.PlagiarismTaskStatus{model.PlagiarismTaskStatusPending, model.PlagiarismTaskStatusRunning}).
+			Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("select from plagiarism_task failed: %w", err)
+		}
+		if count != 0 {
+			return ErrPlagiarismTaskRunning
+		}
+
+		if err = tx.Create(task).Error; err != nil {
+			return fmt.Errorf("insert into plagiarism_task failed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// failStalePlagiarismTasks 将超过 plagiarismTaskTimeout 未更新的等待执行或执行中的任务标记为失败,
+// 这类任务通常因进程重启而中断, 标记后可重新创建, 定时任务也会重新查重; competitionID 为 0 时处理所有比赛
+func failStalePlagiarismTasks(tx *gorm.DB, competitionID, problemID uint64) error {
+	query := tx.Model(&model.PlagiarismTask{}).
+		Where("status IN ?", []model.PlagiarismTaskStatus{model.PlagiarismTaskStatusPending, model.PlagiarismTaskStatusRunning}).
+		Where("updated_at < ?", time.Now().Add(-plagiarismTaskTimeout))
+	if competitionID != 0 {
+		query = query.Where("competition_id = ?", competitionID).Where("problem_id = ?", problemID)
+	}
+	err := query.Updates(map[string]any{
+		"status":      model.PlagiarismTaskStatusFailed,
+		"message":     "任务超时未完成, 可能因服务重启而中断",
+		"finished_at": time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("update stale plagiarism_task failed: %w", err)
+	}
+	return nil
+}
+
+// plagiarismCandidate 参与查重的提交
+type plagiarismCandidate struct {
+	SubmissionID uint64
+	UserID       uint64
+	Family       string
+	CodeHash     string
+	Fingerprint  plagiarism.Fingerprint
+}
+
+// RunPlagiarismTask 执行查重任务, 结果写入 plagiarism_pair
+func (s *PlagiarismServiceImpl) RunPlagiarismTask(ctx context.Context, taskID uint64) error {
+	var task model.PlagiarismTask
+	err := s.db.WithContext(ctx).Where("id = ?", taskID).First(&task).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("RunPlagiarismTask failed: %w", ErrPlagiarismTaskNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("RunPlagiarismTask failed at select from plagiarism_task: %w", err)
+	}
+	ctx = loggerv2.ContextWithFields(ctx,
+		logger.Uint64("task_id", task.ID),
+		logger.Uint64("competition_id", task.CompetitionID),
+		logger.Uint64("problem_id", task.ProblemID))
+
+	res := s.db.WithContext(ctx).Model(&model.PlagiarismTask{}).
+		Where("id = ?", task.ID).
+		Where("status = ?", model.PlagiarismTaskStatusPending).
+		Update("status", model.PlagiarismTaskStatusRunning)
+	if res.Error != nil {
+		return fmt.Errorf("RunPlagiarismTask failed at update status: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("RunPlagiarismTask failed: %w", ErrPlagiarismTaskNotPending)
+	}
+
+	if err = s.runTask(ctx, &task); err != nil {
+		message := []rune(err.Error())
+		if len(message) > 255 {
+			message = message[:255]
+		}
+		if e := s.db.WithContext(ctx).Model(&model.PlagiarismTask{}).
+			Where("id = ?", task.ID).
+			Where("status = ?", model.PlagiarismTaskStatusRunning).
+			Updates(map[string]any{
+				"status":      model.PlagiarismTaskStatusFailed,
+				"message":     string(message),
+				"finished_at": time.Now(),
+			}).Error; e != nil {
+			s.log.ErrorContext(ctx, "update failed plagiarism task failed", logger.Error(e))
+		}
+		return fmt.Errorf("RunPlagiarismTask failed: %w", err)
+	}
+	return nil
+}
+
+// runTask 计算每位选手参与比较的提交的指纹, 两两比较同一语法族的代码, 并按相似关系划分簇
+func (s *PlagiarismServiceImpl) runTask(ctx context.Context, task *model.PlagiarismTask) error {
+	candidates, err := s.loadCandidates(ctx, task.CompetitionID, task.ProblemID)
+	if err != nil {
+		return err
+	}
+
+	var pairs []model.PlagiarismPair
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			a, b := &candidates[i], &candidates[j]
+			if a.Family != b.Family {
+				continue
+			}
+			// 规范化后完全相同的代码无需比较指纹
+			similarity := 1.0
+			if a.CodeHash != b.CodeHash {
+				similarity = plagiarism.Similarity(a.Fingerprint, b.Fingerprint)
+			}
+			if similarity < task.Threshold {
+				continue
+			}
+			pairs = append(pairs, model.PlagiarismPair{
+				TaskID:            task.ID,
+				CompetitionID:     task.CompetitionID,
+				ProblemID:         task.ProblemID,
+				LeftSubmissionID:  a.SubmissionID,
+				LeftUserID:        a.UserID,
+				RightSubmissionID: b.SubmissionID,
+				RightUserID:       b.UserID,
+				Similarity:        similarity,
+			})
+		}
+	}
+	clusterCount := assignPlagiarismClusters(pairs)
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(pairs) != 0 {
+			if err := tx.CreateInBatches(&pairs, 500).Error; err != nil {
+				return fmt.Errorf("insert into plagiarism_pair failed: %w", err)
+			}
+		}
+		// 任务已因超时被标记为失败时放弃本次结果, 避免与重新创建的任务重复
+		res := tx.Model(&model.PlagiarismTask{}).
+			Where("id = ?", task.ID).
+			Where("status = ?", model.PlagiarismTaskStatusRunning).
+			Updates(map[string]any{
+				"status":           model.PlagiarismTaskStatusFinished,
+				"submission_count": len(candidates),
+				"pair_count":       len(pairs),
+				"cluster_count":    clusterCount,
+				"finished_at":      time.Now(),
+			})
+		if res.Error != nil {
+			return fmt.Errorf("update plagiarism_task failed: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrPlagiarismTaskTimedOut
+		}
+		return nil
+	})
+}
+
+// loadCandidates 为每位选手选取一份提交参与查重: 优先最后一次通过的提交, 否则为最后一次代码未被清理的提交,
+// 被取消资格的选手同样参与比较, 以便发现其与他人的抄袭关系, 赛后补题提交不参与查重
+func (s *PlagiarismServiceImpl) loadCandidates(ctx context.Context, competitionID, problemID uint64) ([]plagiarismCandidate, error) {
+	// 先在 MySQL 中按选手选出提交 ID, 只加载选中提交的代码
+	var picks []struct {
+		AcceptedID uint64
+		LastID     uint64
+	}
+	err := s.db.WithContext(ctx).
+		Model(&ojmodel.Submission{}).
+		Select(fmt.Sprintf("IFNULL(MAX(CASE WHEN result = %d THEN id END), 0) AS accepted_id, MAX(id) AS last_id", ojmodel.SubmissionResultAccepted)).
+		Where("competition_id = ?", competitionID).
+		Where("problem_id = ?", problemID).
+		Where("status = ?", ojmodel.SubmissionStatusJudged).
+		Where("code != ?", model.CleanedSubmissionCode).
+		Where("id NOT IN (?)", upsolveSubmissionIDs(s.db.WithContext(ctx), competitionID)).
+		Group("user_id").
+		Scan(&picks).Error
+	if err != nil {
+		return nil, fmt.Errorf("select from submission failed: %w", err)
+	}
+	submissionIDs := make([]uint64, 0, len(picks))
+	for _, pick := range picks {
+		if pick.AcceptedID != 0 {
+			submissionIDs = append(submissionIDs, pick.AcceptedID)
+		} else {
+			submissionIDs = append(submissionIDs, pick.LastID)
+		}
+	}
+
+	var selected []ojmodel.Submission
+	if len(submissionIDs) != 0 {
+		err = s.db.WithContext(ctx).
+			Model(&ojmodel.Submission{}).
+			Select("id", "user_id", "language", "code").
+			Where("id IN ?", submissionIDs).
+			Order("user_id ASC").
+			Find(&selected).Error
+		if err != nil {
+			return nil, fmt.Errorf("select from submission failed: %w", err)
+		}
+	}
+
+	// 优先使用提交时保存的代码摘要, 早于摘要功能的提交现场计算
+	var hashes []model.SubmissionCodeHash
+	if len(submissionIDs) != 0 {
+		err = s.db.WithContext(ctx).
+			Where("submission_id IN ?", submissionIDs).
+			Find(&hashes).Error
+		if err != nil {
+			return nil, fmt.Errorf("select from submission_code_hash failed: %w", err)
+		}
+	}
+	hashMap := make(map[uint64]string, len(hashes))
+	for _, hash := range hashes {
+		hashMap[hash.SubmissionID] = hash.CodeHash
+	}
+
+	candidates := make([]plagiarismCandidate, 0, len(selected))
+	for _, sub := range selected {
+		syntax := plagiarismSyntaxOf(sub.Language)
+		codeHash, ok := hashMap[sub.ID]
+		if !ok {
+			codeHash = plagiarism.CodeHash(sub.Code, syntax)
+		}
+		candidates = append(candidates, plagiarismCandidate{
+			SubmissionID: sub.ID,
+			UserID:       sub.UserID,
+			Family:       syntax.Family,
+			CodeHash:     codeHash,
+			Fingerprint:  plagiarism.Winnow(plagiarism.Tokenize(sub.Code, syntax), plagiarism.DefaultK, plagiarism.DefaultWindow),
+		})
+	}
+	return candidates, nil
+}
+
+// plagiarismSyntaxOf 提交语言对应的分词语法, C 与 C++ 使用同一语法族互相比较
+func plagiarismSyntaxOf(language *ojmodel.SubmissionLanguage) *plagiarism.Syntax {
+	if language == nil {
+		return plagiarism.CSyntax
+	}
+	switch *language {
+	case ojmodel.SubmissionLanguagePython:
+		return plagiarism.PythonSyntax
+	case ojmodel.SubmissionLanguageJava:
+		return plagiarism.JavaSyntax
+	case ojmodel.SubmissionLanguageGo:
+		return plagiarism.GoSyntax
+	default:
+		return plagiarism.CSyntax
+	}
+}
+
+// assignPlagiarismClusters 以用户为节点、可疑代码对为边求连通分量, 为每个代码对填写簇编号, 返回簇数
+func assignPlagiarismClusters(pairs []model.PlagiarismPair) int {
+	parent := make(map[uint64]uint64)
+	var find func(x uint64) uint64
+	find = func(x uint64) uint64 {
+		if _, ok := parent[x]; !ok {
+			parent[x] = x
+		}
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	for _, pair := range pairs {
+		parent[find(pair.LeftUserID)] = find(pair.RightUserID)
+	}
+
+	// 按相似度降序编号, 使最可疑的簇编号最小
+	slices.SortStableFunc(pairs, func(a, b model.PlagiarismPair) int {
+		switch {
+		case a.Similarity > b.Similarity:
+			return -1
+		case a.Similarity < b.Similarity:
+			return 1
+		default:
+			return 0
+		}
+	})
+	clusters := make(map[uint64]int)
+	for i := range pairs {
+		root := find(pairs[i].LeftUserID)
+		id, ok := clusters[root]
+		if !ok {
+			id = len(clusters) + 1
+			clusters[root] = id
+		}
+		pairs[i].ClusterID = id
+	}
+	return len(clusters)
+}
+
+// DetectEndedCompetitions 对给定时间之后结束的比赛中尚未查重的启用题目执行查重, 供定时任务调用
+func (s *PlagiarismServiceImpl) DetectEndedCompetitions(ctx context.Context, since time.Time, threshold float64) error {
+	// 中断的任务标记为失败后, 下面会为其题目重新创建任务
+	if err := failStalePlagiarismTasks(s.db.WithContext(ctx), 0, 0); err != nil {
+		return fmt.Errorf("DetectEndedCompetitions failed: %w", err)
+	}
+
+	var competitions []ojmodel.Competition
+	err := s.db.WithContext(ctx).
+		Model(&ojmodel.Competition{}).
+		Select("id").
+		Where("end_time >= ?", since).
+		Where("end_time <= ?", time.Now()).
+		Find(&competitions).Error
+	if err != nil {
+		return fmt.Errorf("DetectEndedCompetitions failed at select from competition: %w", err)
+	}
+
+	for _, competition := range competitions {
+		items, err := loadCompetitionProblemItems(ctx, s.db, competition.ID)
+		if err != nil {
+			s.log.ErrorContext(ctx, "DetectEndedCompetitions: load competition problems failed",
+				logger.Error(err), logger.Uint64("competition_id", competition.ID))
+			continue
+		}
+		for _, item := range items {
+			if item.Status == nil || *item.Status != ojmodel.CompetitionProblemStatusEnabled {
+				continue
+			}
+			var count int64
+			err = s.db.WithContext(ctx).Model(&model.PlagiarismTask{}).
+				Where("competition_id = ?", competition.ID).
+				Where("problem_id = ?", item.ProblemID).
+				Where("status != ?", model.PlagiarismTaskStatusFailed).
+				Count(&count).Error
+			if err != nil {
+				s.log.ErrorContext(ctx, "DetectEndedCompetitions: select from plagiarism_task failed",
+					logger.Error(err), logger.Uint64("competition_id", competition.ID), logger.Uint64("problem_id", item.ProblemID))
+				continue
+			}
+			if count != 0 {
+				continue
+			}
+
+			// 单个题目失败不影响其他题目, 执行失败的原因记录在任务中
+			task, err := s.createTask(ctx, competition.ID, item.ProblemID, threshold, 0)
+			if err != nil {
+				s.log.ErrorContext(ctx, "DetectEndedCompetitions: create plagiarism task failed",
+					logger.Error(err), logger.Uint64("competition_id", competition.ID), logger.Uint64("problem_id", item.ProblemID))
+				continue
+			}
+			if err = s.RunPlagiarismTask(ctx, task.ID); err != nil {
+				s.log.ErrorContext(ctx, "DetectEndedCompetitions: run plagiarism task failed", logger.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// GetPlagiarismTaskList 获取比赛的查重任务
+func (s *PlagiarismServiceImpl) GetPlagiarismTaskList(ctx context.Context, competitionID uint64, problemID *uint64) ([]model.PlagiarismTask, error) {
+	var tasks []model.PlagiarismTask
+	query := s.db.WithContext(ctx).Where("competition_id = ?", competitionID)
+	if problemID != nil {
+		query = query.Where("problem_id = ?", *problemID)
+	}
+	if err := query.Order("id DESC").Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("GetPlagiarismTaskList failed: %w", err)
+	}
+	return tasks, nil
+}
+
+// plagiarismPairQuery 可疑代码对与双方用户信息的联表查询
+func (s *PlagiarismServiceImpl) plagiarismPairQuery(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).
+		Table("plagiarism_pair p").
+		Select("p.*, " +
+			"IFNULL(lu.username, '') AS left_username, IFNULL(lu.realname, '') AS left_realname, " +
+			"IFNULL(ru.username, '') AS right_username, IFNULL(ru.realname, '') AS right_realname").
+		Joins("LEFT JOIN user lu ON lu.id = p.left_user_id").
+		Joins("LEFT JOIN user ru ON ru.id = p.right_user_id")
+}
+
+// GetPlagiarismPairList 分页获取查重任务中的可疑代码对, 按相似度降序
+func (s *PlagiarismServiceImpl) GetPlagiarismPairList(ctx context.Context, param *model.GetPlagiarismPairListParam) ([]model.PlagiarismPairItem, int, error) {
+	query := s.db.WithContext(ctx).Model(&model.PlagiarismPair{}).Where("task_id = ?", param.TaskID)
+	if param.ClusterID != nil {
+		query = query.Where("cluster_id = ?", *param.ClusterID)
+	}
+	if param.Status != nil {
+		query = query.Where("status = ?", *param.Status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("GetPlagiarismPairList failed at count: %w", err)
+	}
+
+	var list []model.PlagiarismPairItem
+	pairQuery := s.plagiarismPairQuery(ctx).Where("p.task_id = ?", param.TaskID)
+	if param.ClusterID != nil {
+		pairQuery = pairQuery.Where("p.cluster_id = ?", *param.ClusterID)
+	}
+	if param.Status != nil {
+		pairQuery = pairQuery.Where("p.status = ?", *param.Status)
+	}
+	err := pairQuery.Order("p.similarity DESC, p.id ASC").
+		Offset((param.Page - 1) * param.PageSize).
+		Limit(param.PageSize).
+		Scan(&list).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetPlagiarismPairList failed at select: %w", err)
+	}
+	return list, int(total), nil
+}
+
+// GetPlagiarismPairDiff 获取可疑代码对的左右对照, 代码超过对比上限时返回 textdiff.ErrTooLarge
+func (s *PlagiarismServiceImpl) GetPlagiarismPairDiff(ctx context.Context, pairID uint64) (*model.GetPlagiarismPairDiffResponse, error) {
+	var items []model.PlagiarismPairItem
+	err := s.plagiarismPairQuery(ctx).Where("p.id = ?", pairID).Limit(1).Scan(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("GetPlagiarismPairDiff failed at select from plagiarism_pair: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("GetPlagiarismPairDiff failed: %w", ErrPlagiarismPairNotFound)
+	}
+	pair := items[0]
+
+	var submissions []ojmodel.Submission
+	err = s.db.WithContext(ctx).
+		Model(&ojmodel.Submission{}).
+		Select("id", "code").
+		Where("id IN ?", []uint64{pair.LeftSubmissionID, pair.RightSubmissionID}).
+		Find(&submissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("GetPlagiarismPairDiff failed at select from submission: %w", err)
+	}
+	codes := make(map[uint64]string, len(submissions))
+	for _, sub := range submissions {
+		codes[sub.ID] = sub.Code
+	}
+
+	rows, err := textdiff.Compare(textdiff.SplitLines(codes[pair.LeftSubmissionID]), textdiff.SplitLines(codes[pair.RightSubmissionID]))
+	if err != nil {
+		return nil, fmt.Errorf("GetPlagiarismPairDiff failed: %w", err)
+	}
+	return &model.GetPlagiarismPairDiffResponse{
+		Pair: pair,
+		Rows: rows,
+	}, nil
+}
+
+// ReviewPlagiarismPair 复核可疑代码对, 确认抄袭时可同时取消相关选手的比赛资格
+func (s *PlagiarismServiceImpl) ReviewPlagiarismPair(ctx context.Context, param *model.ReviewPlagiarismPairParam) error {
+	var pair model.PlagiarismPair
+	err := s.db.WithContext(ctx).Where("id = ?", param.PairID).First(&pair).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("ReviewPlagiarismPair failed: %w", ErrPlagiarismPairNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("ReviewPlagiarismPair failed at select from plagiarism_pair: %w", err)
+	}
+	if len(param.DisqualifyUserIDs) != 0 && param.Status != model.PlagiarismPairStatusConfirmed {
+		return fmt.Errorf("ReviewPlagiarismPair failed: %w", ErrPlagiarismPairNotConfirmed)
+	}
+	for _, userID := range param.DisqualifyUserIDs {
+		if userID != pair.LeftUserID && userID != pair.RightUserID {
+			return fmt.Errorf("ReviewPlagiarismPair failed: %w: user %d", ErrPlagiarismUserNotInPair, userID)
+		}
+	}
+
+	// 先取消资格再更新审核状态, 取消资格失败时审核状态保持不变, 可重新提交审核;
+	// 取消资格可重复执行, 更新状态失败后重试不会产生重复记录
+	reason := param.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("代码抄袭 ( 查重记录 %d, 相似度 %.0f%% )", pair.ID, pair.Similarity*100)
+	}
+	for _, userID := range param.DisqualifyUserIDs {
+		err = s.rankingSvc.DisqualifyCompetitionUser(ctx, &model.DisqualifyCompetitionUserParam{
+			CommonParam:   model.CommonParam{Operator: param.Operator},
+			CompetitionID: pair.CompetitionID,
+			UserID:        userID,
+			Reason:        reason,
+		})
+		// 已因其他代码对被取消资格的选手无需重复处理
+		if err != nil && !errors.Is(err, ErrUserAlreadyDisqualified) {
+			return fmt.Errorf("ReviewPlagiarismPair failed: %w", err)
+		}
+	}
+
+	err = s.db.WithContext(ctx).Model(&model.PlagiarismPair{}).
+		Where("id = ?", pair.ID).
+		Updates(map[string]any{
+			"status":      param.Status,
+			"reviewer_id": param.Operator,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("ReviewPlagiarismPair failed at update plagiarism_pair: %w", err)
+	}
+	return nil
+}
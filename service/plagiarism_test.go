@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/to404hanga/online_judge_controller/model"
+)
+
+func TestAssignPlagiarismClusters(t *testing.T) {
+	pair := func(left, right uint64, similarity float64) model.PlagiarismPair {
+		return model.PlagiarismPair{LeftUserID: left, RightUserID: right, Similarity: similarity}
+	}
+	tests := []struct {
+		name         string
+		pairs        []model.PlagiarismPair
+		wantCount    int
+		wantClusters map[[2]uint64]int // 代码对两端用户 -> 簇编号
+	}{
+		{
+			name:         "没有代码对",
+			wantCount:    0,
+			wantClusters: map[[2]uint64]int{},
+		},
+		{
+			name:         "传递相连的用户归为同一簇",
+			pairs:        []model.PlagiarismPair{pair(1, 2, 0.9), pair(2, 3, 0.8)},
+			wantCount:    1,
+			wantClusters: map[[2]uint64]int{{1, 2}: 1, {2, 3}: 1},
+		},
+		{
+			name:         "最可疑的簇编号最小",
+			pairs:        []model.PlagiarismPair{pair(1, 2, 0.7), pair(3, 4, 0.95), pair(5, 1, 0.6)},
+			wantCount:    2,
+			wantClusters: map[[2]uint64]int{{3, 4}: 1, {1, 2}: 2, {5, 1}: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := assignPlagiarismClusters(tt.pairs); got != tt.wantCount {
+				t.Errorf("assignPlagiarismClusters() = %d, want %d", got, tt.wantCount)
+			}
+			for _, p := range tt.pairs {
+				want := tt.wantClusters[[2]uint64{p.LeftUserID, p.RightUserID}]
+				if p.ClusterID != want {
+					t.Errorf("pair (%d, %d) ClusterID = %d, want %d", p.LeftUserID, p.RightUserID, p.ClusterID, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/privacy"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	practiceRankingKey = "practice:ranking"
+
+	practiceRankingExpire = time.Minute // 练习排行榜缓存时间, 练习排行榜不要求实时
+)
+
+var ErrPracticeProblemNotFound = errors.New("problem is not available for practice")
+
+type PracticeService interface {
+	// GetPracticeProblemList 获取已发布且非比赛时可见的题目列表, 并标记用户是否已在练习中通过
+	GetPracticeProblemList(ctx context.Context, userID uint64, param *model.GetPracticeProblemListParam) ([]model.PracticeProblemItem, int, error)
+	// GetPracticeProblem 获取练习题目详情, 题目未发布或不可见时返回 ErrPracticeProblemNotFound
+	GetPracticeProblem(ctx context.Context, problemID uint64) (*ojmodel.Problem, error)
+	// GetPracticeRankingList 获取按练习通过题目数排列的排行榜
+	GetPracticeRankingList(ctx context.Context, page, pageSize int) ([]model.PracticeRanking, int, error)
+}
+
+type PracticeServiceImpl struct {
+	db  *gorm.DB
+	rdb redis.Cmdable
+	log loggerv2.Logger
+}
+
+var _ PracticeService = (*PracticeServiceImpl)(nil)
+
+func NewPracticeService(db *gorm.DB, rdb redis.Cmdable, log loggerv2.Logger) PracticeService {
+	return &PracticeServiceImpl{
+		db:  db,
+		rdb: rdb,
+		log: log,
+	}
+}
+
+// practiceProblemQuery 可用于练习的题目: 已发布且非比赛时可见
+func (s *PracticeServiceImpl) practiceProblemQuery(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Model(&ojmodel.Problem{}).
+		Where("status = ?", ojmodel.ProblemStatusPublished).
+		Where("visible = ?", ojmodel.ProblemVisibleTrue)
+}
+
+// GetPracticeProblemList 获取已发布且非比赛时可见的题目列表, 并标记用户是否已在练习中通过
+func (s *PracticeServiceImpl) GetPracticeProblemList(ctx context.Context, userID uint64, param *model.GetPracticeProblemListParam) ([]model.PracticeProblemItem, int, error) {
+	query := s.practiceProblemQuery(ctx)
+	if param.Title != "" {
+		query = query.Where("title LIKE ?", "%"+param.Title+"%")
+	}
+
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetPracticeProblemList failed at count: %w", err)
+	}
+
+	var problems []ojmodel.Problem
+	err = query.Select("id", "title", "time_limit", "memory_limit").
+		Order("id ASC").
+		Offset((param.Page - 1) * param.PageSize).
+		Limit(param.PageSize).
+		Find(&problems).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetPracticeProblemList failed at select from problem: %w", err)
+	}
+	if len(problems) == 0 {
+		return []model.PracticeProblemItem{}, int(total), nil
+	}
+
+	problemIDs := make([]uint64, 0, len(problems))
+	for _, problem := range problems {
+		problemIDs = append(problemIDs, problem.ID)
+	}
+	var acceptedIDs []uint64
+	err = s.db.WithContext(ctx).Model(&ojmodel.Submission{}).
+		Where("competition_id = ?", model.PracticeCompetitionID).
+		Where("user_id = ?", userID).
+		Where("problem_id IN ?", problemIDs).
+		Where("result = ?", ojmodel.SubmissionResultAccepted).
+		Distinct().
+		Pluck("problem_id", &acceptedIDs).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetPracticeProblemList failed at select accepted problems: %w", err)
+	}
+	accepted := make(map[uint64]struct{}, len(acceptedIDs))
+	for _, problemID := range acceptedIDs {
+		accepted[problemID] = struct{}{}
+	}
+
+	items := make([]model.PracticeProblemItem, 0, len(problems))
+	for _, problem := range problems {
+		_, ok := accepted[problem.ID]
+		items = append(items, model.PracticeProblemItem{
+			ID:          problem.ID,
+			Title:       problem.Title,
+			TimeLimit:   problem.TimeLimit,
+			MemoryLimit: problem.MemoryLimit,
+			Accepted:    ok,
+		})
+	}
+	return items, int(total), nil
+}
+
+// GetPracticeProblem 获取练习题目详情, 题目未发布或不可见时返回 ErrPracticeProblemNotFound
+func (s *PracticeServiceImpl) GetPracticeProblem(ctx context.Context, problemID uint64) (*ojmodel.Problem, error) {
+	var problem ojmodel.Problem
+	err := s.practiceProblemQuery(ctx).
+		Where("id = ?", problemID).
+		First(&problem).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("GetPracticeProblem failed: %w", ErrPracticeProblemNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetPracticeProblem failed at select from problem: %w", err)
+	}
+	return &problem, nil
+}
+
+// GetPracticeRankingList 获取按练习通过题目数排列的排行榜, 通过题目数相同时各题首次通过时间中最晚的一个较早的排在前面.
+// 完整排行榜缓存一分钟, 分页在缓存上进行, 缓存中保留原始学号与姓名, 返回前按练习设置的展示模式处理
+func (s *PracticeServiceImpl) GetPracticeRankingList(ctx context.Context, page, pageSize int) ([]model.PracticeRanking, int, error) {
+	var rankings []model.PracticeRanking
+	rankingBytes, err := s.rdb.Get(ctx, practiceRankingKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(rankingBytes, &rankings); err != nil {
+			s.log.WarnContext(ctx, "GetPracticeRankingList: failed to unmarshal practice ranking from redis", logger.Error(err))
+			rankings = nil
+		}
+	} else if err != redis.Nil {
+		s.log.WarnContext(ctx, "GetPracticeRankingList: failed to get practice ranking from redis", logger.Error(err))
+	}
+
+	if rankings == nil {
+		if rankings, err = s.loadPracticeRanking(ctx); err != nil {
+			return nil, 0, err
+		}
+		if rankingBytes, err = json.Marshal(rankings); err == nil {
+			if err = s.rdb.Set(ctx, practiceRankingKey, rankingBytes, practiceRankingExpire).Err(); err != nil {
+				s.log.WarnContext(ctx, "GetPracticeRankingList: failed to set practice ranking to redis", logger.Error(err))
+			}
+		}
+	}
+
+	total := len(rankings)
+	start := min((page-1)*pageSize, total)
+	stop := min(start+pageSize, total)
+	list := rankings[start:stop]
+	display, err := s.getPracticeRankingDisplay(ctx, list)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range list {
+		display.Apply(&list[i].UserID, &list[i].Username, &list[i].Realname)
+	}
+	return list, total, nil
+}
+
+// getPracticeRankingDisplay 获取练习排行榜的选手信息展示方式, 昵称模式下只加载当前页用户的昵称
+func (s *PracticeServiceImpl) getPracticeRankingDisplay(ctx context.Context, list []model.PracticeRanking) (*model.RankingDisplay, error) {
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, model.PracticeCompetitionID)
+	if err != nil {
+		return nil, fmt.Errorf("getPracticeRankingDisplay failed: %w", err)
+	}
+	display := &model.RankingDisplay{Mode: setting.DisplayMode}
+	if display.Mode == privacy.DisplayModeNickname {
+		userIDs := make([]uint64, 0, len(list))
+		for _, ranking := range list {
+			userIDs = append(userIDs, ranking.UserID)
+		}
+		if display.Nicknames, err = loadUserNicknames(ctx, s.db, userIDs); err != nil {
+			return nil, fmt.Errorf("getPracticeRankingDisplay failed: %w", err)
+		}
+	}
+	if display.Mode == privacy.DisplayModeAnonymous {
+		if display.AnonymousKey, err = loadCompetitionAnonymousKey(ctx, s.rdb, model.PracticeCompetitionID); err != nil {
+			return nil, fmt.Errorf("getPracticeRankingDisplay failed: %w", err)
+		}
+	}
+	return display, nil
+}
+
+// loadPracticeRanking 从 MySQL 统计每位用户在练习中通过的题目数并计算排名,
+// 同一题目只取首次通过的时间, 之后重复通过不影响排名
+func (s *PracticeServiceImpl) loadPracticeRanking(ctx context.Context) ([]model.PracticeRanking, error) {
+	firstAccepted := s.db.WithContext(ctx).
+		Model(&ojmodel.Submission{}).
+		Select("user_id, problem_id, MIN(created_at) AS first_accepted_at").
+		Where("competition_id = ?", model.PracticeCompetitionID).
+		Where("result = ?", ojmodel.SubmissionResultAccepted).
+		Group("user_id, problem_id")
+
+	rankings := make([]model.PracticeRanking, 0)
+	err := s.db.WithContext(ctx).
+		Table("(?) AS a", firstAccepted).
+		Joins("LEFT JOIN user u ON u.id = a.user_id").
+		Select("a.user_id, IFNULL(u.username, '') AS username, IFNULL(u.realname, '') AS realname, " +
+			"COUNT(*) AS solved_count, MAX(a.first_accepted_at) AS last_accepted_at").
+		Group("a.user_id, u.username, u.realname").
+		Order("solved_count DESC, last_accepted_at ASC, a.user_id ASC").
+		Scan(&rankings).Error
+	if err != nil {
+		return nil, fmt.Errorf("loadPracticeRanking failed at select from submission: %w", err)
+	}
+
+	for i := range rankings {
+		if i > 0 && rankings[i].SolvedCount == rankings[i-1].SolvedCount {
+			rankings[i].Rank = rankings[i-1].Rank
+		} else {
+			rankings[i].Rank = i + 1
+		}
+	}
+	return rankings, nil
+}
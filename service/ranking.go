@@ -2,15 +2,21 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/IBM/sarama"
 	json "github.com/bytedance/sonic"
 	"github.com/redis/go-redis/v9"
 	ojmodel "github.com/to404hanga/online_judge_common/model"
 	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/service/exporter"
 	"github.com/to404hanga/online_judge_controller/service/exporter/factory"
 	"github.com/to404hanga/pkg404/gotools/transform"
 	"github.com/to404hanga/pkg404/logger"
@@ -20,15 +26,57 @@ import (
 
 type RankingService interface {
 	// GetCompetitionRankingList 获取比赛排行榜
-	GetCompetitionRankingList(ctx context.Context, competitionID uint64, page, pageSize int) ([]model.Ranking, int, error)
-	// UpdateUserScore 更新用户分数
-	UpdateUserScore(ctx context.Context, competitionID, problemID, userID uint64, isAccepted bool, submissionTime time.Time, startTime time.Time) error
+	GetCompetitionRankingList(ctx context.Context, competitionID uint64, page, pageSize int, category *string) ([]model.Ranking, int, error)
+	// UpdateUserScore 根据判题结果更新用户分数与题目统计
+	UpdateUserScore(ctx context.Context, competitionID, problemID, userID uint64, result ojmodel.SubmissionResult, submissionTime time.Time, startTime time.Time) error
 	// InitCompetitionRanking 初始化比赛排行榜
 	InitCompetitionRanking(ctx context.Context, competitionID uint64) error
-	// GetFastestSolverList 获取最快通过每道题的用户
+	// GetFastestSolverList 获取最快通过每道题的用户, 已由 GetCompetitionFirstBloodList 代替
 	GetFastestSolverList(ctx context.Context, competitionID uint64, problemIDs []uint64) []model.FastestSolver
 	// Export 导出数据
-	Export(ctx context.Context, competitionID uint64, exporter factory.ExporterType) (string, error)
+	Export(ctx context.Context, competitionID uint64, exporterType factory.ExporterType, opts *exporter.Options) (string, error)
+	// FreezeCompetitionRanking 封榜, 将当前排行榜快照为选手可见的封榜排行榜
+	FreezeCompetitionRanking(ctx context.Context, competitionID uint64) error
+	// FinalizeCompetitionRanking 定榜, 将实时排行榜写回 MySQL 并解除封榜
+	FinalizeCompetitionRanking(ctx context.Context, competitionID uint64) error
+	// DisqualifyCompetitionUser 取消选手比赛资格, 将其移出排行榜并重新计算各题最快通过者
+	DisqualifyCompetitionUser(ctx context.Context, param *model.DisqualifyCompetitionUserParam) error
+	// ReinstateCompetitionUser 恢复选手比赛资格, 重新计算排行榜
+	ReinstateCompetitionUser(ctx context.Context, param *model.ReinstateCompetitionUserParam) error
+	// GetCompetitionDisqualificationList 获取比赛取消资格记录, active 为 true 时只返回仍生效的记录
+	GetCompetitionDisqualificationList(ctx context.Context, competitionID uint64, active bool) ([]model.CompetitionDisqualification, error)
+	// OverrideSubmissionResult 人工改判提交结果并重建排行榜
+	OverrideSubmissionResult(ctx context.Context, param *model.OverrideSubmissionResultParam) error
+	// AddCompetitionTimeAdjustment 为选手增加或减少罚时, 立即反映到排行榜
+	AddCompetitionTimeAdjustment(ctx context.Context, param *model.AddCompetitionTimeAdjustmentParam) error
+	// GetCompetitionAdjustmentList 获取比赛的人工改判与罚时调整记录, userID 不为空时只返回该选手的记录
+	GetCompetitionAdjustmentList(ctx context.Context, competitionID uint64, userID *uint64) ([]model.SubmissionVerdictOverride, []model.CompetitionTimeAdjustment, error)
+	// GetRankingVersion 获取排行榜当前版本
+	GetRankingVersion(ctx context.Context, competitionID uint64) (int64, error)
+	// GetCompetitionRankingDiff 获取给定版本之后排行榜发生变化的行
+	GetCompetitionRankingDiff(ctx context.Context, competitionID uint64, version int64) (*model.RankingDiff, error)
+	// SubscribeRankingDiff 订阅排行榜变化, 从客户端给定的版本开始推送差异
+	SubscribeRankingDiff(ctx context.Context, competitionID uint64, version int64) chan *model.RankingDiff
+	// GetRankingDisplay 获取比赛的选手信息展示方式, public 为 true 时返回公开排行榜的展示方式
+	GetRankingDisplay(ctx context.Context, competitionID uint64, public bool) (*model.RankingDisplay, error)
+	// GetPublicCompetitionRankingList 获取公开排行榜, 需要比赛开启公开排行榜, 封榜期间返回封榜快照
+	GetPublicCompetitionRankingList(ctx context.Context, competitionID uint64, page, pageSize int, category *string) (*model.GetCompetitionRankingListResponse, error)
+	// GetCompetitionRankingHistory 回放提交记录, 获取比赛开始后第 minute 分钟结束时的排行榜, 返回排行榜、总数与实际查询的分钟数
+	GetCompetitionRankingHistory(ctx context.Context, competitionID uint64, minute, page, pageSize int, category *string) ([]model.Ranking, int, int, error)
+	// GetCompetitionUpsolveRankingList 回放全部提交, 获取包含赛后补题的排行榜, 补题通过计入通过数但不计罚时
+	GetCompetitionUpsolveRankingList(ctx context.Context, competitionID uint64, page, pageSize int, category *string) ([]model.Ranking, int, error)
+	// GetCompetitionRankTrend 获取选手排名随时间的变化, userID 为空时返回最终排名前 top 名选手
+	GetCompetitionRankTrend(ctx context.Context, competitionID uint64, userID *uint64, top, interval int) (*model.GetCompetitionRankTrendResponse, error)
+	// GetCompetitionProblemStatistics 获取比赛各题统计, live 为 false 时只返回启用的题目, 封榜期间返回封榜时的统计
+	GetCompetitionProblemStatistics(ctx context.Context, competitionID uint64, live bool) ([]model.ProblemStatistics, error)
+	// RebuildCompetitionProblemStatistics 丢弃增量统计状态, 从 MySQL 重新计算比赛各题统计
+	RebuildCompetitionProblemStatistics(ctx context.Context, competitionID uint64) error
+	// GetCompetitionFirstBloodList 获取比赛各题的首个通过者, 只包含已有人通过的启用题目, 封榜期间返回封榜时的结果
+	GetCompetitionFirstBloodList(ctx context.Context, competitionID uint64) ([]model.FirstBlood, error)
+	// SubscribeFirstBlood 订阅首个通过者的变化, 订阅后先推送当前全部首个通过者, 之后推送新增、变更与撤销
+	SubscribeFirstBlood(ctx context.Context, competitionID uint64) chan *model.FirstBlood
+	// HandleJudgeReportMessage 消费判题报告, 修正判题服务写入实时排行榜的、不应计入排行榜的结果
+	HandleJudgeReportMessage(ctx context.Context, msg *sarama.ConsumerMessage) error
 }
 
 // RankingServiceImpl 排行榜服务实现, 实时排行榜强依赖 Redis, 暂无 Redis 重建数据功能
@@ -38,6 +86,7 @@ type RankingServiceImpl struct {
 	log             loggerv2.Logger
 	exporterFactory *factory.ExporterFactory
 	exportDir       string
+	pendingSync     sync.Map // 已安排版本同步的比赛, 同一比赛同一时间只安排一次同步
 }
 
 var _ RankingService = (*RankingServiceImpl)(nil)
@@ -56,43 +105,107 @@ const (
 	RankingKey              = "ranking:competition:%d"
 	UserDetailKey           = "ranking:user:%s:competition:%d"
 	ProblemFastestSolverKey = "ranking:problem:%d:competition:%d"
-	PenaltyTime             = 20 * 60 * 1000 // 20分钟
+	FrozenRankingKey        = "ranking:competition:%d:frozen"
+	FrozenUserDetailKey     = "ranking:user:%s:competition:%d:frozen"
 	ScoreMultiplier         = 1000000000000
 )
 
 // UserRankingData 用户排行榜数据
 type UserRankingData struct {
-	UserID        uint64                   `json:"user_id" gorm:"-"`
-	Username      string                   `json:"username" gorm:"column:username"`
-	Realname      string                   `json:"realname" gorm:"column:realname"`
-	TotalAccepted int                      `json:"total_accepted" gorm:"-"`
-	TotalTimeUsed int64                    `json:"total_time_used" gorm:"-"`
-	Problems      map[uint64]model.Problem `json:"problems" gorm:"-"`
+	UserID          uint64                   `json:"user_id" gorm:"-"`
+	Username        string                   `json:"username" gorm:"column:username"`
+	Realname        string                   `json:"realname" gorm:"column:realname"`
+	TotalAccepted   int                      `json:"total_accepted" gorm:"-"`
+	TotalTimeUsed   int64                    `json:"total_time_used" gorm:"-"`
+	UpsolveAccepted int                      `json:"upsolve_accepted,omitempty" gorm:"-"` // 赛后补题通过数, 仅在回放包含补题的排行榜时计算
+	Problems        map[uint64]model.Problem `json:"problems" gorm:"-"`
 }
 
-// GetCompetitionRankingList 获取比赛排行榜
-func (s *RankingServiceImpl) GetCompetitionRankingList(ctx context.Context, competitionID uint64, page, pageSize int) ([]model.Ranking, int, error) {
+// GetCompetitionRankingList 获取比赛排行榜, category 不为空时只返回该分类的选手
+func (s *RankingServiceImpl) GetCompetitionRankingList(ctx context.Context, competitionID uint64, page, pageSize int, category *string) ([]model.Ranking, int, error) {
 	rankingKey := fmt.Sprintf(RankingKey, competitionID)
+	userDetailKeyFormat := UserDetailKey
 
-	start := int64((page - 1) * pageSize)
-	stop := start + int64(pageSize) - 1
+	// 封榜期间返回封榜快照
+	frozenRankingKey := fmt.Sprintf(FrozenRankingKey, competitionID)
+	frozen, err := s.rdb.Exists(ctx, frozenRankingKey).Result()
+	if err != nil {
+		s.log.WarnContext(ctx, "check frozen ranking from redis failed", logger.Error(err))
+	} else if frozen > 0 {
+		rankingKey = frozenRankingKey
+		userDetailKeyFormat = FrozenUserDetailKey
+	}
 
-	// 获取排行榜(按分数降序)
-	userIDs, err := s.rdb.ZRevRange(ctx, rankingKey, start, stop).Result()
+	categoryMap, err := loadCompetitionUserCategories(ctx, s.db, s.rdb, competitionID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("get ranking from redis failed: %w", err)
+		s.log.WarnContext(ctx, "get competition user category failed", logger.Error(err))
+	}
+	// 判题服务可能在取消资格后继续写入排行榜, 读取时再过滤一次
+	disqualified, err := loadCompetitionDisqualifiedUsers(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		s.log.WarnContext(ctx, "get competition disqualified users failed", logger.Error(err))
+	}
+	// 罚时调整在读取时叠加, 避免判题服务更新排行榜时覆盖
+	adjustments, err := loadCompetitionTimeAdjustments(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		s.log.WarnContext(ctx, "get competition time adjustment failed", logger.Error(err))
 	}
 
-	// 获取总数
-	total, err := s.rdb.ZCard(ctx, rankingKey).Result()
+	var entries []rankEntry
+	var total int
+	if category == nil && len(adjustments) == 0 {
+		entries, total, err = s.pageRankingEntries(ctx, rankingKey, page, pageSize, categoryMap, disqualified)
+		if err != nil {
+			return nil, 0, err
+		}
+	} else {
+		// 按分类筛选或存在罚时调整时顺序与 ZSet 不一致, 需要读取完整排行榜(按分数降序)重新计算排名
+		members, err := s.rdb.ZRevRangeWithScores(ctx, rankingKey, 0, -1).Result()
+		if err != nil {
+			return nil, 0, fmt.Errorf("get ranking from redis failed: %w", err)
+		}
+		members = slices.DeleteFunc(members, func(member redis.Z) bool {
+			userID, _ := strconv.ParseUint(member.Member.(string), 10, 64)
+			_, ok := disqualified[userID]
+			return ok
+		})
+		if len(adjustments) != 0 {
+			for i := range members {
+				userID, _ := strconv.ParseUint(members[i].Member.(string), 10, 64)
+				members[i].Score -= float64(adjustments[userID])
+			}
+			sort.SliceStable(members, func(i, j int) bool {
+				return members[i].Score > members[j].Score
+			})
+		}
+		entries = assignRanks(members, categoryMap)
+		if category != nil {
+			entries = slices.DeleteFunc(entries, func(entry rankEntry) bool {
+				return entry.Category != *category
+			})
+		}
+
+		total = len(entries)
+		start := min((page-1)*pageSize, total)
+		stop := min(start+pageSize, total)
+		entries = entries[start:stop]
+	}
+
+	// 获取题目顺序与标号
+	positions := make(map[uint64]model.CompetitionProblemItem)
+	items, err := getCompetitionProblemItems(ctx, s.db, s.rdb, competitionID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("get total from redis failed: %w", err)
+		s.log.WarnContext(ctx, "get competition problem items failed", logger.Error(err))
+	}
+	for _, item := range items {
+		positions[item.ProblemID] = item
 	}
 
 	// 获取用户详细信息
-	rankings := make([]model.Ranking, 0, len(userIDs))
-	for _, userIDStr := range userIDs {
-		userDetailKey := fmt.Sprintf(UserDetailKey, userIDStr, competitionID)
+	rankings := make([]model.Ranking, 0, len(entries))
+	for _, entry := range entries {
+		userIDStr := entry.UserIDStr
+		userDetailKey := fmt.Sprintf(userDetailKeyFormat, userIDStr, competitionID)
 		userDataStr, err := s.rdb.Get(ctx, userDetailKey).Result()
 		if err != nil {
 			s.log.ErrorContext(ctx, "get user detail from redis failed",
@@ -110,28 +223,245 @@ func (s *RankingServiceImpl) GetCompetitionRankingList(ctx context.Context, comp
 		}
 
 		problems := transform.SliceFromMap(userData.Problems, func(k uint64, v model.Problem) model.Problem {
+			v.Label = positions[k].Label
 			return v
 		})
+		sortProblemsByPosition(problems, positions)
 
 		rankings = append(rankings, model.Ranking{
-			UserID:        userData.UserID,
-			Username:      userData.Username,
-			Realname:      userData.Realname,
-			TotalAccepted: userData.TotalAccepted,
-			TotalTimeUsed: userData.TotalTimeUsed,
-			Problems:      problems,
+			UserID:         userData.UserID,
+			Username:       userData.Username,
+			Realname:       userData.Realname,
+			Category:       entry.Category,
+			Unofficial:     entry.Unofficial,
+			Rank:           entry.Rank,
+			CategoryRank:   entry.CategoryRank,
+			TotalAccepted:  userData.TotalAccepted,
+			TotalTimeUsed:  userData.TotalTimeUsed + adjustments[userData.UserID],
+			TimeAdjustment: adjustments[userData.UserID],
+			Problems:       problems,
 		})
 	}
 
-	return rankings, int(total), nil
+	return rankings, total, nil
+}
+
+// rankEntry 排行榜中单个选手的排名信息
+type rankEntry struct {
+	UserIDStr    string
+	Category     string
+	Unofficial   bool
+	Rank         int
+	CategoryRank int
+}
+
+// assignRanks 按分数降序计算正式排名与分类排名, 分数相同的选手排名相同, 打星选手不占用排名
+func assignRanks(members []redis.Z, categoryMap map[uint64]model.CompetitionUserCategory) []rankEntry {
+	type counter struct {
+		count     int
+		rank      int
+		lastScore float64
+	}
+	next := func(c *counter, score float64) int {
+		c.count++
+		if c.count == 1 || score != c.lastScore {
+			c.rank = c.count
+			c.lastScore = score
+		}
+		return c.rank
+	}
+
+	overall := &counter{}
+	categories := make(map[string]*counter)
+	entries := make([]rankEntry, 0, len(members))
+	for _, member := range members {
+		userIDStr, _ := member.Member.(string)
+		userID, _ := strconv.ParseUint(userIDStr, 10, 64)
+		category := categoryMap[userID]
+		entry := rankEntry{
+			UserIDStr:  userIDStr,
+			Category:   category.Category,
+			Unofficial: category.Unofficial,
+		}
+		if !entry.Unofficial {
+			entry.Rank = next(overall, member.Score)
+			if categories[entry.Category] == nil {
+				categories[entry.Category] = &counter{}
+			}
+			entry.CategoryRank = next(categories[entry.Category], member.Score)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// pageRankingEntries 只读取当前页的排行榜成员并计算排名, 用于不按分类筛选且没有罚时调整的排行榜.
+// 排名为分数更高的正式选手数加一: 用 ZCOUNT 统计分数更高的成员, 再扣除其中取消资格与打星的选手;
+// 取消资格与设置了分类的选手通常远少于全部选手, 单独读取其分数
+func (s *RankingServiceImpl) pageRankingEntries(ctx context.Context, rankingKey string, page, pageSize int, categoryMap map[uint64]model.CompetitionUserCategory, disqualified map[uint64]struct{}) ([]rankEntry, int, error) {
+	card, err := s.rdb.ZCard(ctx, rankingKey).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("get ranking size from redis failed: %w", err)
+	}
+
+	special := make([]uint64, 0, len(disqualified)+len(categoryMap))
+	for userID := range disqualified {
+		special = append(special, userID)
+	}
+	for userID := range categoryMap {
+		if _, ok := disqualified[userID]; !ok {
+			special = append(special, userID)
+		}
+	}
+	scores := make(map[uint64]float64, len(special))
+	disqualifiedRanks := make([]int64, 0, len(disqualified))
+	if len(special) != 0 {
+		pipeline := s.rdb.Pipeline()
+		scoreCmds := make([]*redis.FloatCmd, len(special))
+		rankCmds := make([]*redis.IntCmd, 0, len(disqualified))
+		for i, userID := range special {
+			member := strconv.FormatUint(userID, 10)
+			scoreCmds[i] = pipeline.ZScore(ctx, rankingKey, member)
+			if _, ok := disqualified[userID]; ok {
+				rankCmds = append(rankCmds, pipeline.ZRevRank(ctx, rankingKey, member))
+			}
+		}
+		// 不在排行榜中的选手返回 redis.Nil
+		if _, err = pipeline.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return nil, 0, fmt.Errorf("get ranking score from redis failed: %w", err)
+		}
+		for i, userID := range special {
+			if score, err := scoreCmds[i].Result(); err == nil {
+				scores[userID] = score
+			}
+		}
+		for _, cmd := range rankCmds {
+			if rank, err := cmd.Result(); err == nil {
+				disqualifiedRanks = append(disqualifiedRanks, rank)
+			}
+		}
+		slices.Sort(disqualifiedRanks)
+	}
+
+	total := int(card) - len(disqualifiedRanks)
+	start := int64((page - 1) * pageSize)
+	if start >= int64(total) {
+		return []rankEntry{}, total, nil
+	}
+	// 将去除取消资格选手后的位置换算为 ZSet 中的位置
+	rawStart := start
+	for _, rank := range disqualifiedRanks {
+		if rank <= rawStart {
+			rawStart++
+		}
+	}
+	members, err := s.rdb.ZRevRangeWithScores(ctx, rankingKey, rawStart, rawStart+int64(pageSize+len(disqualifiedRanks))-1).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("get ranking from redis failed: %w", err)
+	}
+	covered := rawStart == 0 && int64(len(members)) == card
+	members = slices.DeleteFunc(members, func(member redis.Z) bool {
+		userID, _ := strconv.ParseUint(member.Member.(string), 10, 64)
+		_, ok := disqualified[userID]
+		return ok
+	})
+	if covered {
+		// 当前页已包含全部选手, 直接顺序计算
+		entries := assignRanks(members, categoryMap)
+		return entries[:min(pageSize, len(entries))], total, nil
+	}
+	members = members[:min(pageSize, len(members))]
+
+	pipeline := s.rdb.Pipeline()
+	countCmds := make([]*redis.IntCmd, len(members))
+	for i, member := range members {
+		countCmds[i] = pipeline.ZCount(ctx, rankingKey, "("+strconv.FormatFloat(member.Score, 'f', -1, 64), "+inf")
+	}
+	if _, err = pipeline.Exec(ctx); err != nil {
+		return nil, 0, fmt.Errorf("count ranking from redis failed: %w", err)
+	}
+
+	entries := make([]rankEntry, 0, len(members))
+	for i, member := range members {
+		userIDStr, _ := member.Member.(string)
+		userID, _ := strconv.ParseUint(userIDStr, 10, 64)
+		category := categoryMap[userID]
+		entry := rankEntry{
+			UserIDStr:  userIDStr,
+			Category:   category.Category,
+			Unofficial: category.Unofficial,
+		}
+		if !entry.Unofficial {
+			// higher 为分数更高的正式选手数, sameHigher 与 classifiedHigher 分别为其中同分类与有分类的选手数
+			higher := int(countCmds[i].Val())
+			sameHigher, classifiedHigher := 0, 0
+			for specialID, score := range scores {
+				if score <= member.Score {
+					continue
+				}
+				specialCategory := categoryMap[specialID]
+				if _, ok := disqualified[specialID]; ok || specialCategory.Unofficial {
+					higher--
+					continue
+				}
+				if specialCategory.Category == entry.Category {
+					sameHigher++
+				}
+				if specialCategory.Category != "" {
+					classifiedHigher++
+				}
+			}
+			entry.Rank = higher + 1
+			if entry.Category != "" {
+				entry.CategoryRank = sameHigher + 1
+			} else {
+				entry.CategoryRank = higher - classifiedHigher + 1
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total, nil
+}
+
+// sortProblemsByPosition 按题目顺序排列, 已不在比赛中的题目排在末尾
+func sortProblemsByPosition(problems []model.Problem, positions map[uint64]model.CompetitionProblemItem) {
+	sort.Slice(problems, func(i, j int) bool {
+		pi, iok := positions[problems[i].ProblemID]
+		pj, jok := positions[problems[j].ProblemID]
+		if iok != jok {
+			return iok
+		}
+		if iok && pi.Position != pj.Position {
+			return pi.Position < pj.Position
+		}
+		return problems[i].ProblemID < problems[j].ProblemID
+	})
 }
 
-// UpdateUserScore 更新用户分数
-func (s *RankingServiceImpl) UpdateUserScore(ctx context.Context, competitionID, problemID, userID uint64, isAccepted bool, submissionTime time.Time, startTime time.Time) error {
+// UpdateUserScore 根据判题结果更新用户分数与题目统计
+func (s *RankingServiceImpl) UpdateUserScore(ctx context.Context, competitionID, problemID, userID uint64, result ojmodel.SubmissionResult, submissionTime time.Time, startTime time.Time) error {
 	userIDStr := strconv.FormatUint(userID, 10)
 	userDetailKey := fmt.Sprintf(UserDetailKey, userIDStr, competitionID)
 	rankingKey := fmt.Sprintf(RankingKey, competitionID)
 
+	// 与 HandleJudgeReportMessage 一致, 已取消资格的选手不计入排行榜
+	disqualified, err := loadCompetitionDisqualifiedUsers(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return fmt.Errorf("get competition disqualified users failed: %w", err)
+	}
+	if _, ok := disqualified[userID]; ok {
+		return nil
+	}
+
+	// 与 HandleJudgeReportMessage 一致, 比赛结束后的赛后补题提交不计入正式排行榜
+	competition, err := loadCompetitionMeta(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return fmt.Errorf("get competition meta failed: %w", err)
+	}
+	if !submissionTime.Before(competition.EndTime) {
+		return nil
+	}
+
 	// 获取当前用户数据
 	var userData UserRankingData
 	userDataStr, err := s.rdb.Get(ctx, userDetailKey).Result()
@@ -174,6 +504,7 @@ func (s *RankingServiceImpl) UpdateUserScore(ctx context.Context, competitionID,
 			Where("user_id = ?", userID).
 			Where("problem_id = ?", problemID).
 			Where("result = ?", ojmodel.SubmissionResultAccepted).
+			Where("created_at < ?", competition.EndTime). // 赛后补题提交均晚于比赛结束时间
 			Select("id", "created_at").
 			Limit(1).
 			First(&latestAccepted).Error
@@ -187,7 +518,8 @@ func (s *RankingServiceImpl) UpdateUserScore(ctx context.Context, competitionID,
 			Where("competition_id = ?", competitionID).
 			Where("user_id = ?", userID).
 			Where("problem_id = ?", problemID).
-			Where("result != ?", ojmodel.SubmissionResultAccepted)
+			Where("result != ?", ojmodel.SubmissionResultAccepted).
+			Where("created_at < ?", competition.EndTime)
 		if latestAccepted.ID != 0 {
 			if latestAccepted.CreatedAt.Before(submissionTime) {
 				result = model.ProblemStatusAccepted
@@ -205,74 +537,20 @@ func (s *RankingServiceImpl) UpdateUserScore(ctx context.Context, competitionID,
 		}
 	}
 
-	// 如果题目已经通过, 不再更新
+	// 如果题目已经通过, 不再更新排行榜, 提交仍计入题目统计
 	if problem.Result == model.ProblemStatusAccepted {
+		s.recordProblemStatistics(ctx, competitionID, problemID, userID, result, false, false, 0, 0)
 		return nil
 	}
 
-	submissionTimeMs := submissionTime.UnixMilli()
-	startTimeMs := startTime.UnixMilli()
-	if submissionTimeMs < startTimeMs {
-		submissionTimeMs = startTimeMs
-	}
-	offsetMs := submissionTimeMs - startTimeMs
-	if isAccepted {
-		if problem.Result != model.ProblemStatusAccepted {
-			userData.TotalAccepted++
-			penaltyTime := int64(problem.Retrys * PenaltyTime)
-			userData.TotalTimeUsed = offsetMs + penaltyTime
-			problem.Result = model.ProblemStatusAccepted
-			problem.AcceptedAt = offsetMs
-			// 最快通过标记更新
-			fastKey := fmt.Sprintf(ProblemFastestSolverKey, problemID, competitionID)
-			// 读取当前最快
-			var prev struct {
-				ProblemID  uint64 `json:"problem_id"`
-				UserID     uint64 `json:"user_id"`
-				AcceptedAt int64  `json:"accepted_at"`
-			}
-			prevStr, err := s.rdb.Get(ctx, fastKey).Result()
-			if err == redis.Nil || err != nil {
-				// 无记录或读取失败，直接设置为当前最快
-				problem.IsFastest = true
-				fastBytes, _ := json.Marshal(struct {
-					ProblemID  uint64 `json:"problem_id"`
-					UserID     uint64 `json:"user_id"`
-					AcceptedAt int64  `json:"accepted_at"`
-				}{ProblemID: problemID, UserID: userID, AcceptedAt: userData.TotalTimeUsed})
-				_ = s.rdb.Set(ctx, fastKey, fastBytes, 8*time.Hour).Err()
-			} else {
-				_ = json.Unmarshal([]byte(prevStr), &prev)
-				if prev.AcceptedAt == 0 || userData.TotalTimeUsed < prev.AcceptedAt {
-					// 更新最快用户
-					if prev.UserID != 0 && prev.UserID != userID {
-						// 取消之前用户的最快标记
-						prevUserKey := fmt.Sprintf(UserDetailKey, strconv.FormatUint(prev.UserID, 10), competitionID)
-						prevUserStr, e2 := s.rdb.Get(ctx, prevUserKey).Result()
-						if e2 == nil {
-							var prevUser UserRankingData
-							if json.Unmarshal([]byte(prevUserStr), &prevUser) == nil {
-								p := prevUser.Problems[problemID]
-								p.IsFastest = false
-								prevUser.Problems[problemID] = p
-								b, _ := json.Marshal(prevUser)
-								_ = s.rdb.Set(ctx, prevUserKey, b, 8*time.Hour).Err()
-							}
-						}
-					}
-					problem.IsFastest = true
-					fastBytes, _ := json.Marshal(struct {
-						ProblemID  uint64 `json:"problem_id"`
-						UserID     uint64 `json:"user_id"`
-						AcceptedAt int64  `json:"accepted_at"`
-					}{ProblemID: problemID, UserID: userID, AcceptedAt: userData.TotalTimeUsed})
-					_ = s.rdb.Set(ctx, fastKey, fastBytes, 8*time.Hour).Err()
-				}
-			}
-		}
-	} else {
-		problem.Retrys++
-		problem.Result = model.ProblemStatusAttempting
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return fmt.Errorf("get competition setting failed: %w", err)
+	}
+	tried := problem.Result == model.ProblemStatusNotAttempted
+	solved := scoreSubmission(&userData, &problem, result == ojmodel.SubmissionResultAccepted, submissionTime, startTime, setting.PenaltyMs())
+	if solved {
+		problem.IsFastest = s.updateFastestSolver(ctx, competitionID, problemID, userID, userData.TotalTimeUsed)
 	}
 
 	userData.Problems[problemID] = problem
@@ -299,11 +577,74 @@ func (s *RankingServiceImpl) UpdateUserScore(ctx context.Context, competitionID,
 		return fmt.Errorf("zadd ranking to redis failed: %w", err)
 	}
 
+	s.recordProblemStatistics(ctx, competitionID, problemID, userID, result, tried, solved, problem.AcceptedAt, problem.Retrys+1)
+	s.notifyRankingChanged(ctx, competitionID)
 	return nil
 }
 
+// scoreSubmission 将一次提交计入选手在该题上的成绩, 实时更新、重建与回放共用同一套计分规则.
+// 题目已通过时不再计分; 通过时 TotalTimeUsed 记为本题通过用时与罚时之和, 与判题服务写入的数据保持一致.
+// 返回本次提交是否使题目变为通过
+func scoreSubmission(userData *UserRankingData, problem *model.Problem, isAccepted bool, submissionTime, startTime time.Time, penaltyMs int64) bool {
+	if problem.Result == model.ProblemStatusAccepted {
+		return false
+	}
+	if !isAccepted {
+		problem.Retrys++
+		problem.Result = model.ProblemStatusAttempting
+		return false
+	}
+	offsetMs := max(submissionTime.UnixMilli()-startTime.UnixMilli(), 0)
+	userData.TotalAccepted++
+	userData.TotalTimeUsed = offsetMs + int64(problem.Retrys)*penaltyMs
+	problem.Result = model.ProblemStatusAccepted
+	problem.AcceptedAt = offsetMs
+	return true
+}
+
+// isFasterSolve 新的通过是否快于当前最快通过, 以通过用时与罚时之和比较, 没有记录时为 0
+func isFasterSolve(prevAcceptedAt, acceptedAt int64) bool {
+	return prevAcceptedAt == 0 || acceptedAt < prevAcceptedAt
+}
+
+// updateFastestSolver 更新题目的最快通过者, 新通过更快时取消之前用户的最快标记, 返回当前用户是否为最快通过者
+func (s *RankingServiceImpl) updateFastestSolver(ctx context.Context, competitionID, problemID, userID uint64, acceptedAt int64) bool {
+	type fastestSolver struct {
+		ProblemID  uint64 `json:"problem_id"`
+		UserID     uint64 `json:"user_id"`
+		AcceptedAt int64  `json:"accepted_at"`
+	}
+	fastKey := fmt.Sprintf(ProblemFastestSolverKey, problemID, competitionID)
+
+	// 读取当前最快, 无记录或读取失败时直接设置为当前最快
+	var prev fastestSolver
+	if prevStr, err := s.rdb.Get(ctx, fastKey).Result(); err == nil {
+		_ = json.Unmarshal([]byte(prevStr), &prev)
+		if !isFasterSolve(prev.AcceptedAt, acceptedAt) {
+			return false
+		}
+	}
+	if prev.UserID != 0 && prev.UserID != userID {
+		// 取消之前用户的最快标记
+		prevUserKey := fmt.Sprintf(UserDetailKey, strconv.FormatUint(prev.UserID, 10), competitionID)
+		if prevUserStr, err := s.rdb.Get(ctx, prevUserKey).Result(); err == nil {
+			var prevUser UserRankingData
+			if json.Unmarshal([]byte(prevUserStr), &prevUser) == nil {
+				p := prevUser.Problems[problemID]
+				p.IsFastest = false
+				prevUser.Problems[problemID] = p
+				b, _ := json.Marshal(prevUser)
+				_ = s.rdb.Set(ctx, prevUserKey, b, 8*time.Hour).Err()
+			}
+		}
+	}
+	fastBytes, _ := json.Marshal(fastestSolver{ProblemID: problemID, UserID: userID, AcceptedAt: acceptedAt})
+	_ = s.rdb.Set(ctx, fastKey, fastBytes, 8*time.Hour).Err()
+	return true
+}
+
 // rebuildRanking 重建用户分数排行榜
-func (s *RankingServiceImpl) rebuildRanking(ctx context.Context, competitionID, problemID, userID uint64, isAccepted bool, submissionTime time.Time, startTime time.Time) error {
+func (s *RankingServiceImpl) rebuildRanking(ctx context.Context, competitionID, problemID, userID uint64, isAccepted bool, submissionTime time.Time, startTime time.Time, penaltyMs int64) error {
 	userIDStr := strconv.FormatUint(userID, 10)
 	userDetailKey := fmt.Sprintf(UserDetailKey, userIDStr, competitionID)
 	rankingKey := fmt.Sprintf(RankingKey, competitionID)
@@ -350,69 +691,8 @@ func (s *RankingServiceImpl) rebuildRanking(ctx context.Context, competitionID,
 		return nil
 	}
 
-	submissionTimeMs := submissionTime.UnixMilli()
-	startTimeMs := startTime.UnixMilli()
-	if submissionTimeMs < startTimeMs {
-		submissionTimeMs = startTimeMs
-	}
-	offsetMs := submissionTimeMs - startTimeMs
-	if isAccepted {
-		if problem.Result != model.ProblemStatusAccepted {
-			userData.TotalAccepted++
-			penaltyTime := int64(problem.Retrys * PenaltyTime)
-			userData.TotalTimeUsed = offsetMs + penaltyTime
-			problem.Result = model.ProblemStatusAccepted
-			problem.AcceptedAt = offsetMs
-			// 最快通过标记更新
-			fastKey := fmt.Sprintf(ProblemFastestSolverKey, problemID, competitionID)
-			// 读取当前最快
-			var prev struct {
-				ProblemID  uint64 `json:"problem_id"`
-				UserID     uint64 `json:"user_id"`
-				AcceptedAt int64  `json:"accepted_at"`
-			}
-			prevStr, err := s.rdb.Get(ctx, fastKey).Result()
-			if err == redis.Nil || err != nil {
-				// 无记录或读取失败，直接设置为当前最快
-				problem.IsFastest = true
-				fastBytes, _ := json.Marshal(struct {
-					ProblemID  uint64 `json:"problem_id"`
-					UserID     uint64 `json:"user_id"`
-					AcceptedAt int64  `json:"accepted_at"`
-				}{ProblemID: problemID, UserID: userID, AcceptedAt: userData.TotalTimeUsed})
-				_ = s.rdb.Set(ctx, fastKey, fastBytes, 8*time.Hour).Err()
-			} else {
-				_ = json.Unmarshal([]byte(prevStr), &prev)
-				if prev.AcceptedAt == 0 || userData.TotalTimeUsed < prev.AcceptedAt {
-					// 更新最快用户
-					if prev.UserID != 0 && prev.UserID != userID {
-						// 取消之前用户的最快标记
-						prevUserKey := fmt.Sprintf(UserDetailKey, strconv.FormatUint(prev.UserID, 10), competitionID)
-						prevUserStr, e2 := s.rdb.Get(ctx, prevUserKey).Result()
-						if e2 == nil {
-							var prevUser UserRankingData
-							if json.Unmarshal([]byte(prevUserStr), &prevUser) == nil {
-								p := prevUser.Problems[problemID]
-								p.IsFastest = false
-								prevUser.Problems[problemID] = p
-								b, _ := json.Marshal(prevUser)
-								_ = s.rdb.Set(ctx, prevUserKey, b, 8*time.Hour).Err()
-							}
-						}
-					}
-					problem.IsFastest = true
-					fastBytes, _ := json.Marshal(struct {
-						ProblemID  uint64 `json:"problem_id"`
-						UserID     uint64 `json:"user_id"`
-						AcceptedAt int64  `json:"accepted_at"`
-					}{ProblemID: problemID, UserID: userID, AcceptedAt: userData.TotalTimeUsed})
-					_ = s.rdb.Set(ctx, fastKey, fastBytes, 8*time.Hour).Err()
-				}
-			}
-		}
-	} else {
-		problem.Retrys++
-		problem.Result = model.ProblemStatusAttempting
+	if scoreSubmission(&userData, &problem, isAccepted, submissionTime, startTime, penaltyMs) {
+		problem.IsFastest = s.updateFastestSolver(ctx, competitionID, problemID, userID, userData.TotalTimeUsed)
 	}
 
 	userData.Problems[problemID] = problem
@@ -460,6 +740,8 @@ func (s *RankingServiceImpl) InitCompetitionRanking(ctx context.Context, competi
 	}
 	// 删除排行榜 ZSet
 	pipeline.Del(ctx, rankingKey)
+	// 删除题目统计, 下次读取时从 MySQL 重新计算
+	pipeline.Del(ctx, fmt.Sprintf(ProblemStatisticsKey, competitionID))
 
 	// 删除题目最快解题者记录
 	var problemIDList []uint64
@@ -477,13 +759,14 @@ func (s *RankingServiceImpl) InitCompetitionRanking(ctx context.Context, competi
 		return fmt.Errorf("clean redis failed: %w", err)
 	}
 
-	// 2. 从 MySQL 加载该比赛所有有效提交 (按 ID 升序/时间升序)
+	// 2. 从 MySQL 加载该比赛所有有效提交 (按 ID 升序/时间升序), 赛后补题提交不计入正式排行榜
 	var submissions []ojmodel.Submission
 	err = s.db.WithContext(ctx).
 		Model(&ojmodel.Submission{}).
 		Where("competition_id = ?", competitionID).
 		Where("result != ?", 0). // 未判题
-		Order("id ASC").         // 保证回放顺序
+		Where("id NOT IN (?)", upsolveSubmissionIDs(s.db.WithContext(ctx), competitionID)).
+		Order("id ASC"). // 保证回放顺序
 		Find(&submissions).Error
 	if err != nil {
 		return fmt.Errorf("load submissions from db failed: %w", err)
@@ -497,14 +780,27 @@ func (s *RankingServiceImpl) InitCompetitionRanking(ctx context.Context, competi
 		First(&comp).Error; err != nil {
 		return fmt.Errorf("load competition start_time failed: %w", err)
 	}
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return fmt.Errorf("load competition setting failed: %w", err)
+	}
+
+	// 被取消资格的选手不参与排行榜与最快通过计算
+	disqualified, err := loadCompetitionDisqualifiedUsers(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return fmt.Errorf("load disqualified users failed: %w", err)
+	}
 
 	// 3. 重放提交记录重建排行榜
 	for _, sub := range submissions {
 		if sub.Result == nil {
 			continue
 		}
+		if _, ok := disqualified[sub.UserID]; ok {
+			continue
+		}
 		isAccepted := *sub.Result == ojmodel.SubmissionResultAccepted
-		err := s.rebuildRanking(ctx, competitionID, sub.ProblemID, sub.UserID, isAccepted, sub.CreatedAt, comp.StartTime)
+		err := s.rebuildRanking(ctx, competitionID, sub.ProblemID, sub.UserID, isAccepted, sub.CreatedAt, comp.StartTime, setting.PenaltyMs())
 		if err != nil {
 			s.log.ErrorContext(ctx, "InitCompetitionRanking: replay submission failed",
 				logger.Error(err),
@@ -512,6 +808,7 @@ func (s *RankingServiceImpl) InitCompetitionRanking(ctx context.Context, competi
 		}
 	}
 
+	s.notifyRankingChanged(ctx, competitionID)
 	return nil
 }
 
@@ -561,16 +858,112 @@ func (s *RankingServiceImpl) GetFastestSolverList(ctx context.Context, competiti
 }
 
 // Export 导出数据
-func (s *RankingServiceImpl) Export(ctx context.Context, competitionID uint64, exporter factory.ExporterType) (string, error) {
-	exp := s.exporterFactory.GetExporter(exporter)
+func (s *RankingServiceImpl) Export(ctx context.Context, competitionID uint64, exporterType factory.ExporterType, opts *exporter.Options) (string, error) {
+	exp := s.exporterFactory.GetExporter(exporterType)
 	if exp == nil {
 		return "", fmt.Errorf("get exporter failed: exporter not found")
 	}
-	filepath := fmt.Sprintf("%s/%d%s", s.exportDir, competitionID, factory.ExporterSuffixMap[exporter])
+	filepath := fmt.Sprintf("%s/%d%s", s.exportDir, competitionID, factory.ExporterSuffixMap[exporterType])
 	file, err := os.Create(filepath)
 	if err != nil {
 		return "", fmt.Errorf("create file failed: %w", err)
 	}
 	defer file.Close()
-	return filepath, exp.Export(ctx, competitionID, file)
+	return filepath, exp.Export(ctx, competitionID, file, opts)
+}
+
+// FreezeCompetitionRanking 封榜, 将当前排行榜快照为选手可见的封榜排行榜
+func (s *RankingServiceImpl) FreezeCompetitionRanking(ctx context.Context, competitionID uint64) error {
+	rankingKey := fmt.Sprintf(RankingKey, competitionID)
+	frozenRankingKey := fmt.Sprintf(FrozenRankingKey, competitionID)
+
+	members, err := s.rdb.ZRangeWithScores(ctx, rankingKey, 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("get ranking from redis failed: %w", err)
+	}
+
+	pipeline := s.rdb.Pipeline()
+	pipeline.Del(ctx, frozenRankingKey)
+	for _, member := range members {
+		userIDStr, _ := member.Member.(string)
+		userDataStr, err := s.rdb.Get(ctx, fmt.Sprintf(UserDetailKey, userIDStr, competitionID)).Result()
+		if err != nil {
+			s.log.WarnContext(ctx, "FreezeCompetitionRanking: get user detail from redis failed",
+				logger.Error(err),
+				logger.String("user_id", userIDStr))
+			continue
+		}
+		// 封榜快照不设过期时间, 由定榜时删除, 避免封榜期间过期导致排行榜提前解封
+		pipeline.Set(ctx, fmt.Sprintf(FrozenUserDetailKey, userIDStr, competitionID), userDataStr, 0)
+		pipeline.ZAdd(ctx, frozenRankingKey, member)
+	}
+	if _, err = pipeline.Exec(ctx); err != nil {
+		return fmt.Errorf("save frozen ranking to redis failed: %w", err)
+	}
+	if err = s.freezeProblemStatistics(ctx, competitionID); err != nil {
+		s.log.WarnContext(ctx, "FreezeCompetitionRanking: save frozen problem statistics failed", logger.Error(err))
+	}
+	s.notifyRankingChanged(ctx, competitionID)
+	return nil
+}
+
+// FinalizeCompetitionRanking 定榜, 将实时排行榜写回 MySQL 并解除封榜
+func (s *RankingServiceImpl) FinalizeCompetitionRanking(ctx context.Context, competitionID uint64) error {
+	frozenRankingKey := fmt.Sprintf(FrozenRankingKey, competitionID)
+
+	if err := s.saveCompetitionUserRanking(ctx, competitionID); err != nil {
+		return err
+	}
+
+	// 解除封榜
+	frozenUserIDs, err := s.rdb.ZRange(ctx, frozenRankingKey, 0, -1).Result()
+	if err != nil {
+		s.log.WarnContext(ctx, "FinalizeCompetitionRanking: failed to get frozen members", logger.Error(err))
+	}
+	pipeline := s.rdb.Pipeline()
+	for _, uid := range frozenUserIDs {
+		pipeline.Del(ctx, fmt.Sprintf(FrozenUserDetailKey, uid, competitionID))
+	}
+	pipeline.Del(ctx, frozenRankingKey)
+	pipeline.Del(ctx, fmt.Sprintf(FrozenProblemStatisticsKey, competitionID))
+	if _, err = pipeline.Exec(ctx); err != nil {
+		return fmt.Errorf("clean frozen ranking failed: %w", err)
+	}
+	s.notifyRankingChanged(ctx, competitionID)
+	return nil
+}
+
+// saveCompetitionUserRanking 将实时排行榜中的成绩写回 competition_user, 供定榜后的导出使用
+func (s *RankingServiceImpl) saveCompetitionUserRanking(ctx context.Context, competitionID uint64) error {
+	userIDs, err := s.rdb.ZRange(ctx, fmt.Sprintf(RankingKey, competitionID), 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("get ranking from redis failed: %w", err)
+	}
+
+	for _, userIDStr := range userIDs {
+		userDataStr, err := s.rdb.Get(ctx, fmt.Sprintf(UserDetailKey, userIDStr, competitionID)).Result()
+		if err != nil {
+			return fmt.Errorf("get user %s detail from redis failed: %w", userIDStr, err)
+		}
+		var userData UserRankingData
+		if err = json.Unmarshal([]byte(userDataStr), &userData); err != nil {
+			return fmt.Errorf("unmarshal user %s detail from redis failed: %w", userIDStr, err)
+		}
+		retryCount := 0
+		for _, problem := range userData.Problems {
+			retryCount += problem.Retrys
+		}
+		err = s.db.WithContext(ctx).Model(&ojmodel.CompetitionUser{}).
+			Where("competition_id = ?", competitionID).
+			Where("user_id = ?", userData.UserID).
+			Updates(map[string]any{
+				"pass_count":  userData.TotalAccepted,
+				"total_time":  userData.TotalTimeUsed,
+				"retry_count": retryCount,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("update competition user %s ranking failed: %w", userIDStr, err)
+		}
+	}
+	return nil
 }
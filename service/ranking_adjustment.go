@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSubmissionNotFound  = errors.New("submission not found")
+	ErrSubmissionNotJudged = errors.New("submission has not been judged")
+	ErrSubmissionUnchanged = errors.New("submission result is unchanged")
+)
+
+const competitionTimeAdjustmentKey = "competition:%d:time:adjustment"
+
+// loadCompetitionTimeAdjustments 获取比赛中各选手的罚时调整总和 ( 单位: 毫秒 ), 优先读取 Redis 缓存
+func loadCompetitionTimeAdjustments(ctx context.Context, db *gorm.DB, rdb redis.Cmdable, competitionID uint64) (map[uint64]int64, error) {
+	adjustmentKey := fmt.Sprintf(competitionTimeAdjustmentKey, competitionID)
+
+	adjustments := make(map[uint64]int64)
+	adjustmentBytes, err := rdb.Get(ctx, adjustmentKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(adjustmentBytes, &adjustments); err == nil {
+			return adjustments, nil
+		}
+	}
+
+	var rows []struct {
+		UserID  uint64 `gorm:"column:user_id"`
+		Minutes int64  `gorm:"column:minutes"`
+	}
+	err = db.WithContext(ctx).Model(&model.CompetitionTimeAdjustment{}).
+		Where("competition_id = ?", competitionID).
+		Group("user_id").
+		Select("user_id", "SUM(minutes) AS minutes").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("loadCompetitionTimeAdjustments failed at select from competition_time_adjustment: %w", err)
+	}
+	for _, row := range rows {
+		if row.Minutes != 0 {
+			adjustments[row.UserID] = row.Minutes * int64(time.Minute/time.Millisecond)
+		}
+	}
+
+	if adjustmentBytes, err = json.Marshal(adjustments); err == nil {
+		rdb.Set(ctx, adjustmentKey, adjustmentBytes, 8*time.Hour)
+	}
+	return adjustments, nil
+}
+
+// OverrideSubmissionResult 人工改判提交结果并重建排行榜
+func (s *RankingServiceImpl) OverrideSubmissionResult(ctx context.Context, param *model.OverrideSubmissionResultParam) error {
+	var competitionID uint64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var submission ojmodel.Submission
+		err := tx.Model(&ojmodel.Submission{}).
+			Where("id = ?", param.SubmissionID).
+			Where("competition_id <> ?", 0).
+			Select("id", "competition_id", "user_id", "problem_id", "status", "result").
+			First(&submission).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSubmissionNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("select from submission failed: %w", err)
+		}
+		if submission.Status == nil || *submission.Status != ojmodel.SubmissionStatusJudged || submission.Result == nil {
+			return ErrSubmissionNotJudged
+		}
+		if *submission.Result == *param.Result {
+			return ErrSubmissionUnchanged
+		}
+
+		err = tx.Model(&ojmodel.Submission{}).
+			Where("id = ?", submission.ID).
+			Update("result", param.Result.Int8()).Error
+		if err != nil {
+			return fmt.Errorf("update submission result failed: %w", err)
+		}
+		err = tx.Create(&model.SubmissionVerdictOverride{
+			SubmissionID:   submission.ID,
+			CompetitionID:  submission.CompetitionID,
+			UserID:         submission.UserID,
+			ProblemID:      submission.ProblemID,
+			OriginalResult: *submission.Result,
+			Result:         *param.Result,
+			Reason:         param.Reason,
+			OperatorID:     param.Operator,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("insert into submission_verdict_override failed: %w", err)
+		}
+		competitionID = submission.CompetitionID
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("OverrideSubmissionResult failed: %w", err)
+	}
+
+	// 改判会影响罚时与最快通过者, 直接从 MySQL 重放全部提交
+	if err = s.InitCompetitionRanking(ctx, competitionID); err != nil {
+		return fmt.Errorf("OverrideSubmissionResult failed at rebuild ranking: %w", err)
+	}
+
+	// 定榜后导出读取 competition_user 中的成绩, 需要同步写回
+	var states []model.CompetitionState
+	err = s.db.WithContext(ctx).Model(&model.CompetitionLifecycle{}).
+		Where("competition_id = ?", competitionID).
+		Pluck("state", &states).Error
+	if err != nil {
+		return fmt.Errorf("OverrideSubmissionResult failed at select from competition_lifecycle: %w", err)
+	}
+	if len(states) != 0 && states[0] >= model.CompetitionStateFinalized {
+		if err = s.saveCompetitionUserRanking(ctx, competitionID); err != nil {
+			return fmt.Errorf("OverrideSubmissionResult failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// AddCompetitionTimeAdjustment 为选手增加或减少罚时, 调整在读取排行榜时叠加, 立即生效
+func (s *RankingServiceImpl) AddCompetitionTimeAdjustment(ctx context.Context, param *model.AddCompetitionTimeAdjustmentParam) error {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&ojmodel.CompetitionUser{}).
+		Where("competition_id = ?", param.CompetitionID).
+		Where("user_id = ?", param.UserID).
+		Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("AddCompetitionTimeAdjustment failed at select from competition_user: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("AddCompetitionTimeAdjustment failed: %w", ErrCompetitionUserNotFound)
+	}
+
+	err = s.db.WithContext(ctx).Create(&model.CompetitionTimeAdjustment{
+		CompetitionID: param.CompetitionID,
+		UserID:        param.UserID,
+		Minutes:       param.Minutes,
+		Reason:        param.Reason,
+		OperatorID:    param.Operator,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("AddCompetitionTimeAdjustment failed at insert into competition_time_adjustment: %w", err)
+	}
+
+	// 同步删除缓存, 保证接口返回后排行榜立即反映调整
+	if err = s.rdb.Del(ctx, fmt.Sprintf(competitionTimeAdjustmentKey, param.CompetitionID)).Err(); err != nil {
+		return fmt.Errorf("AddCompetitionTimeAdjustment failed at delete cache: %w", err)
+	}
+	s.notifyRankingChanged(ctx, param.CompetitionID)
+	return nil
+}
+
+// GetCompetitionAdjustmentList 获取比赛的人工改判与罚时调整记录
+func (s *RankingServiceImpl) GetCompetitionAdjustmentList(ctx context.Context, competitionID uint64, userID *uint64) ([]model.SubmissionVerdictOverride, []model.CompetitionTimeAdjustment, error) {
+	var overrides []model.SubmissionVerdictOverride
+	query := s.db.WithContext(ctx).Where("competition_id = ?", competitionID)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	err := query.Order("id DESC").Find(&overrides).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetCompetitionAdjustmentList failed at select from submission_verdict_override: %w", err)
+	}
+
+	var adjustments []model.CompetitionTimeAdjustment
+	query = s.db.WithContext(ctx).Where("competition_id = ?", competitionID)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	err = query.Order("id DESC").Find(&adjustments).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetCompetitionAdjustmentList failed at select from competition_time_adjustment: %w", err)
+	}
+	return overrides, adjustments, nil
+}
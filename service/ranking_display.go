@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/privacy"
+)
+
+// competitionAnonymousKey 比赛的匿名密钥, 首次以匿名模式展示时生成, 不设置过期时间,
+// 丢失后匿名 ID 会整体变化, 客户端需重新获取完整排行榜
+const competitionAnonymousKey = "competition:%d:anonymous:key"
+
+// anonymousKeySize 匿名密钥的字节数
+const anonymousKeySize = 32
+
+// GetRankingDisplay 获取比赛的选手信息展示方式, public 为 true 时返回公开排行榜的展示方式
+func (s *RankingServiceImpl) GetRankingDisplay(ctx context.Context, competitionID uint64, public bool) (*model.RankingDisplay, error) {
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("GetRankingDisplay failed: %w", err)
+	}
+	display := &model.RankingDisplay{
+		Mode: setting.DisplayMode,
+	}
+	if public {
+		display.Mode = setting.PublicDisplayMode()
+	}
+	if display.Mode == privacy.DisplayModeNickname {
+		display.Nicknames, err = loadCompetitionUserNicknames(ctx, s.db, s.rdb, competitionID)
+		if err != nil {
+			return nil, fmt.Errorf("GetRankingDisplay failed: %w", err)
+		}
+	}
+	if display.Mode == privacy.DisplayModeAnonymous {
+		display.AnonymousKey, err = loadCompetitionAnonymousKey(ctx, s.rdb, competitionID)
+		if err != nil {
+			return nil, fmt.Errorf("GetRankingDisplay failed: %w", err)
+		}
+	}
+	return display, nil
+}
+
+// loadCompetitionAnonymousKey 获取比赛的匿名密钥, 不存在时随机生成; 并发生成时以先写入的为准
+func loadCompetitionAnonymousKey(ctx context.Context, rdb redis.Cmdable, competitionID uint64) ([]byte, error) {
+	key := fmt.Sprintf(competitionAnonymousKey, competitionID)
+	anonymousKey, err := rdb.Get(ctx, key).Bytes()
+	if err == nil {
+		return anonymousKey, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("loadCompetitionAnonymousKey failed: %w", err)
+	}
+	anonymousKey = make([]byte, anonymousKeySize)
+	if _, err = rand.Read(anonymousKey); err != nil {
+		return nil, fmt.Errorf("loadCompetitionAnonymousKey failed: %w", err)
+	}
+	if err = rdb.SetNX(ctx, key, anonymousKey, 0).Err(); err != nil {
+		return nil, fmt.Errorf("loadCompetitionAnonymousKey failed: %w", err)
+	}
+	anonymousKey, err = rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("loadCompetitionAnonymousKey failed: %w", err)
+	}
+	return anonymousKey, nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/logger"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCompetitionUserNotFound = errors.New("user is not in competition")
+	ErrUserAlreadyDisqualified = errors.New("user has already been disqualified")
+	ErrUserNotDisqualified     = errors.New("user is not disqualified")
+)
+
+const competitionDisqualifiedKey = "competition:%d:disqualified"
+
+// loadCompetitionDisqualifiedUsers 获取比赛中当前处于取消资格状态的用户, 优先读取 Redis 缓存
+func loadCompetitionDisqualifiedUsers(ctx context.Context, db *gorm.DB, rdb redis.Cmdable, competitionID uint64) (map[uint64]struct{}, error) {
+	disqualifiedKey := fmt.Sprintf(competitionDisqualifiedKey, competitionID)
+
+	var userIDs []uint64
+	userIDsBytes, err := rdb.Get(ctx, disqualifiedKey).Bytes()
+	if err != nil || json.Unmarshal(userIDsBytes, &userIDs) != nil {
+		err = db.WithContext(ctx).Model(&model.CompetitionDisqualification{}).
+			Where("competition_id = ?", competitionID).
+			Where("revoked_at IS NULL").
+			Distinct().
+			Pluck("user_id", &userIDs).Error
+		if err != nil {
+			return nil, fmt.Errorf("loadCompetitionDisqualifiedUsers failed at select from competition_disqualification: %w", err)
+		}
+		if userIDsBytes, err = json.Marshal(userIDs); err == nil {
+			rdb.Set(ctx, disqualifiedKey, userIDsBytes, 8*time.Hour)
+		}
+	}
+
+	disqualified := make(map[uint64]struct{}, len(userIDs))
+	for _, userID := range userIDs {
+		disqualified[userID] = struct{}{}
+	}
+	return disqualified, nil
+}
+
+// DisqualifyCompetitionUser 取消选手比赛资格, 将其移出排行榜并重新计算各题最快通过者
+func (s *RankingServiceImpl) DisqualifyCompetitionUser(ctx context.Context, param *model.DisqualifyCompetitionUserParam) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&ojmodel.CompetitionUser{}).
+			Where("competition_id = ?", param.CompetitionID).
+			Where("user_id = ?", param.UserID).
+			Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("select from competition_user failed: %w", err)
+		}
+		if count == 0 {
+			return ErrCompetitionUserNotFound
+		}
+
+		err = tx.Model(&model.CompetitionDisqualification{}).
+			Where("competition_id = ?", param.CompetitionID).
+			Where("user_id = ?", param.UserID).
+			Where("revoked_at IS NULL").
+			Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("select from competition_disqualification failed: %w", err)
+		}
+		if count != 0 {
+			return ErrUserAlreadyDisqualified
+		}
+
+		err = tx.Create(&model.CompetitionDisqualification{
+			CompetitionID: param.CompetitionID,
+			UserID:        param.UserID,
+			Reason:        param.Reason,
+			OperatorID:    param.Operator,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("insert into competition_disqualification failed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("DisqualifyCompetitionUser failed: %w", err)
+	}
+
+	if err = s.refreshDisqualifiedRanking(ctx, param.CompetitionID, param.UserID, true); err != nil {
+		return fmt.Errorf("DisqualifyCompetitionUser failed: %w", err)
+	}
+	return nil
+}
+
+// ReinstateCompetitionUser 恢复选手比赛资格, 重新计算排行榜
+func (s *RankingServiceImpl) ReinstateCompetitionUser(ctx context.Context, param *model.ReinstateCompetitionUserParam) error {
+	now := time.Now()
+	res := s.db.WithContext(ctx).Model(&model.CompetitionDisqualification{}).
+		Where("competition_id = ?", param.CompetitionID).
+		Where("user_id = ?", param.UserID).
+		Where("revoked_at IS NULL").
+		Updates(map[string]any{
+			"revoked_at": &now,
+			"revoker_id": param.Operator,
+		})
+	if res.Error != nil {
+		return fmt.Errorf("ReinstateCompetitionUser failed at update competition_disqualification: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("ReinstateCompetitionUser failed: %w", ErrUserNotDisqualified)
+	}
+
+	if err := s.refreshDisqualifiedRanking(ctx, param.CompetitionID, param.UserID, false); err != nil {
+		return fmt.Errorf("ReinstateCompetitionUser failed: %w", err)
+	}
+	return nil
+}
+
+// GetCompetitionDisqualificationList 获取比赛取消资格记录
+func (s *RankingServiceImpl) GetCompetitionDisqualificationList(ctx context.Context, competitionID uint64, active bool) ([]model.CompetitionDisqualification, error) {
+	var disqualifications []model.CompetitionDisqualification
+	query := s.db.WithContext(ctx).Where("competition_id = ?", competitionID)
+	if active {
+		query = query.Where("revoked_at IS NULL")
+	}
+	err := query.Order("id DESC").Find(&disqualifications).Error
+	if err != nil {
+		return nil, fmt.Errorf("GetCompetitionDisqualificationList failed: %w", err)
+	}
+	return disqualifications, nil
+}
+
+// refreshDisqualifiedRanking 取消或恢复资格后刷新缓存并从 MySQL 重建实时排行榜,
+// 封榜期间同步调整封榜快照, 避免提前泄露封榜后的成绩
+func (s *RankingServiceImpl) refreshDisqualifiedRanking(ctx context.Context, competitionID, userID uint64, disqualified bool) error {
+	// 重建排行榜依赖最新的取消资格名单, 这里同步删除缓存
+	if err := s.rdb.Del(ctx, fmt.Sprintf(competitionDisqualifiedKey, competitionID)).Err(); err != nil {
+		return fmt.Errorf("delete disqualified user cache failed: %w", err)
+	}
+
+	if err := s.InitCompetitionRanking(ctx, competitionID); err != nil {
+		return fmt.Errorf("rebuild ranking failed: %w", err)
+	}
+
+	frozenRankingKey := fmt.Sprintf(FrozenRankingKey, competitionID)
+	frozen, err := s.rdb.Exists(ctx, frozenRankingKey).Result()
+	if err != nil || frozen == 0 {
+		return nil
+	}
+	userIDStr := strconv.FormatUint(userID, 10)
+	if disqualified {
+		if err = s.rdb.ZRem(ctx, frozenRankingKey, userIDStr).Err(); err != nil {
+			s.log.WarnContext(ctx, "remove disqualified user from frozen ranking failed", logger.Error(err))
+		}
+		return nil
+	}
+	userDataStr, err := s.rdb.Get(ctx, fmt.Sprintf(FrozenUserDetailKey, userIDStr, competitionID)).Result()
+	if err != nil {
+		// 封榜时选手没有提交记录, 定榜后再出现在排行榜上
+		return nil
+	}
+	var userData UserRankingData
+	if err = json.Unmarshal([]byte(userDataStr), &userData); err != nil {
+		s.log.WarnContext(ctx, "unmarshal frozen user detail failed", logger.Error(err))
+		return nil
+	}
+	err = s.rdb.ZAdd(ctx, frozenRankingKey, redis.Z{
+		Score:  s.calculateScore(userData.TotalAccepted, userData.TotalTimeUsed),
+		Member: userIDStr,
+	}).Err()
+	if err != nil {
+		s.log.WarnContext(ctx, "add reinstated user to frozen ranking failed", logger.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/gotools/transform"
+	"github.com/to404hanga/pkg404/logger"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRankTrendTop      = 10
+	defaultRankTrendInterval = 5
+)
+
+// rankingReplay 按提交时间回放比赛提交记录, 得到任意时刻的排行榜
+type rankingReplay struct {
+	competition  ojmodel.Competition
+	penaltyMs    int64
+	submissions  []ojmodel.Submission
+	users        map[uint64]ojmodel.CompetitionUser
+	disqualified map[uint64]struct{}
+	adjustments  map[uint64]int64
+	categoryMap  map[uint64]model.CompetitionUserCategory
+	positions    map[uint64]model.CompetitionProblemItem
+	upsolve      map[uint64]struct{} // 赛后补题提交, 仅在回放包含补题的排行榜时加载
+
+	cursor  int
+	board   map[uint64]*UserRankingData
+	fastest map[uint64]replayFastestSolver // 各题当前的最快通过者
+}
+
+// replayFastestSolver 回放中题目的最快通过者, AcceptedAt 与实时排行榜相同为通过用时与罚时之和
+type replayFastestSolver struct {
+	UserID     uint64
+	AcceptedAt int64
+}
+
+// loadRankingReplay 加载回放所需的比赛信息与全部已判题提交, withUpsolve 为 true 时同时加载赛后补题提交
+func (s *RankingServiceImpl) loadRankingReplay(ctx context.Context, competitionID uint64, withUpsolve bool) (*rankingReplay, error) {
+	replay := &rankingReplay{
+		users:   make(map[uint64]ojmodel.CompetitionUser),
+		board:   make(map[uint64]*UserRankingData),
+		fastest: make(map[uint64]replayFastestSolver),
+	}
+
+	err := s.db.WithContext(ctx).
+		Where("id = ?", competitionID).
+		First(&replay.competition).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCompetitionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select from competition failed: %w", err)
+	}
+
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("load competition setting failed: %w", err)
+	}
+	replay.penaltyMs = setting.PenaltyMs()
+
+	query := s.db.WithContext(ctx).Model(&ojmodel.Submission{}).
+		Where("competition_id = ?", competitionID).
+		Where("result != ?", ojmodel.SubmissionResultUnjudged)
+	if withUpsolve {
+		var upsolveIDs []uint64
+		err = upsolveSubmissionIDs(s.db.WithContext(ctx), competitionID).Pluck("submission_id", &upsolveIDs).Error
+		if err != nil {
+			return nil, fmt.Errorf("load upsolve submissions from db failed: %w", err)
+		}
+		replay.upsolve = make(map[uint64]struct{}, len(upsolveIDs))
+		for _, id := range upsolveIDs {
+			replay.upsolve[id] = struct{}{}
+		}
+	} else {
+		// 与实时排行榜相同, 比赛结束时刻及之后的提交不计入
+		query = query.
+			Where("created_at < ?", replay.competition.EndTime).
+			Where("id NOT IN (?)", upsolveSubmissionIDs(s.db.WithContext(ctx), competitionID))
+	}
+	err = query.
+		Select("id", "user_id", "problem_id", "result", "created_at").
+		Order("created_at ASC, id ASC").
+		Find(&replay.submissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("load submissions from db failed: %w", err)
+	}
+
+	var users []ojmodel.CompetitionUser
+	err = s.db.WithContext(ctx).Model(&ojmodel.CompetitionUser{}).
+		Where("competition_id = ?", competitionID).
+		Select("user_id", "username", "realname").
+		Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("load competition users from db failed: %w", err)
+	}
+	for _, user := range users {
+		replay.users[user.UserID] = user
+	}
+
+	if replay.disqualified, err = loadCompetitionDisqualifiedUsers(ctx, s.db, s.rdb, competitionID); err != nil {
+		return nil, err
+	}
+	if replay.adjustments, err = loadCompetitionTimeAdjustments(ctx, s.db, s.rdb, competitionID); err != nil {
+		return nil, err
+	}
+	if replay.categoryMap, err = loadCompetitionUserCategories(ctx, s.db, s.rdb, competitionID); err != nil {
+		s.log.WarnContext(ctx, "get competition user category failed", logger.Error(err))
+	}
+
+	replay.positions = make(map[uint64]model.CompetitionProblemItem)
+	items, err := getCompetitionProblemItems(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		s.log.WarnContext(ctx, "get competition problem items failed", logger.Error(err))
+	}
+	for _, item := range items {
+		replay.positions[item.ProblemID] = item
+	}
+	return replay, nil
+}
+
+// durationMinutes 比赛时长 ( 单位: 分钟 )
+func (r *rankingReplay) durationMinutes() int {
+	return int(r.competition.EndTime.Sub(r.competition.StartTime) / time.Minute)
+}
+
+// advance 回放截至比赛开始后 minute 分钟 ( 含 ) 的提交, minute 只能递增
+func (r *rankingReplay) advance(minute int) {
+	r.advanceTo(r.competition.StartTime.Add(time.Duration(minute+1) * time.Minute))
+}
+
+// advanceTo 回放提交时间早于 deadline 的提交, deadline 只能递增.
+// 计分与最快通过规则与实时排行榜共用; 赛后补题通过计入通过数但不计罚时, 也不参与最快通过
+func (r *rankingReplay) advanceTo(deadline time.Time) {
+	for ; r.cursor < len(r.submissions); r.cursor++ {
+		sub := r.submissions[r.cursor]
+		if !sub.CreatedAt.Before(deadline) {
+			return
+		}
+		if _, ok := r.disqualified[sub.UserID]; ok {
+			continue
+		}
+
+		userData, ok := r.board[sub.UserID]
+		if !ok {
+			user := r.users[sub.UserID]
+			userData = &UserRankingData{
+				UserID:   sub.UserID,
+				Username: user.Username,
+				Realname: user.Realname,
+				Problems: make(map[uint64]model.Problem),
+			}
+			r.board[sub.UserID] = userData
+		}
+		problem, ok := userData.Problems[sub.ProblemID]
+		if !ok {
+			problem = model.Problem{ProblemID: sub.ProblemID}
+		}
+		if problem.Result == model.ProblemStatusAccepted {
+			continue
+		}
+
+		isAccepted := *sub.Result == ojmodel.SubmissionResultAccepted
+		if _, ok = r.upsolve[sub.ID]; ok && isAccepted {
+			problem.Result = model.ProblemStatusAccepted
+			problem.Upsolved = true
+			userData.TotalAccepted++
+			userData.UpsolveAccepted++
+		} else if scoreSubmission(userData, &problem, isAccepted, sub.CreatedAt, r.competition.StartTime, r.penaltyMs) {
+			prev, ok := r.fastest[sub.ProblemID]
+			if !ok || isFasterSolve(prev.AcceptedAt, userData.TotalTimeUsed) {
+				if ok && prev.UserID != sub.UserID {
+					// 取消之前用户的最快标记
+					prevProblem := r.board[prev.UserID].Problems[sub.ProblemID]
+					prevProblem.IsFastest = false
+					r.board[prev.UserID].Problems[sub.ProblemID] = prevProblem
+				}
+				problem.IsFastest = true
+				r.fastest[sub.ProblemID] = replayFastestSolver{UserID: sub.UserID, AcceptedAt: userData.TotalTimeUsed}
+			}
+		}
+		userData.Problems[sub.ProblemID] = problem
+	}
+}
+
+// members 当前回放进度下按分数降序排列的排行榜成员, 已叠加罚时调整
+func (r *rankingReplay) members() []redis.Z {
+	members := make([]redis.Z, 0, len(r.board))
+	for userID, userData := range r.board {
+		members = append(members, redis.Z{
+			Score:  float64(int64(userData.TotalAccepted)*ScoreMultiplier - userData.TotalTimeUsed - r.adjustments[userID]),
+			Member: strconv.FormatUint(userID, 10),
+		})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Score != members[j].Score {
+			return members[i].Score > members[j].Score
+		}
+		return members[i].Member.(string) < members[j].Member.(string)
+	})
+	return members
+}
+
+// GetCompetitionRankingHistory 获取比赛开始后第 minute 分钟结束时的排行榜, 返回排行榜、总数与实际查询的分钟数
+func (s *RankingServiceImpl) GetCompetitionRankingHistory(ctx context.Context, competitionID uint64, minute, page, pageSize int, category *string) ([]model.Ranking, int, int, error) {
+	replay, err := s.loadRankingReplay(ctx, competitionID, false)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("GetCompetitionRankingHistory failed: %w", err)
+	}
+	minute = min(minute, replay.durationMinutes())
+	replay.advance(minute)
+
+	rankings, total := replay.rankings(page, pageSize, category)
+	return rankings, total, minute, nil
+}
+
+// rankings 当前回放进度下的排行榜分页, category 不为空时只返回该分类的选手
+func (r *rankingReplay) rankings(page, pageSize int, category *string) ([]model.Ranking, int) {
+	entries := assignRanks(r.members(), r.categoryMap)
+	if category != nil {
+		entries = slices.DeleteFunc(entries, func(entry rankEntry) bool {
+			return entry.Category != *category
+		})
+	}
+	total := len(entries)
+	start := min((page-1)*pageSize, total)
+	stop := min(start+pageSize, total)
+
+	rankings := make([]model.Ranking, 0, stop-start)
+	for _, entry := range entries[start:stop] {
+		userID, _ := strconv.ParseUint(entry.UserIDStr, 10, 64)
+		userData := r.board[userID]
+		problems := transform.SliceFromMap(userData.Problems, func(k uint64, v model.Problem) model.Problem {
+			v.Label = r.positions[k].Label
+			return v
+		})
+		sortProblemsByPosition(problems, r.positions)
+
+		rankings = append(rankings, model.Ranking{
+			UserID:          userID,
+			Username:        userData.Username,
+			Realname:        userData.Realname,
+			Category:        entry.Category,
+			Unofficial:      entry.Unofficial,
+			Rank:            entry.Rank,
+			CategoryRank:    entry.CategoryRank,
+			TotalAccepted:   userData.TotalAccepted,
+			TotalTimeUsed:   userData.TotalTimeUsed + r.adjustments[userID],
+			TimeAdjustment:  r.adjustments[userID],
+			UpsolveAccepted: userData.UpsolveAccepted,
+			Problems:        problems,
+		})
+	}
+	return rankings, total
+}
+
+// GetCompetitionRankTrend 获取选手排名随时间的变化, userID 为空时返回最终排名前 top 名选手
+func (s *RankingServiceImpl) GetCompetitionRankTrend(ctx context.Context, competitionID uint64, userID *uint64, top, interval int) (*model.GetCompetitionRankTrendResponse, error) {
+	if top <= 0 {
+		top = defaultRankTrendTop
+	}
+	if interval <= 0 {
+		interval = defaultRankTrendInterval
+	}
+
+	replay, err := s.loadRankingReplay(ctx, competitionID, false)
+	if err != nil {
+		return nil, fmt.Errorf("GetCompetitionRankTrend failed: %w", err)
+	}
+	duration := replay.durationMinutes()
+	minutes := make([]int, 0, duration/interval+2)
+	for minute := 0; minute < duration; minute += interval {
+		minutes = append(minutes, minute)
+	}
+	minutes = append(minutes, duration)
+
+	// 每个采样点的排名与通过数, 按用户 ID 索引
+	type trendPoint struct {
+		rank     int
+		accepted int
+	}
+	snapshots := make([]map[uint64]trendPoint, 0, len(minutes))
+	var final []rankEntry
+	for _, minute := range minutes {
+		replay.advance(minute)
+		final = assignRanks(replay.members(), replay.categoryMap)
+		snapshot := make(map[uint64]trendPoint, len(final))
+		for _, entry := range final {
+			id, _ := strconv.ParseUint(entry.UserIDStr, 10, 64)
+			snapshot[id] = trendPoint{
+				rank:     entry.Rank,
+				accepted: replay.board[id].TotalAccepted,
+			}
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	var userIDs []uint64
+	if userID != nil {
+		userIDs = append(userIDs, *userID)
+	} else {
+		for _, entry := range final {
+			if len(userIDs) >= top {
+				break
+			}
+			if entry.Rank == 0 {
+				continue
+			}
+			id, _ := strconv.ParseUint(entry.UserIDStr, 10, 64)
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	series := make([]model.RankTrendSeries, 0, len(userIDs))
+	for _, id := range userIDs {
+		user := replay.users[id]
+		trend := model.RankTrendSeries{
+			UserID:        id,
+			Username:      user.Username,
+			Realname:      user.Realname,
+			Ranks:         make([]int, 0, len(minutes)),
+			TotalAccepted: make([]int, 0, len(minutes)),
+		}
+		for i := range minutes {
+			point := snapshots[i][id]
+			trend.Ranks = append(trend.Ranks, point.rank)
+			trend.TotalAccepted = append(trend.TotalAccepted, point.accepted)
+		}
+		series = append(series, trend)
+	}
+
+	return &model.GetCompetitionRankTrendResponse{
+		Minutes: minutes,
+		Series:  series,
+	}, nil
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/event"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"gorm.io/gorm"
+)
+
+// judgeCorrection 判题报告到达后对实时排行榜的修正方式
+type judgeCorrection int8
+
+const (
+	judgeCorrectionNone        judgeCorrection = iota // 无需修正
+	judgeCorrectionRemoveUser                         // 选手已被取消资格, 移出排行榜
+	judgeCorrectionRebuildUser                        // 赛后补题提交, 按比赛期间的提交重建选手成绩
+)
+
+// judgeReportCorrection 根据提交时间与选手资格判断判题服务写入的结果是否需要修正,
+// 与 InitCompetitionRanking 一致, 取消资格优先于赛后补题
+func judgeReportCorrection(submissionTime, endTime time.Time, disqualified bool) judgeCorrection {
+	switch {
+	case disqualified:
+		return judgeCorrectionRemoveUser
+	case !submissionTime.Before(endTime):
+		return judgeCorrectionRebuildUser
+	default:
+		return judgeCorrectionNone
+	}
+}
+
+// HandleJudgeReportMessage 判题服务写入判题结果并更新实时排行榜后投递判题报告,
+// 据此修正判题服务写入实时排行榜的、不应计入排行榜的结果 ( 已取消资格选手与赛后补题的提交 ), 并使题目统计失效
+func (s *RankingServiceImpl) HandleJudgeReportMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	var report model.JudgeReport
+	if err := json.Unmarshal(msg.Value, &report); err != nil {
+		return fmt.Errorf("HandleJudgeReportMessage failed at unmarshal report: %w: %w", event.ErrInvalidMessage, err)
+	}
+	if report.SubmissionID == 0 {
+		return fmt.Errorf("HandleJudgeReportMessage failed: %w: submission id is empty", event.ErrInvalidMessage)
+	}
+
+	var submission ojmodel.Submission
+	err := s.db.WithContext(ctx).
+		Model(&ojmodel.Submission{}).
+		Select("id", "competition_id", "problem_id", "user_id", "created_at").
+		Where("id = ?", report.SubmissionID).
+		First(&submission).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("HandleJudgeReportMessage failed: %w: submission %d not found", event.ErrInvalidMessage, report.SubmissionID)
+	}
+	if err != nil {
+		return fmt.Errorf("HandleJudgeReportMessage failed at select from submission: %w", err)
+	}
+	// 练习提交不进入排行榜
+	if submission.CompetitionID == model.PracticeCompetitionID {
+		return nil
+	}
+	ctx = loggerv2.ContextWithFields(ctx,
+		logger.Uint64("competition_id", submission.CompetitionID),
+		logger.Uint64("submission_id", submission.ID))
+
+	// 统计只在读取时从 MySQL 重建, 先于排行榜修正失效, 修正失败重试时不影响统计
+	s.invalidateProblemStatistics(ctx, submission.CompetitionID)
+
+	disqualified, err := loadCompetitionDisqualifiedUsers(ctx, s.db, s.rdb, submission.CompetitionID)
+	if err != nil {
+		return fmt.Errorf("HandleJudgeReportMessage failed: %w", err)
+	}
+	competition, err := loadCompetitionMeta(ctx, s.db, s.rdb, submission.CompetitionID)
+	if err != nil {
+		return fmt.Errorf("HandleJudgeReportMessage failed: %w", err)
+	}
+	_, isDisqualified := disqualified[submission.UserID]
+	switch judgeReportCorrection(submission.CreatedAt, competition.EndTime, isDisqualified) {
+	case judgeCorrectionRemoveUser:
+		err = s.removeDisqualifiedUser(ctx, submission.CompetitionID, submission.ProblemID, submission.UserID)
+	case judgeCorrectionRebuildUser:
+		err = s.rebuildUserRanking(ctx, submission.CompetitionID, submission.ProblemID, submission.UserID, competition.StartTime, competition.EndTime)
+	}
+	if err != nil {
+		return fmt.Errorf("HandleJudgeReportMessage failed: %w", err)
+	}
+	return nil
+}
+
+// replayUserRanking 按提交顺序重放选手比赛期间的提交, 结束时间之后的赛后补题提交不计入
+func replayUserRanking(userData *UserRankingData, submissions []ojmodel.Submission, startTime, endTime time.Time, penaltyMs int64) {
+	for _, sub := range submissions {
+		if sub.Result == nil || *sub.Result == ojmodel.SubmissionResultUnjudged || !sub.CreatedAt.Before(endTime) {
+			continue
+		}
+		problem, ok := userData.Problems[sub.ProblemID]
+		if !ok {
+			problem = model.Problem{ProblemID: sub.ProblemID, Result: model.ProblemStatusNotAttempted}
+		}
+		scoreSubmission(userData, &problem, *sub.Result == ojmodel.SubmissionResultAccepted, sub.CreatedAt, startTime, penaltyMs)
+		userData.Problems[sub.ProblemID] = problem
+	}
+}
+
+// rebuildUserRanking 判题服务不区分赛后补题, 仍会为补题提交更新实时排行榜, 按比赛期间的提交重建该选手的成绩;
+// 补题通过使选手成为该题最快通过者时, 其他选手的最快标记已被覆盖, 改为从 MySQL 重建排行榜
+func (s *RankingServiceImpl) rebuildUserRanking(ctx context.Context, competitionID, problemID, userID uint64, startTime, endTime time.Time) error {
+	var submissions []ojmodel.Submission
+	err := s.db.WithContext(ctx).
+		Model(&ojmodel.Submission{}).
+		Select("id", "problem_id", "result", "created_at").
+		Where("competition_id = ?", competitionID).
+		Where("user_id = ?", userID).
+		Where("created_at < ?", endTime).
+		Where("result != ?", ojmodel.SubmissionResultUnjudged).
+		Order("id ASC").
+		Find(&submissions).Error
+	if err != nil {
+		return fmt.Errorf("load user submissions from db failed: %w", err)
+	}
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return fmt.Errorf("load competition setting failed: %w", err)
+	}
+	userData := UserRankingData{
+		UserID:   userID,
+		Problems: make(map[uint64]model.Problem),
+	}
+	replayUserRanking(&userData, submissions, startTime, endTime, setting.PenaltyMs())
+
+	fastestUserID, err := s.getFastestSolverUserID(ctx, competitionID, problemID)
+	if err != nil {
+		return err
+	}
+	if fastestUserID == userID && userData.Problems[problemID].Result != model.ProblemStatusAccepted {
+		if err = s.InitCompetitionRanking(ctx, competitionID); err != nil {
+			return fmt.Errorf("rebuild ranking failed: %w", err)
+		}
+		return nil
+	}
+
+	userIDStr := strconv.FormatUint(userID, 10)
+	userDetailKey := fmt.Sprintf(UserDetailKey, userIDStr, competitionID)
+	rankingKey := fmt.Sprintf(RankingKey, competitionID)
+	if len(userData.Problems) == 0 {
+		// 比赛期间没有提交, 补题前不在排行榜中
+		pipeline := s.rdb.TxPipeline()
+		pipeline.ZRem(ctx, rankingKey, userIDStr)
+		pipeline.Del(ctx, userDetailKey)
+		if _, err = pipeline.Exec(ctx); err != nil {
+			return fmt.Errorf("remove upsolve user from ranking failed: %w", err)
+		}
+		s.notifyRankingChanged(ctx, competitionID)
+		return nil
+	}
+
+	err = s.db.WithContext(ctx).Model(&ojmodel.User{}).
+		Where("id = ?", userID).
+		Select("username", "realname").
+		First(&userData).Error
+	if err != nil {
+		return fmt.Errorf("get user detail from db failed: %w", err)
+	}
+	for pid, problem := range userData.Problems {
+		if problem.Result != model.ProblemStatusAccepted {
+			continue
+		}
+		if problem.IsFastest, err = s.isFastestSolver(ctx, competitionID, pid, userID); err != nil {
+			return err
+		}
+		userData.Problems[pid] = problem
+	}
+	userDataBytes, err := json.Marshal(userData)
+	if err != nil {
+		return fmt.Errorf("marshal user detail failed: %w", err)
+	}
+	pipeline := s.rdb.TxPipeline()
+	pipeline.Set(ctx, userDetailKey, userDataBytes, 8*time.Hour)
+	pipeline.ZAdd(ctx, rankingKey, redis.Z{
+		Score:  s.calculateScore(userData.TotalAccepted, userData.TotalTimeUsed),
+		Member: userIDStr,
+	})
+	if _, err = pipeline.Exec(ctx); err != nil {
+		return fmt.Errorf("save rebuilt user ranking to redis failed: %w", err)
+	}
+	s.notifyRankingChanged(ctx, competitionID)
+	return nil
+}
+
+// isFastestSolver 选手是否为题目当前的最快通过者
+func (s *RankingServiceImpl) isFastestSolver(ctx context.Context, competitionID, problemID, userID uint64) (bool, error) {
+	fastestUserID, err := s.getFastestSolverUserID(ctx, competitionID, problemID)
+	if err != nil {
+		return false, err
+	}
+	return fastestUserID == userID, nil
+}
+
+// removeDisqualifiedUser 判题服务不感知取消资格, 仍会为已取消资格的选手更新实时排行榜, 将其重新移出;
+// 选手因此成为该题最快通过者时, 其他选手的最快标记已被覆盖, 改为从 MySQL 重建排行榜
+func (s *RankingServiceImpl) removeDisqualifiedUser(ctx context.Context, competitionID, problemID, userID uint64) error {
+	fastestUserID, err := s.getFastestSolverUserID(ctx, competitionID, problemID)
+	if err != nil {
+		return err
+	}
+	if fastestUserID == userID {
+		if err = s.InitCompetitionRanking(ctx, competitionID); err != nil {
+			return fmt.Errorf("rebuild ranking failed: %w", err)
+		}
+		return nil
+	}
+
+	userIDStr := strconv.FormatUint(userID, 10)
+	pipeline := s.rdb.TxPipeline()
+	pipeline.ZRem(ctx, fmt.Sprintf(RankingKey, competitionID), userIDStr)
+	pipeline.Del(ctx, fmt.Sprintf(UserDetailKey, userIDStr, competitionID))
+	if _, err = pipeline.Exec(ctx); err != nil {
+		return fmt.Errorf("remove disqualified user from ranking failed: %w", err)
+	}
+	s.notifyRankingChanged(ctx, competitionID)
+	return nil
+}
+
+// getFastestSolverUserID 获取题目当前的最快通过者, 没有人通过时返回 0
+func (s *RankingServiceImpl) getFastestSolverUserID(ctx context.Context, competitionID, problemID uint64) (uint64, error) {
+	solverBytes, err := s.rdb.Get(ctx, fmt.Sprintf(ProblemFastestSolverKey, problemID, competitionID)).Bytes()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get problem fastest solver from redis failed: %w", err)
+	}
+	var solver model.FastestSolver
+	if err = json.Unmarshal(solverBytes, &solver); err != nil {
+		return 0, fmt.Errorf("unmarshal problem fastest solver failed: %w", err)
+	}
+	return solver.UserID, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+)
+
+func TestJudgeReportCorrection(t *testing.T) {
+	endTime := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name           string
+		submissionTime time.Time
+		disqualified   bool
+		want           judgeCorrection
+	}{
+		{"比赛期间提交", endTime.Add(-time.Minute), false, judgeCorrectionNone},
+		{"比赛期间最后一毫秒", endTime.Add(-time.Millisecond), false, judgeCorrectionNone},
+		{"结束时刻提交为补题", endTime, false, judgeCorrectionRebuildUser},
+		{"赛后补题", endTime.Add(time.Hour), false, judgeCorrectionRebuildUser},
+		{"取消资格选手比赛期间提交", endTime.Add(-time.Minute), true, judgeCorrectionRemoveUser},
+		{"取消资格选手赛后补题", endTime.Add(time.Hour), true, judgeCorrectionRemoveUser},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := judgeReportCorrection(tt.submissionTime, endTime, tt.disqualified); got != tt.want {
+				t.Errorf("judgeReportCorrection() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayUserRanking(t *testing.T) {
+	startTime := time.Date(2026, 10, 1, 9, 0, 0, 0, time.Local)
+	endTime := startTime.Add(3 * time.Hour)
+	penaltyMs := int64(20 * time.Minute / time.Millisecond)
+	result := func(r ojmodel.SubmissionResult) *ojmodel.SubmissionResult { return &r }
+	submission := func(problemID uint64, r ojmodel.SubmissionResult, offset time.Duration) ojmodel.Submission {
+		return ojmodel.Submission{ProblemID: problemID, Result: result(r), CreatedAt: startTime.Add(offset)}
+	}
+
+	tests := []struct {
+		name          string
+		submissions   []ojmodel.Submission
+		wantAccepted  int
+		wantTimeUsed  int64
+		wantProblems  map[uint64]model.ProblemStatut
+		wantRetrysOf1 int
+	}{
+		{
+			name:         "没有提交",
+			wantProblems: map[uint64]model.ProblemStatut{},
+		},
+		{
+			name: "比赛期间通过计入罚时",
+			submissions: []ojmodel.Submission{
+				submission(1, ojmodel.SubmissionResultWrongAnswer, 10*time.Minute),
+				submission(1, ojmodel.SubmissionResultAccepted, 30*time.Minute),
+			},
+			wantAccepted:  1,
+			wantTimeUsed:  int64(30*time.Minute/time.Millisecond) + penaltyMs,
+			wantProblems:  map[uint64]model.ProblemStatut{1: model.ProblemStatusAccepted},
+			wantRetrysOf1: 1,
+		},
+		{
+			name: "赛后补题通过不计入",
+			submissions: []ojmodel.Submission{
+				submission(1, ojmodel.SubmissionResultWrongAnswer, 10*time.Minute),
+				submission(1, ojmodel.SubmissionResultAccepted, 4*time.Hour),
+			},
+			wantProblems:  map[uint64]model.ProblemStatut{1: model.ProblemStatusAttempting},
+			wantRetrysOf1: 1,
+		},
+		{
+			name: "只有赛后补题时不进入排行榜",
+			submissions: []ojmodel.Submission{
+				submission(1, ojmodel.SubmissionResultAccepted, 3*time.Hour),
+			},
+			wantProblems: map[uint64]model.ProblemStatut{},
+		},
+		{
+			name: "未判题的提交不计入",
+			submissions: []ojmodel.Submission{
+				submission(1, ojmodel.SubmissionResultUnjudged, 10*time.Minute),
+				{ProblemID: 2, CreatedAt: startTime.Add(20 * time.Minute)},
+			},
+			wantProblems: map[uint64]model.ProblemStatut{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userData := UserRankingData{Problems: make(map[uint64]model.Problem)}
+			replayUserRanking(&userData, tt.submissions, startTime, endTime, penaltyMs)
+			if userData.TotalAccepted != tt.wantAccepted {
+				t.Errorf("TotalAccepted = %d, want %d", userData.TotalAccepted, tt.wantAccepted)
+			}
+			if userData.TotalTimeUsed != tt.wantTimeUsed {
+				t.Errorf("TotalTimeUsed = %d, want %d", userData.TotalTimeUsed, tt.wantTimeUsed)
+			}
+			if len(userData.Problems) != len(tt.wantProblems) {
+				t.Fatalf("len(Problems) = %d, want %d", len(userData.Problems), len(tt.wantProblems))
+			}
+			for problemID, want := range tt.wantProblems {
+				if got := userData.Problems[problemID].Result; got != want {
+					t.Errorf("Problems[%d].Result = %d, want %d", problemID, got, want)
+				}
+			}
+			if got := userData.Problems[1].Retrys; got != tt.wantRetrysOf1 {
+				t.Errorf("Problems[1].Retrys = %d, want %d", got, tt.wantRetrysOf1)
+			}
+		})
+	}
+}
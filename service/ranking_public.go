@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+var ErrPublicScoreboardDisabled = errors.New("public scoreboard is disabled")
+
+const (
+	PublicRankingKey     = "ranking:competition:%d:public"
+	PublicRankingLockKey = "lock:ranking:competition:%d:public:load"
+
+	publicRankingCacheTTL = 10 * time.Second // 公开排行榜访问量大且无需实时, 整榜缓存
+)
+
+// publicRanking 公开排行榜缓存, 保存已按公开展示模式处理的完整排行榜, 分页与分类筛选在读取缓存后进行
+type publicRanking struct {
+	Version    int64                          `json:"version"`
+	Problems   []model.CompetitionProblemItem `json:"problems"`
+	Statistics []model.ProblemStatistics      `json:"statistics"`
+	List       []model.Ranking                `json:"list"`
+}
+
+// GetPublicCompetitionRankingList 获取公开排行榜, 需要比赛开启公开排行榜, 封榜期间返回封榜快照
+func (s *RankingServiceImpl) GetPublicCompetitionRankingList(ctx context.Context, competitionID uint64, page, pageSize int, category *string) (*model.GetCompetitionRankingListResponse, error) {
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("GetPublicCompetitionRankingList failed: %w", err)
+	}
+	if !setting.PublicScoreboard {
+		return nil, fmt.Errorf("GetPublicCompetitionRankingList failed: %w", ErrPublicScoreboardDisabled)
+	}
+
+	board, err := s.getPublicRanking(ctx, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("GetPublicCompetitionRankingList failed: %w", err)
+	}
+
+	list := board.List
+	if category != nil {
+		list = slices.DeleteFunc(list, func(row model.Ranking) bool {
+			return row.Category != *category
+		})
+	}
+	total := len(list)
+	start := min((page-1)*pageSize, total)
+	stop := min(start+pageSize, total)
+
+	return &model.GetCompetitionRankingListResponse{
+		Version:    board.Version,
+		Problems:   board.Problems,
+		Statistics: board.Statistics,
+		List:       list[start:stop],
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+// getPublicRanking 获取公开排行榜缓存, 未命中时由持有锁的请求重建, 其余请求等待后重试
+func (s *RankingServiceImpl) getPublicRanking(ctx context.Context, competitionID uint64) (*publicRanking, error) {
+	publicKey := fmt.Sprintf(PublicRankingKey, competitionID)
+
+	var board publicRanking
+	boardBytes, err := s.rdb.Get(ctx, publicKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(boardBytes, &board); err == nil {
+			return &board, nil
+		}
+		s.log.WarnContext(ctx, "unmarshal public ranking from redis failed", logger.Error(err))
+	}
+
+	lockKey := fmt.Sprintf(PublicRankingLockKey, competitionID)
+	ok, err := s.rdb.SetNX(ctx, lockKey, "locked", 10*time.Second).Result()
+	if err != nil {
+		return nil, fmt.Errorf("set public ranking lock failed: %w", err)
+	}
+	if !ok {
+		// 未获取到锁，休眠后重试
+		time.Sleep(100 * time.Millisecond)
+		return s.getPublicRanking(ctx, competitionID)
+	}
+	defer s.rdb.Del(ctx, lockKey)
+
+	list, _, err := s.GetCompetitionRankingList(ctx, competitionID, 1, math.MaxInt32, nil)
+	if err != nil {
+		return nil, err
+	}
+	display, err := s.GetRankingDisplay(ctx, competitionID, true)
+	if err != nil {
+		return nil, err
+	}
+	display.ApplyRankings(list)
+
+	items, err := getCompetitionProblemItems(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	problems := slices.DeleteFunc(items, func(item model.CompetitionProblemItem) bool {
+		return item.Status == nil || *item.Status != ojmodel.CompetitionProblemStatusEnabled
+	})
+
+	version, err := s.GetRankingVersion(ctx, competitionID)
+	if err != nil {
+		s.log.WarnContext(ctx, "get ranking version failed", logger.Error(err))
+	}
+	statistics, err := s.GetCompetitionProblemStatistics(ctx, competitionID, false)
+	if err != nil {
+		s.log.WarnContext(ctx, "get problem statistics failed", logger.Error(err))
+	}
+	display.ApplyStatistics(statistics)
+
+	board = publicRanking{
+		Version:    version,
+		Problems:   problems,
+		Statistics: statistics,
+		List:       list,
+	}
+	if boardBytes, err = json.Marshal(board); err == nil {
+		s.rdb.Set(ctx, publicKey, boardBytes, publicRankingCacheTTL)
+	}
+	return &board, nil
+}
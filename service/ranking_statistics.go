@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+const (
+	ProblemStatisticsKey       = "ranking:competition:%d:problem:statistics:counter"
+	FrozenProblemStatisticsKey = "ranking:competition:%d:problem:statistics:frozen"
+	ProblemStatisticsLockKey   = "lock:ranking:competition:%d:problem:statistics"
+
+	problemStatisticsBatchSize  = 1000
+	problemStatisticsReadyField = "ready" // 统计哈希已从 MySQL 构建的标记, 缺失时读取前重新构建
+)
+
+// recordProblemStatisticsScript 将一次判题结果累加到题目统计哈希, 统计尚未构建时不做处理
+//
+//go:embed lua/record_problem_statistics.lua
+var recordProblemStatisticsScript string
+
+// problemStatisticsAccumulator 从 MySQL 重建统计时单题的累加数据
+type problemStatisticsAccumulator struct {
+	tries            map[uint64]int // 选手到首次通过 ( 含通过提交 ) 为止的提交次数
+	solved           map[uint64]bool
+	submissions      int
+	verdicts         map[string]int
+	firstSolveUserID uint64
+	firstSolveTime   int64
+	firstSolveTries  int
+}
+
+// problemStatisticsField 题目统计哈希中单题计数的字段名
+func problemStatisticsField(problemID uint64, name string) string {
+	return strconv.FormatUint(problemID, 10) + ":" + name
+}
+
+// recordProblemStatistics 将一次判题结果累加到题目统计, tried 与 solved 表示该选手是否首次提交与首次通过该题,
+// 与排行榜一致, 编译错误同样计为一次尝试; 累加失败时删除统计, 下次读取时从 MySQL 重新构建.
+// 只用于 UpdateUserScore, 判题服务写入的结果由 invalidateProblemStatistics 使统计失效
+func (s *RankingServiceImpl) recordProblemStatistics(ctx context.Context, competitionID, problemID, userID uint64, result ojmodel.SubmissionResult, tried, solved bool, solveTime int64, tries int) {
+	key := fmt.Sprintf(ProblemStatisticsKey, competitionID)
+	err := s.rdb.Eval(ctx, recordProblemStatisticsScript, []string{key},
+		problemID, result.String(), tried, solved, solveTime, userID, tries).Err()
+	if err != nil {
+		s.log.WarnContext(ctx, "record problem statistics failed", logger.Error(err))
+		s.rdb.Del(ctx, key)
+	}
+}
+
+// invalidateProblemStatistics 判题结果落库后使题目统计失效, 下次读取时从 MySQL 重新构建.
+// 判题报告不区分首次判题与重判, 无法据此增量累加; 失效失败时删除统计, 避免继续返回过期的计数
+func (s *RankingServiceImpl) invalidateProblemStatistics(ctx context.Context, competitionID uint64) {
+	key := fmt.Sprintf(ProblemStatisticsKey, competitionID)
+	if err := s.rdb.HDel(ctx, key, problemStatisticsReadyField).Err(); err != nil {
+		s.log.WarnContext(ctx, "invalidate problem statistics failed", logger.Error(err))
+		s.rdb.Del(ctx, key)
+	}
+}
+
+// GetCompetitionProblemStatistics 获取比赛各题统计, live 为 false 时只返回启用的题目, 封榜期间返回封榜时的统计
+func (s *RankingServiceImpl) GetCompetitionProblemStatistics(ctx context.Context, competitionID uint64, live bool) ([]model.ProblemStatistics, error) {
+	if !live {
+		frozen, err := s.rdb.Exists(ctx, fmt.Sprintf(FrozenRankingKey, competitionID)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("GetCompetitionProblemStatistics failed at check frozen ranking: %w", err)
+		}
+		if frozen > 0 {
+			list, err := s.getFrozenProblemStatistics(ctx, competitionID)
+			if err != nil {
+				return nil, fmt.Errorf("GetCompetitionProblemStatistics failed: %w", err)
+			}
+			return list, nil
+		}
+	}
+
+	fields, err := s.loadProblemStatistics(ctx, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("GetCompetitionProblemStatistics failed: %w", err)
+	}
+	list, err := s.buildProblemStatistics(ctx, competitionID, fields, !live)
+	if err != nil {
+		return nil, fmt.Errorf("GetCompetitionProblemStatistics failed: %w", err)
+	}
+	return list, nil
+}
+
+// RebuildCompetitionProblemStatistics 丢弃 Redis 中的统计计数, 从 MySQL 重新计算比赛各题统计
+func (s *RankingServiceImpl) RebuildCompetitionProblemStatistics(ctx context.Context, competitionID uint64) error {
+	err := s.rdb.Del(ctx, fmt.Sprintf(ProblemStatisticsKey, competitionID)).Err()
+	if err != nil {
+		return fmt.Errorf("RebuildCompetitionProblemStatistics failed at delete counter: %w", err)
+	}
+	if _, err = s.loadProblemStatistics(ctx, competitionID); err != nil {
+		return fmt.Errorf("RebuildCompetitionProblemStatistics failed: %w", err)
+	}
+	return nil
+}
+
+// buildProblemStatistics 按题目顺序生成统计结果, enabledOnly 为 true 时跳过未启用的题目
+func (s *RankingServiceImpl) buildProblemStatistics(ctx context.Context, competitionID uint64, fields map[string]string, enabledOnly bool) ([]model.ProblemStatistics, error) {
+	items, err := getCompetitionProblemItems(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 字段名为 "题目 ID:计数名", 判题结果计数的计数名为 "verdict:判题结果名称"
+	statMap := make(map[uint64]*model.ProblemStatistics, len(items))
+	for field, value := range fields {
+		problemIDStr, name, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		problemID, err := strconv.ParseUint(problemIDStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		n, _ := strconv.ParseInt(value, 10, 64)
+		stat, ok := statMap[problemID]
+		if !ok {
+			stat = &model.ProblemStatistics{ProblemID: problemID, Verdicts: map[string]int{}}
+			statMap[problemID] = stat
+		}
+		switch name {
+		case "tried":
+			stat.Tried = int(n)
+		case "solved":
+			stat.Solved = int(n)
+		case "submissions":
+			stat.Submissions = int(n)
+		case "first_solve_user_id":
+			stat.FirstSolveUserID = uint64(n)
+		case "first_solve_time":
+			stat.FirstSolveTime = n
+		case "first_solve_tries":
+			stat.FirstSolveTries = int(n)
+		default:
+			if verdict, ok := strings.CutPrefix(name, "verdict:"); ok {
+				stat.Verdicts[verdict] = int(n)
+			}
+		}
+	}
+
+	list := make([]model.ProblemStatistics, 0, len(items))
+	for _, item := range items {
+		if enabledOnly && (item.Status == nil || *item.Status != ojmodel.CompetitionProblemStatusEnabled) {
+			continue
+		}
+		stat := model.ProblemStatistics{ProblemID: item.ProblemID, Verdicts: map[string]int{}}
+		if counted, ok := statMap[item.ProblemID]; ok {
+			stat = *counted
+		}
+		stat.Label = item.Label
+		list = append(list, stat)
+	}
+	return list, nil
+}
+
+// loadProblemStatistics 读取 Redis 中的题目统计计数, 统计尚未构建或已失效时从 MySQL 重建.
+// 每次判题报告都会使统计失效, 并发读取由锁合并为一次重建
+func (s *RankingServiceImpl) loadProblemStatistics(ctx context.Context, competitionID uint64) (map[string]string, error) {
+	key := fmt.Sprintf(ProblemStatisticsKey, competitionID)
+	fields, err := s.rdb.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get problem statistics from redis failed: %w", err)
+	}
+	if _, ok := fields[problemStatisticsReadyField]; ok {
+		return fields, nil
+	}
+
+	lockKey := fmt.Sprintf(ProblemStatisticsLockKey, competitionID)
+	ok, err := s.rdb.SetNX(ctx, lockKey, "locked", 10*time.Second).Result()
+	if err != nil {
+		return nil, fmt.Errorf("set problem statistics lock failed: %w", err)
+	}
+	if !ok {
+		// 其他请求正在重建, 休眠后重新读取
+		time.Sleep(100 * time.Millisecond)
+		return s.loadProblemStatistics(ctx, competitionID)
+	}
+	defer s.rdb.Del(ctx, lockKey)
+
+	return s.rebuildProblemStatistics(ctx, competitionID)
+}
+
+// rebuildProblemStatistics 按提交时间顺序累加已判题的提交, 覆盖写入 Redis 中的统计计数.
+// 与排行榜一致, 不包含赛后补题与被取消资格选手的提交
+func (s *RankingServiceImpl) rebuildProblemStatistics(ctx context.Context, competitionID uint64) (map[string]string, error) {
+	var competition ojmodel.Competition
+	err := s.db.WithContext(ctx).Model(&ojmodel.Competition{}).
+		Where("id = ?", competitionID).
+		Select("start_time").
+		First(&competition).Error
+	if err != nil {
+		return nil, fmt.Errorf("load competition start_time failed: %w", err)
+	}
+	disqualified, err := loadCompetitionDisqualifiedUsers(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("load disqualified users failed: %w", err)
+	}
+
+	problems := make(map[uint64]*problemStatisticsAccumulator)
+	var lastCreatedAt time.Time
+	var lastID uint64
+	for {
+		var submissions []ojmodel.Submission
+		err = s.db.WithContext(ctx).
+			Model(&ojmodel.Submission{}).
+			Select("id", "user_id", "problem_id", "result", "created_at").
+			Where("competition_id = ?", competitionID).
+			Where("status = ?", ojmodel.SubmissionStatusJudged).
+			Where("id NOT IN (?)", upsolveSubmissionIDs(s.db.WithContext(ctx), competitionID)).
+			Where("(created_at > ? OR (created_at = ? AND id > ?))", lastCreatedAt, lastCreatedAt, lastID).
+			Order("created_at ASC, id ASC").
+			Limit(problemStatisticsBatchSize).
+			Find(&submissions).Error
+		if err != nil {
+			return nil, fmt.Errorf("load judged submissions failed: %w", err)
+		}
+
+		for i := range submissions {
+			sub := &submissions[i]
+			lastCreatedAt, lastID = sub.CreatedAt, sub.ID
+			if sub.Result == nil || *sub.Result == ojmodel.SubmissionResultUnjudged {
+				continue
+			}
+			if _, ok := disqualified[sub.UserID]; ok {
+				continue
+			}
+			acc, ok := problems[sub.ProblemID]
+			if !ok {
+				acc = &problemStatisticsAccumulator{
+					tries:    make(map[uint64]int),
+					solved:   make(map[uint64]bool),
+					verdicts: make(map[string]int),
+				}
+				problems[sub.ProblemID] = acc
+			}
+			acc.submissions++
+			acc.verdicts[sub.Result.String()]++
+			if acc.solved[sub.UserID] {
+				continue
+			}
+			acc.tries[sub.UserID]++
+			if *sub.Result != ojmodel.SubmissionResultAccepted {
+				continue
+			}
+			acc.solved[sub.UserID] = true
+			// 按提交时间顺序累加, 第一个通过的提交即为首次通过
+			if acc.firstSolveUserID == 0 {
+				acc.firstSolveUserID = sub.UserID
+				acc.firstSolveTime = max(sub.CreatedAt.Sub(competition.StartTime).Milliseconds(), 0)
+				acc.firstSolveTries = acc.tries[sub.UserID]
+			}
+		}
+
+		if len(submissions) < problemStatisticsBatchSize {
+			break
+		}
+	}
+
+	fields := map[string]string{problemStatisticsReadyField: "1"}
+	for problemID, acc := range problems {
+		fields[problemStatisticsField(problemID, "tried")] = strconv.Itoa(len(acc.tries))
+		fields[problemStatisticsField(problemID, "solved")] = strconv.Itoa(len(acc.solved))
+		fields[problemStatisticsField(problemID, "submissions")] = strconv.Itoa(acc.submissions)
+		for verdict, count := range acc.verdicts {
+			fields[problemStatisticsField(problemID, "verdict:"+verdict)] = strconv.Itoa(count)
+		}
+		if acc.firstSolveUserID != 0 {
+			fields[problemStatisticsField(problemID, "first_solve_user_id")] = strconv.FormatUint(acc.firstSolveUserID, 10)
+			fields[problemStatisticsField(problemID, "first_solve_time")] = strconv.FormatInt(acc.firstSolveTime, 10)
+			fields[problemStatisticsField(problemID, "first_solve_tries")] = strconv.Itoa(acc.firstSolveTries)
+		}
+	}
+
+	key := fmt.Sprintf(ProblemStatisticsKey, competitionID)
+	pipeline := s.rdb.TxPipeline()
+	pipeline.Del(ctx, key)
+	pipeline.HSet(ctx, key, fields)
+	pipeline.Expire(ctx, key, 8*time.Hour)
+	if _, err = pipeline.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("save problem statistics to redis failed: %w", err)
+	}
+	return fields, nil
+}
+
+// freezeProblemStatistics 封榜时保存当前统计, 封榜期间选手看到的统计不再变化
+func (s *RankingServiceImpl) freezeProblemStatistics(ctx context.Context, competitionID uint64) error {
+	fields, err := s.loadProblemStatistics(ctx, competitionID)
+	if err != nil {
+		return err
+	}
+	list, err := s.buildProblemStatistics(ctx, competitionID, fields, false)
+	if err != nil {
+		return err
+	}
+	listBytes, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("marshal frozen problem statistics failed: %w", err)
+	}
+	// 与封榜排行榜一致, 保留到定榜
+	return s.rdb.Set(ctx, fmt.Sprintf(FrozenProblemStatisticsKey, competitionID), listBytes, 0).Err()
+}
+
+// getFrozenProblemStatistics 获取封榜时保存的统计, 只返回当前启用的题目
+func (s *RankingServiceImpl) getFrozenProblemStatistics(ctx context.Context, competitionID uint64) ([]model.ProblemStatistics, error) {
+	items, err := getCompetitionProblemItems(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var frozen []model.ProblemStatistics
+	listBytes, err := s.rdb.Get(ctx, fmt.Sprintf(FrozenProblemStatisticsKey, competitionID)).Bytes()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("get frozen problem statistics from redis failed: %w", err)
+	}
+	if err == nil {
+		if err = json.Unmarshal(listBytes, &frozen); err != nil {
+			return nil, fmt.Errorf("unmarshal frozen problem statistics failed: %w", err)
+		}
+	}
+	frozenMap := make(map[uint64]model.ProblemStatistics, len(frozen))
+	for _, stat := range frozen {
+		frozenMap[stat.ProblemID] = stat
+	}
+
+	// 封榜后新增的题目或快照缺失时返回空统计, 不泄露封榜期间的提交情况
+	list := make([]model.ProblemStatistics, 0, len(items))
+	for _, item := range items {
+		if item.Status == nil || *item.Status != ojmodel.CompetitionProblemStatusEnabled {
+			continue
+		}
+		stat, ok := frozenMap[item.ProblemID]
+		if !ok {
+			stat = model.ProblemStatistics{ProblemID: item.ProblemID, Verdicts: map[string]int{}}
+		}
+		stat.Label = item.Label
+		list = append(list, stat)
+	}
+	return list, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/online_judge_controller/model"
+)
+
+func TestAssignRanks(t *testing.T) {
+	members := []redis.Z{
+		{Score: 300, Member: "1"},
+		{Score: 300, Member: "2"},
+		{Score: 250, Member: "3"},
+		{Score: 200, Member: "4"},
+		{Score: 100, Member: "5"},
+	}
+	tests := []struct {
+		name             string
+		categoryMap      map[uint64]model.CompetitionUserCategory
+		wantRanks        []int
+		wantCategoryRank []int
+	}{
+		{
+			name:             "分数相同的选手排名相同",
+			wantRanks:        []int{1, 1, 3, 4, 5},
+			wantCategoryRank: []int{1, 1, 3, 4, 5},
+		},
+		{
+			name: "打星选手不占用排名",
+			categoryMap: map[uint64]model.CompetitionUserCategory{
+				2: {Unofficial: true},
+				3: {Unofficial: true},
+			},
+			wantRanks:        []int{1, 0, 0, 2, 3},
+			wantCategoryRank: []int{1, 0, 0, 2, 3},
+		},
+		{
+			name: "分类排名独立计算",
+			categoryMap: map[uint64]model.CompetitionUserCategory{
+				2: {Category: "专科"},
+				4: {Category: "专科"},
+			},
+			wantRanks:        []int{1, 1, 3, 4, 5},
+			wantCategoryRank: []int{1, 1, 2, 2, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := assignRanks(members, tt.categoryMap)
+			if len(entries) != len(members) {
+				t.Fatalf("len(assignRanks()) = %d, want %d", len(entries), len(members))
+			}
+			for i, entry := range entries {
+				if entry.Rank != tt.wantRanks[i] || entry.CategoryRank != tt.wantCategoryRank[i] {
+					t.Errorf("user %s: rank = (%d, %d), want (%d, %d)", entry.UserIDStr, entry.Rank, entry.CategoryRank, tt.wantRanks[i], tt.wantCategoryRank[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/to404hanga/online_judge_controller/model"
+	"gorm.io/gorm"
+)
+
+// upsolveSubmissionIDs 比赛赛后补题提交 ID 的子查询, 用于从正式排行榜、统计与查重中排除补题提交
+func upsolveSubmissionIDs(db *gorm.DB, competitionID uint64) *gorm.DB {
+	return db.Model(&model.UpsolveSubmission{}).
+		Select("submission_id").
+		Where("competition_id = ?", competitionID)
+}
+
+// GetCompetitionUpsolveRankingList 回放全部提交, 获取包含赛后补题的排行榜, 补题通过计入通过数但不计罚时
+func (s *RankingServiceImpl) GetCompetitionUpsolveRankingList(ctx context.Context, competitionID uint64, page, pageSize int, category *string) ([]model.Ranking, int, error) {
+	replay, err := s.loadRankingReplay(ctx, competitionID, true)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetCompetitionUpsolveRankingList failed: %w", err)
+	}
+	replay.advanceTo(time.Now())
+
+	rankings, total := replay.rankings(page, pageSize, category)
+	return rankings, total, nil
+}
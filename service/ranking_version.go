@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/online_judge_controller/constants"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+const (
+	RankingVersionKey   = "ranking:competition:%d:version"
+	RankingRowsKey      = "ranking:competition:%d:rows"
+	RankingChangeLogKey = "ranking:competition:%d:changelog"
+	RankingSyncLockKey  = "ranking:competition:%d:sync:lock"
+
+	rankingChangeLogSize    = 200             // 保留的变更日志条数, 更早的版本需要重新获取完整排行榜
+	rankingSyncInterval     = time.Second     // 两次同步之间的最小间隔
+	rankingEventSyncTicker  = 2 * time.Second // 有订阅者时安排同步的间隔, 用于发现判题服务直接写入的变化
+	rankingVersionKeyExpire = 8 * time.Hour
+)
+
+// GetRankingVersion 获取排行榜当前版本
+func (s *RankingServiceImpl) GetRankingVersion(ctx context.Context, competitionID uint64) (int64, error) {
+	version, err := s.rdb.Get(ctx, fmt.Sprintf(RankingVersionKey, competitionID)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("GetRankingVersion failed: %w", err)
+	}
+	return version, nil
+}
+
+// syncRankingVersion 将当前排行榜与上一版本逐行比较, 有变化时递增版本号、记录变更日志并发布通知.
+// 排行榜由判题服务直接写入 Redis, 因此通过比较可见结果而不是拦截写操作来发现变化
+func (s *RankingServiceImpl) syncRankingVersion(ctx context.Context, competitionID uint64) (int64, error) {
+	// 同一比赛同一时间只允许一个同步, 同时限制同步频率
+	locked, err := s.rdb.SetNX(ctx, fmt.Sprintf(RankingSyncLockKey, competitionID), 1, rankingSyncInterval).Result()
+	if err != nil {
+		return 0, fmt.Errorf("acquire ranking sync lock failed: %w", err)
+	}
+	if !locked {
+		return s.GetRankingVersion(ctx, competitionID)
+	}
+
+	rankings, _, err := s.GetCompetitionRankingList(ctx, competitionID, 1, math.MaxInt32, nil)
+	if err != nil {
+		return 0, fmt.Errorf("get ranking list failed: %w", err)
+	}
+
+	rowsKey := fmt.Sprintf(RankingRowsKey, competitionID)
+	previous, err := s.rdb.HGetAll(ctx, rowsKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("get ranking rows from redis failed: %w", err)
+	}
+
+	changed := make([]model.Ranking, 0)
+	changedValues := make([]any, 0)
+	for _, ranking := range rankings {
+		userIDStr := strconv.FormatUint(ranking.UserID, 10)
+		rowBytes, err := json.Marshal(ranking)
+		if err != nil {
+			return 0, fmt.Errorf("marshal ranking row failed: %w", err)
+		}
+		if previous[userIDStr] != string(rowBytes) {
+			changed = append(changed, ranking)
+			changedValues = append(changedValues, userIDStr, string(rowBytes))
+		}
+		delete(previous, userIDStr)
+	}
+	removed := make([]uint64, 0, len(previous))
+	removedFields := make([]string, 0, len(previous))
+	for userIDStr := range previous {
+		userID, _ := strconv.ParseUint(userIDStr, 10, 64)
+		removed = append(removed, userID)
+		removedFields = append(removedFields, userIDStr)
+	}
+	if len(changed) == 0 && len(removed) == 0 {
+		return s.GetRankingVersion(ctx, competitionID)
+	}
+
+	versionKey := fmt.Sprintf(RankingVersionKey, competitionID)
+	version, err := s.rdb.Incr(ctx, versionKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("incr ranking version failed: %w", err)
+	}
+	changeLogBytes, err := json.Marshal(model.RankingChangeLog{
+		Version: version,
+		Rows:    changed,
+		Removed: removed,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("marshal ranking change log failed: %w", err)
+	}
+
+	changeLogKey := fmt.Sprintf(RankingChangeLogKey, competitionID)
+	pipeline := s.rdb.TxPipeline()
+	if len(changedValues) != 0 {
+		pipeline.HSet(ctx, rowsKey, changedValues...)
+	}
+	if len(removedFields) != 0 {
+		pipeline.HDel(ctx, rowsKey, removedFields...)
+	}
+	pipeline.RPush(ctx, changeLogKey, changeLogBytes)
+	pipeline.LTrim(ctx, changeLogKey, -rankingChangeLogSize, -1)
+	pipeline.Expire(ctx, rowsKey, rankingVersionKeyExpire)
+	pipeline.Expire(ctx, changeLogKey, rankingVersionKeyExpire)
+	pipeline.Expire(ctx, versionKey, rankingVersionKeyExpire)
+	pipeline.Publish(ctx, fmt.Sprintf(constants.RedisPubSubRankingVersionEventKey, competitionID), version)
+	if _, err = pipeline.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("save ranking change log failed: %w", err)
+	}
+	return version, nil
+}
+
+// notifyRankingChanged 排行榜发生变化后异步同步版本.
+// 同一比赛同一时间只安排一次同步, 同步间隔内的多次判题合并为一次重新计算
+func (s *RankingServiceImpl) notifyRankingChanged(ctx context.Context, competitionID uint64) {
+	if _, scheduled := s.pendingSync.LoadOrStore(competitionID, struct{}{}); scheduled {
+		return
+	}
+	syncCtx := context.WithValue(context.Background(), loggerv2.FieldsKey, ctx.Value(loggerv2.FieldsKey))
+	go func() {
+		// 等待同步间隔, 避免被上一次同步的限频吞掉; 同步开始前移除标记, 同步期间的变化会安排下一次同步
+		time.Sleep(rankingSyncInterval)
+		s.pendingSync.Delete(competitionID)
+		if _, err := s.syncRankingVersion(syncCtx, competitionID); err != nil {
+			s.log.ErrorContext(syncCtx, "sync ranking version failed", logger.Error(err))
+		}
+	}()
+}
+
+// GetCompetitionRankingDiff 获取给定版本之后排行榜发生变化的行
+func (s *RankingServiceImpl) GetCompetitionRankingDiff(ctx context.Context, competitionID uint64, version int64) (*model.RankingDiff, error) {
+	current, err := s.syncRankingVersion(ctx, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("GetCompetitionRankingDiff failed: %w", err)
+	}
+	diff, err := s.getRankingDiff(ctx, competitionID, version, current)
+	if err != nil {
+		return nil, fmt.Errorf("GetCompetitionRankingDiff failed: %w", err)
+	}
+	return diff, nil
+}
+
+// getRankingDiff 根据变更日志合并 version 之后到 current 的差异, 不触发同步
+func (s *RankingServiceImpl) getRankingDiff(ctx context.Context, competitionID uint64, version, current int64) (*model.RankingDiff, error) {
+	diff := &model.RankingDiff{
+		Version: current,
+		Rows:    make([]model.Ranking, 0),
+		Removed: make([]uint64, 0),
+	}
+	if version == current {
+		return diff, nil
+	}
+
+	changeLogs, err := s.getRankingChangeLogs(ctx, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	// 客户端版本早于保留的最早变更日志, 或客户端版本比服务端更新 ( 如 Redis 数据被重建 ), 返回完整排行榜
+	if version == 0 || version > current || len(changeLogs) == 0 || changeLogs[0].Version > version+1 {
+		diff.Full = true
+		diff.Rows, err = s.getRankingRows(ctx, competitionID)
+		if err != nil {
+			return nil, err
+		}
+		return diff, nil
+	}
+
+	// 按版本顺序合并变更, 同一用户以最后一次变更为准
+	rows := make(map[uint64]model.Ranking)
+	removed := make(map[uint64]struct{})
+	for _, changeLog := range changeLogs {
+		if changeLog.Version <= version || changeLog.Version > current {
+			continue
+		}
+		for _, row := range changeLog.Rows {
+			rows[row.UserID] = row
+			delete(removed, row.UserID)
+		}
+		for _, userID := range changeLog.Removed {
+			removed[userID] = struct{}{}
+			delete(rows, userID)
+		}
+	}
+	for _, row := range rows {
+		diff.Rows = append(diff.Rows, row)
+	}
+	for userID := range removed {
+		diff.Removed = append(diff.Removed, userID)
+	}
+	return diff, nil
+}
+
+// getRankingChangeLogs 获取保留的全部变更日志, 按版本升序
+func (s *RankingServiceImpl) getRankingChangeLogs(ctx context.Context, competitionID uint64) ([]model.RankingChangeLog, error) {
+	changeLogStrs, err := s.rdb.LRange(ctx, fmt.Sprintf(RankingChangeLogKey, competitionID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get ranking change log from redis failed: %w", err)
+	}
+	changeLogs := make([]model.RankingChangeLog, 0, len(changeLogStrs))
+	for _, changeLogStr := range changeLogStrs {
+		var changeLog model.RankingChangeLog
+		if err = json.Unmarshal([]byte(changeLogStr), &changeLog); err != nil {
+			return nil, fmt.Errorf("unmarshal ranking change log failed: %w", err)
+		}
+		changeLogs = append(changeLogs, changeLog)
+	}
+	return changeLogs, nil
+}
+
+// getRankingRows 获取最近一次同步时的完整排行榜
+func (s *RankingServiceImpl) getRankingRows(ctx context.Context, competitionID uint64) ([]model.Ranking, error) {
+	rowStrs, err := s.rdb.HVals(ctx, fmt.Sprintf(RankingRowsKey, competitionID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get ranking rows from redis failed: %w", err)
+	}
+	rows := make([]model.Ranking, 0, len(rowStrs))
+	for _, rowStr := range rowStrs {
+		var row model.Ranking
+		if err = json.Unmarshal([]byte(rowStr), &row); err != nil {
+			return nil, fmt.Errorf("unmarshal ranking row failed: %w", err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// SubscribeRankingDiff 订阅排行榜变化, 从客户端给定的版本开始推送差异.
+// 订阅者只读取变更日志, 重新计算排行榜由 notifyRankingChanged 按比赛合并执行
+func (s *RankingServiceImpl) SubscribeRankingDiff(ctx context.Context, competitionID uint64, version int64) chan *model.RankingDiff {
+	ch := make(chan *model.RankingDiff, 1)
+	uc, ok := s.rdb.(redis.UniversalClient)
+	if !ok {
+		s.log.ErrorContext(ctx, "SubscribeRankingDiff: redis cmdable not universal client")
+		close(ch)
+		return ch
+	}
+	go func() {
+		pubsub := uc.Subscribe(ctx, fmt.Sprintf(constants.RedisPubSubRankingVersionEventKey, competitionID))
+		ticker := time.NewTicker(rankingEventSyncTicker)
+		defer pubsub.Close()
+		defer ticker.Stop()
+		defer close(ch)
+
+		push := func() bool {
+			current, err := s.GetRankingVersion(ctx, competitionID)
+			if err != nil {
+				s.log.ErrorContext(ctx, "SubscribeRankingDiff: get ranking version failed", logger.Error(err))
+				return true
+			}
+			diff, err := s.getRankingDiff(ctx, competitionID, version, current)
+			if err != nil {
+				s.log.ErrorContext(ctx, "SubscribeRankingDiff: get ranking diff failed", logger.Error(err))
+				return true
+			}
+			if diff.Version == version && !diff.Full {
+				return true
+			}
+			version = diff.Version
+			select {
+			case ch <- diff:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		s.notifyRankingChanged(ctx, competitionID)
+		if !push() {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				s.log.InfoContext(ctx, "SubscribeRankingDiff: client closed")
+				return
+			case _, ok := <-pubsub.Channel():
+				if !ok {
+					return
+				}
+				if !push() {
+					return
+				}
+			case <-ticker.C:
+				// 判题服务直接写入排行榜时不会发布通知, 定时安排同步以发现变化, 有变化时通过发布通知推送
+				s.notifyRankingChanged(ctx, competitionID)
+			}
+		}
+	}()
+	return ch
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -10,6 +11,7 @@ import (
 	ojmodel "github.com/to404hanga/online_judge_common/model"
 	ojconstants "github.com/to404hanga/online_judge_common/proto/constants"
 	pbsubmission "github.com/to404hanga/online_judge_common/proto/gen/submission"
+	"github.com/to404hanga/online_judge_controller/constants"
 	"github.com/to404hanga/online_judge_controller/event"
 	"github.com/to404hanga/online_judge_controller/model"
 	"github.com/to404hanga/online_judge_controller/pkg/pointer"
@@ -21,12 +23,38 @@ import (
 type SubmissionService interface {
 	// SubmitCompetitionProblem 提交比赛题目
 	SubmitCompetitionProblem(ctx context.Context, param *model.SubmitCompetitionProblemParam) error
+	// TakeSubmitToken 按比赛设置的提交频率限制扣减令牌, 被限流时返回需要等待的时间
+	TakeSubmitToken(ctx context.Context, competitionID, problemID, userID uint64) (*SubmitRateLimit, error)
+	// TakeRunToken 按比赛设置的自定义输入运行频率限制扣减令牌, 被限流时返回需要等待的时间
+	TakeRunToken(ctx context.Context, competitionID, userID uint64) (*SubmitRateLimit, error)
+	// CreateCustomRun 创建自定义输入运行, 不写入提交记录也不影响排行榜, 返回运行 ID
+	CreateCustomRun(ctx context.Context, param *model.CreateCustomRunParam) (string, error)
+	// GetCustomRun 获取自定义输入运行记录
+	GetCustomRun(ctx context.Context, competitionID, userID uint64, runID string) (*model.CustomRun, error)
+	// SubscribeCustomRun 订阅自定义输入运行结果
+	SubscribeCustomRun(ctx context.Context, competitionID, userID uint64, runID string) chan *model.CustomRun
+	// CheckDuplicateSubmission 按比赛设置检查选手是否重复提交相同代码, 返回比赛的处理方式与相同代码的已判题提交
+	CheckDuplicateSubmission(ctx context.Context, param *model.SubmitCompetitionProblemParam) (model.DuplicateSubmitMode, *model.DuplicateSubmission, error)
+	// HandleJudgeReportMessage 消费判题服务投递的详细判题报告
+	HandleJudgeReportMessage(ctx context.Context, msg *sarama.ConsumerMessage) error
+	// GetJudgeReport 获取提交的完整判题报告
+	GetJudgeReport(ctx context.Context, submissionID uint64) (*model.JudgeReport, error)
+	// UserGetJudgeReport 按比赛设置获取选手可见的判题报告
+	UserGetJudgeReport(ctx context.Context, competitionID, userID, submissionID uint64) (*model.UserJudgeReport, error)
 	// GetLatestSubmission 获取最新提交记录
 	GetLatestSubmission(ctx context.Context, competitionID, problemID, userID uint64) (*ojmodel.Submission, error)
 	// GetSubmissionByID 获取提交记录
 	GetSubmissionByID(ctx context.Context, submissionID uint64) (*ojmodel.Submission, error)
 	// CleanUserFailedSubmission 清理给定截止时间之前所有用户的失败提交记录(仅清理提交代码)
 	CleanUserFailedSubmission(ctx context.Context, timeDeadline time.Time) error
+	// GetSubmissionList 按条件分页查询提交记录, 不返回代码
+	GetSubmissionList(ctx context.Context, param *model.GetSubmissionListParam) ([]model.SubmissionListItem, int, error)
+	// GetSubmissionDetail 获取提交详情, 包含代码与错误输出
+	GetSubmissionDetail(ctx context.Context, submissionID uint64) (*model.SubmissionDetail, error)
+	// GetSubmissionDiff 对比两次提交的代码, 代码已被清理时拒绝对比
+	GetSubmissionDiff(ctx context.Context, leftID, rightID uint64, option *model.SubmissionDiffOption) (*model.SubmissionDiff, error)
+	// UserGetSubmissionDiff 对比选手自己在当前比赛中的两次提交
+	UserGetSubmissionDiff(ctx context.Context, competitionID, userID, leftID, rightID uint64, option *model.SubmissionDiffOption) (*model.SubmissionDiff, error)
 }
 
 type SubmissionServiceImpl struct {
@@ -62,12 +90,46 @@ func (s *SubmissionServiceImpl) SubmitCompetitionProblem(ctx context.Context, pa
 		CreatedAt:     time.Now(),        // 立即生成提交时间
 	}
 
-	err := s.db.WithContext(ctx).Create(&submission).Error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&submission).Error; err != nil {
+			return fmt.Errorf("create submission failed: %w", err)
+		}
+		// 保存规范化代码的摘要, 用于重复提交检查与查重预筛
+		err := tx.Create(&model.SubmissionCodeHash{
+			SubmissionID:  submission.ID,
+			CompetitionID: param.CompetitionID,
+			ProblemID:     param.ProblemID,
+			UserID:        param.Operator,
+			Language:      param.Language,
+			CodeHash:      submissionCodeHash(param.Code, param.Language),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("create submission_code_hash failed: %w", err)
+		}
+		if !param.Upsolve {
+			return nil
+		}
+		// 赛后补题提交单独标记, 不计入正式排行榜
+		err = tx.Create(&model.UpsolveSubmission{
+			SubmissionID:  submission.ID,
+			CompetitionID: param.CompetitionID,
+			ProblemID:     param.ProblemID,
+			UserID:        param.Operator,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("create submission_upsolve failed: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("SubmitCompetitionProblem failed at create submission: %w", err)
+		return fmt.Errorf("SubmitCompetitionProblem failed: %w", err)
 	}
 
-	// 通过 kafka 发布提交任务
+	// 通过 kafka 发布提交任务, 语言倍率通过消息头下发
+	language, err := loadLanguage(ctx, s.db, s.rdb, param.Language)
+	if err != nil {
+		return fmt.Errorf("SubmitCompetitionProblem failed at load language: %w", err)
+	}
 	requestID, _ := ctx.Value("request_id").(string)
 	msg := &pbsubmission.Submission{
 		SubmissionId: submission.ID,
@@ -79,6 +141,10 @@ func (s *SubmissionServiceImpl) SubmitCompetitionProblem(ctx context.Context, pa
 	}
 	_, _, err = s.kafka.Produce(ctx, &sarama.ProducerMessage{
 		Topic: ojconstants.SubmissionTopic,
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(constants.SubmissionTimeMultiplierHeader), Value: []byte(strconv.FormatFloat(language.TimeMultiplier, 'f', -1, 64))},
+			{Key: []byte(constants.SubmissionMemoryMultiplierHeader), Value: []byte(strconv.FormatFloat(language.MemoryMultiplier, 'f', -1, 64))},
+		},
 		Value: sarama.ByteEncoder(val),
 	})
 	if err != nil {
@@ -118,7 +184,7 @@ func (s *SubmissionServiceImpl) CleanUserFailedSubmission(ctx context.Context, t
 	err := s.db.WithContext(ctx).Model(&ojmodel.Submission{}).
 		Where("result != ?", ojmodel.SubmissionResultAccepted).
 		Where("created_at < ?", timeDeadline).
-		UpdateColumn("code", "**代码已被清理**").Error
+		UpdateColumn("code", model.CleanedSubmissionCode).Error
 	if err != nil {
 		return fmt.Errorf("CleanUserFailedSubmission failed at update submission: %w", err)
 	}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/to404hanga/online_judge_controller/model"
+	"gorm.io/gorm"
+)
+
+const submissionListColumns = "s.id, s.competition_id, s.problem_id, s.user_id, " +
+	"IFNULL(u.username, '') AS username, IFNULL(u.realname, '') AS realname, " +
+	"s.language, s.status, s.result, IFNULL(s.time_used, -1) AS time_used, IFNULL(s.memory_used, -1) AS memory_used, " +
+	"CHAR_LENGTH(s.code) AS code_length, IFNULL(h.code_hash, '') AS code_hash, s.created_at"
+
+// submissionQuery 提交记录与用户信息的联表查询
+func (s *SubmissionServiceImpl) submissionQuery(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).
+		Table("submission s").
+		Joins("LEFT JOIN user u ON u.id = s.user_id").
+		Joins("LEFT JOIN submission_code_hash h ON h.submission_id = s.id")
+}
+
+// GetSubmissionList 按条件分页查询提交记录, 不返回代码
+func (s *SubmissionServiceImpl) GetSubmissionList(ctx context.Context, param *model.GetSubmissionListParam) ([]model.SubmissionListItem, int, error) {
+	query := s.submissionQuery(ctx)
+	if param.CompetitionID != nil {
+		query = query.Where("s.competition_id = ?", *param.CompetitionID)
+	}
+	if param.UserID != nil {
+		query = query.Where("s.user_id = ?", *param.UserID)
+	}
+	if param.ProblemID != nil {
+		query = query.Where("s.problem_id = ?", *param.ProblemID)
+	}
+	if param.Language != nil {
+		query = query.Where("s.language = ?", *param.Language)
+	}
+	if param.Result != nil {
+		query = query.Where("s.result = ?", *param.Result)
+	}
+	if param.CodeHash != "" {
+		query = query.Where("h.code_hash = ?", param.CodeHash)
+	}
+	if param.StartTime != nil {
+		query = query.Where("s.created_at >= ?", *param.StartTime)
+	}
+	if param.EndTime != nil {
+		query = query.Where("s.created_at < ?", *param.EndTime)
+	}
+
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetSubmissionList failed at count: %w", err)
+	}
+
+	var list []model.SubmissionListItem
+	err = query.Select(submissionListColumns).
+		Order("s.id DESC").
+		Offset((param.Page - 1) * param.PageSize).
+		Limit(param.PageSize).
+		Scan(&list).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetSubmissionList failed at select: %w", err)
+	}
+	return list, int(total), nil
+}
+
+// GetSubmissionDetail 获取提交详情, 包含代码与错误输出
+func (s *SubmissionServiceImpl) GetSubmissionDetail(ctx context.Context, submissionID uint64) (*model.SubmissionDetail, error) {
+	var details []model.SubmissionDetail
+	err := s.submissionQuery(ctx).
+		Select(submissionListColumns+", s.code, IFNULL(s.stderr, '') AS stderr").
+		Where("s.id = ?", submissionID).
+		Limit(1).
+		Scan(&details).Error
+	if err != nil {
+		return nil, fmt.Errorf("GetSubmissionDetail failed at select: %w", err)
+	}
+	if len(details) == 0 {
+		return nil, fmt.Errorf("GetSubmissionDetail failed: %w", ErrSubmissionNotFound)
+	}
+	detail := &details[0]
+	detail.Cleaned = detail.Code == model.CleanedSubmissionCode
+	return detail, nil
+}
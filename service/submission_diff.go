@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/textdiff"
+)
+
+const defaultSubmissionDiffContext = 3 // 差异中每处修改前后默认保留的相同行数
+
+var ErrSubmissionCodeCleaned = errors.New("submission code has been cleaned")
+
+// GetSubmissionDiff 对比两次提交的代码, 任一提交的代码已被清理时返回 ErrSubmissionCodeCleaned
+func (s *SubmissionServiceImpl) GetSubmissionDiff(ctx context.Context, leftID, rightID uint64, option *model.SubmissionDiffOption) (*model.SubmissionDiff, error) {
+	left, err := s.GetSubmissionDetail(ctx, leftID)
+	if err != nil {
+		return nil, fmt.Errorf("GetSubmissionDiff failed: %w", err)
+	}
+	right, err := s.GetSubmissionDetail(ctx, rightID)
+	if err != nil {
+		return nil, fmt.Errorf("GetSubmissionDiff failed: %w", err)
+	}
+	return diffSubmission(left, right, option)
+}
+
+// UserGetSubmissionDiff 对比选手自己在当前比赛中的两次提交
+func (s *SubmissionServiceImpl) UserGetSubmissionDiff(ctx context.Context, competitionID, userID, leftID, rightID uint64, option *model.SubmissionDiffOption) (*model.SubmissionDiff, error) {
+	left, err := s.GetSubmissionDetail(ctx, leftID)
+	if err == nil && (left.UserID != userID || left.CompetitionID != competitionID) {
+		// 不暴露他人提交是否存在
+		err = ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("UserGetSubmissionDiff failed: %w", err)
+	}
+	right, err := s.GetSubmissionDetail(ctx, rightID)
+	if err == nil && (right.UserID != userID || right.CompetitionID != competitionID) {
+		err = ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("UserGetSubmissionDiff failed: %w", err)
+	}
+	return diffSubmission(left, right, option)
+}
+
+// diffSubmission 按选项生成两份代码的统一格式差异, 两侧任一为 Python 时保留行首缩进的比较;
+// 代码超过对比上限时返回 textdiff.ErrTooLarge
+func diffSubmission(left, right *model.SubmissionDetail, option *model.SubmissionDiffOption) (*model.SubmissionDiff, error) {
+	if left.Cleaned || right.Cleaned {
+		return nil, fmt.Errorf("diffSubmission failed: %w", ErrSubmissionCodeCleaned)
+	}
+
+	opts := textdiff.Options{
+		IgnoreTrailingSpace: option.IgnoreTrailingSpace,
+		IgnoreSpaceChange:   option.IgnoreSpaceChange,
+		IgnoreAllSpace:      option.IgnoreAllSpace,
+		KeepIndent: ojmodel.SubmissionLanguage(left.Language) == ojmodel.SubmissionLanguagePython ||
+			ojmodel.SubmissionLanguage(right.Language) == ojmodel.SubmissionLanguagePython,
+	}
+	diffContext := defaultSubmissionDiffContext
+	if option.Context != nil {
+		diffContext = *option.Context
+	}
+
+	rows, err := textdiff.CompareWithOptions(textdiff.SplitLines(left.Code), textdiff.SplitLines(right.Code), opts)
+	if err != nil {
+		return nil, fmt.Errorf("diffSubmission failed: %w", err)
+	}
+	diff := textdiff.Unified(rows,
+		fmt.Sprintf("%d%s", left.ID, model.SubmissionFileExt(left.Language)),
+		fmt.Sprintf("%d%s", right.ID, model.SubmissionFileExt(right.Language)),
+		diffContext)
+	return &model.SubmissionDiff{
+		Left:      left.SubmissionListItem,
+		Right:     right.SubmissionListItem,
+		Identical: diff == "",
+		Diff:      diff,
+	}, nil
+}
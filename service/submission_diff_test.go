@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/textdiff"
+)
+
+func TestDiffSubmission(t *testing.T) {
+	detail := func(id uint64, language ojmodel.SubmissionLanguage, code string) *model.SubmissionDetail {
+		return &model.SubmissionDetail{
+			SubmissionListItem: model.SubmissionListItem{ID: id, Language: language.Int8()},
+			Code:               code,
+		}
+	}
+	cleaned := detail(2, ojmodel.SubmissionLanguageCPP, "")
+	cleaned.Cleaned = true
+	tooLarge := strings.Repeat("x\n", textdiff.MaxLines+1)
+
+	tests := []struct {
+		name          string
+		left, right   *model.SubmissionDetail
+		option        model.SubmissionDiffOption
+		wantIdentical bool
+		wantDiff      string
+		wantErr       error
+	}{
+		{
+			name:     "代码不同",
+			left:     detail(1, ojmodel.SubmissionLanguageCPP, "int a;\n"),
+			right:    detail(2, ojmodel.SubmissionLanguageCPP, "int b;\n"),
+			wantDiff: "--- 1.cpp\n+++ 2.cpp\n@@ -1 +1 @@\n-int a;\n+int b;\n",
+		},
+		{
+			name:          "忽略空白后相同",
+			left:          detail(1, ojmodel.SubmissionLanguageCPP, "int a = 1;\n"),
+			right:         detail(2, ojmodel.SubmissionLanguageCPP, "int a=1;\n"),
+			option:        model.SubmissionDiffOption{IgnoreAllSpace: true},
+			wantIdentical: true,
+		},
+		{
+			name:     "Python 始终比较缩进",
+			left:     detail(1, ojmodel.SubmissionLanguagePython, "    return x\n"),
+			right:    detail(2, ojmodel.SubmissionLanguagePython, "return x\n"),
+			option:   model.SubmissionDiffOption{IgnoreAllSpace: true},
+			wantDiff: "--- 1.py\n+++ 2.py\n@@ -1 +1 @@\n-    return x\n+return x\n",
+		},
+		{
+			name:    "代码已清理",
+			left:    detail(1, ojmodel.SubmissionLanguageCPP, "int a;\n"),
+			right:   cleaned,
+			wantErr: ErrSubmissionCodeCleaned,
+		},
+		{
+			name:    "超过对比上限",
+			left:    detail(1, ojmodel.SubmissionLanguageCPP, tooLarge),
+			right:   detail(2, ojmodel.SubmissionLanguageCPP, "int a;\n"),
+			wantErr: textdiff.ErrTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diffSubmission(tt.left, tt.right, &tt.option)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("diffSubmission() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Identical != tt.wantIdentical || got.Diff != tt.wantDiff {
+				t.Errorf("diffSubmission() = (%v, %q), want (%v, %q)", got.Identical, got.Diff, tt.wantIdentical, tt.wantDiff)
+			}
+		})
+	}
+}
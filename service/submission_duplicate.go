@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/plagiarism"
+	"github.com/to404hanga/online_judge_controller/pkg/pointer"
+)
+
+// submissionCodeHash 计算提交代码规范化后的摘要
+func submissionCodeHash(code string, language int8) string {
+	return plagiarism.CodeHash(code, plagiarismSyntaxOf(pointer.ToPtr(ojmodel.SubmissionLanguage(language))))
+}
+
+// CheckDuplicateSubmission 按比赛设置检查选手是否重复提交与之前已判题提交相同的代码
+// 未开启检查时返回 DuplicateSubmitModeOff, 没有相同代码时返回的提交为 nil
+func (s *SubmissionServiceImpl) CheckDuplicateSubmission(ctx context.Context, param *model.SubmitCompetitionProblemParam) (model.DuplicateSubmitMode, *model.DuplicateSubmission, error) {
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, param.CompetitionID)
+	if err != nil {
+		return model.DuplicateSubmitModeOff, nil, fmt.Errorf("CheckDuplicateSubmission failed: %w", err)
+	}
+	if setting.DuplicateSubmitMode == model.DuplicateSubmitModeOff {
+		return model.DuplicateSubmitModeOff, nil, nil
+	}
+
+	var duplicates []model.DuplicateSubmission
+	err = s.db.WithContext(ctx).
+		Table("submission_code_hash h").
+		Joins("JOIN submission s ON s.id = h.submission_id").
+		Select("h.submission_id, s.result, s.created_at").
+		Where("h.competition_id = ?", param.CompetitionID).
+		Where("h.problem_id = ?", param.ProblemID).
+		Where("h.user_id = ?", param.Operator).
+		Where("h.code_hash = ?", submissionCodeHash(param.Code, param.Language)).
+		Where("h.language = ?", param.Language).
+		Where("s.status = ?", ojmodel.SubmissionStatusJudged).
+		Order("h.submission_id DESC").
+		Limit(1).
+		Scan(&duplicates).Error
+	if err != nil {
+		return setting.DuplicateSubmitMode, nil, fmt.Errorf("CheckDuplicateSubmission failed at select from submission_code_hash: %w", err)
+	}
+	if len(duplicates) == 0 {
+		return setting.DuplicateSubmitMode, nil, nil
+	}
+	return setting.DuplicateSubmitMode, &duplicates[0], nil
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+)
+
+func TestSubmissionCodeHash(t *testing.T) {
+	const code = "int main() { return 0; } // done"
+	tests := []struct {
+		name          string
+		language      ojmodel.SubmissionLanguage
+		other         string
+		otherLanguage ojmodel.SubmissionLanguage
+		same          bool
+	}{
+		{"C 与 C++ 使用同一语法", ojmodel.SubmissionLanguageC, code, ojmodel.SubmissionLanguageCPP, true},
+		{"只改注释", ojmodel.SubmissionLanguageCPP, "int main() { return 0; } /* done */", ojmodel.SubmissionLanguageCPP, true},
+		// Python 中 // 不是注释, 注释内容参与摘要
+		{"不同语法族按各自规则规范化", ojmodel.SubmissionLanguageC, code, ojmodel.SubmissionLanguagePython, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := submissionCodeHash(code, tt.language.Int8()) == submissionCodeHash(tt.other, tt.otherLanguage.Int8())
+			if got != tt.same {
+				t.Errorf("submissionCodeHash() same = %v, want %v", got, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/to404hanga/online_judge_controller/model"
+)
+
+//go:embed lua/take_submit_token.lua
+var takeSubmitTokenScript string
+
+const (
+	submitIntervalBucketKey     = "submission:competition:%d:user:%d:problem:%d:interval"
+	userSubmitLimitBucketKey    = "submission:competition:%d:user:%d:limit"
+	problemSubmitLimitBucketKey = "submission:competition:%d:user:%d:problem:%d:limit"
+)
+
+// 触发限流的规则
+const (
+	SubmitLimitScopeInterval = "interval" // 同一题目的最小提交间隔
+	SubmitLimitScopeUser     = "user"     // 统计窗口内全部题目的提交次数
+	SubmitLimitScopeProblem  = "problem"  // 统计窗口内同一题目的提交次数
+)
+
+// SubmitRateLimit 提交限流结果, RetryAfter 为 0 表示允许提交
+type SubmitRateLimit struct {
+	RetryAfter time.Duration
+	Scope      string
+}
+
+// TakeSubmitToken 按比赛设置对提交进行令牌桶限流, 所有规则均满足时扣减令牌并允许提交
+func (s *SubmissionServiceImpl) TakeSubmitToken(ctx context.Context, competitionID, problemID, userID uint64) (*SubmitRateLimit, error) {
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("TakeSubmitToken failed: %w", err)
+	}
+
+	keys, args, scopes := submitTokenBuckets(setting, competitionID, problemID, userID)
+	limit, err := s.takeTokens(ctx, keys, args, scopes)
+	if err != nil {
+		return nil, fmt.Errorf("TakeSubmitToken failed: %w", err)
+	}
+	return limit, nil
+}
+
+// takeTokens 原子地检查并扣减一组令牌桶, 任一桶没有令牌时均不扣减并返回最长的等待时间
+func (s *SubmissionServiceImpl) takeTokens(ctx context.Context, keys []string, args []any, scopes []string) (*SubmitRateLimit, error) {
+	if len(keys) == 0 {
+		return &SubmitRateLimit{}, nil
+	}
+
+	res, err := s.rdb.Eval(ctx, takeSubmitTokenScript, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("eval take token script failed: %w", err)
+	}
+	if len(res) != 2 || res[0] <= 0 {
+		return &SubmitRateLimit{}, nil
+	}
+	limit := &SubmitRateLimit{
+		RetryAfter: time.Duration(res[0]) * time.Millisecond,
+	}
+	if idx := int(res[1]) - 1; idx >= 0 && idx < len(scopes) {
+		limit.Scope = scopes[idx]
+	}
+	return limit, nil
+}
+
+// submitTokenBuckets 根据比赛设置生成需要检查的令牌桶, 最小间隔等价于容量为 1 的令牌桶
+func submitTokenBuckets(setting *model.CompetitionSetting, competitionID, problemID, userID uint64) ([]string, []any, []string) {
+	var (
+		keys   []string
+		args   []any
+		scopes []string
+	)
+	add := func(key string, capacity int, interval int64, scope string) {
+		keys = append(keys, key)
+		args = append(args, strconv.Itoa(capacity), strconv.FormatInt(interval, 10))
+		scopes = append(scopes, scope)
+	}
+
+	if setting.SubmitInterval > 0 {
+		add(fmt.Sprintf(submitIntervalBucketKey, competitionID, userID, problemID), 1, int64(setting.SubmitInterval)*1000, SubmitLimitScopeInterval)
+	}
+	if windowMs := setting.SubmitWindowMs(); windowMs > 0 {
+		if setting.UserSubmitLimit > 0 {
+			add(fmt.Sprintf(userSubmitLimitBucketKey, competitionID, userID), setting.UserSubmitLimit, max(windowMs/int64(setting.UserSubmitLimit), 1), SubmitLimitScopeUser)
+		}
+		if setting.ProblemSubmitLimit > 0 {
+			add(fmt.Sprintf(problemSubmitLimitBucketKey, competitionID, userID, problemID), setting.ProblemSubmitLimit, max(windowMs/int64(setting.ProblemSubmitLimit), 1), SubmitLimitScopeProblem)
+		}
+	}
+	return keys, args, scopes
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/online_judge_controller/model"
+)
+
+// evalRedis 只实现 Eval 的 Redis 替身, 记录调用参数并返回给定结果
+type evalRedis struct {
+	redis.Cmdable
+	result any
+	err    error
+	keys   []string
+	args   []any
+}
+
+func (r *evalRedis) Eval(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+	r.keys, r.args = keys, args
+	return redis.NewCmdResult(r.result, r.err)
+}
+
+func TestSubmitTokenBuckets(t *testing.T) {
+	tests := []struct {
+		name       string
+		setting    model.CompetitionSetting
+		wantKeys   []string
+		wantArgs   []any
+		wantScopes []string
+	}{
+		{
+			name: "不限制",
+		},
+		{
+			name:       "只限制提交间隔",
+			setting:    model.CompetitionSetting{SubmitInterval: 10},
+			wantKeys:   []string{"submission:competition:1:user:3:problem:2:interval"},
+			wantArgs:   []any{"1", "10000"},
+			wantScopes: []string{SubmitLimitScopeInterval},
+		},
+		{
+			name:    "没有统计窗口时忽略次数限制",
+			setting: model.CompetitionSetting{UserSubmitLimit: 10, ProblemSubmitLimit: 5},
+		},
+		{
+			name:    "全部规则",
+			setting: model.CompetitionSetting{SubmitInterval: 5, SubmitWindow: 10, UserSubmitLimit: 60, ProblemSubmitLimit: 20},
+			wantKeys: []string{
+				"submission:competition:1:user:3:problem:2:interval",
+				"submission:competition:1:user:3:limit",
+				"submission:competition:1:user:3:problem:2:limit",
+			},
+			wantArgs:   []any{"1", "5000", "60", "10000", "20", "30000"},
+			wantScopes: []string{SubmitLimitScopeInterval, SubmitLimitScopeUser, SubmitLimitScopeProblem},
+		},
+		{
+			name:       "次数超过窗口毫秒数时补充间隔至少为 1 毫秒",
+			setting:    model.CompetitionSetting{SubmitWindow: 1, UserSubmitLimit: 10000000},
+			wantKeys:   []string{"submission:competition:1:user:3:limit"},
+			wantArgs:   []any{"10000000", "1"},
+			wantScopes: []string{SubmitLimitScopeUser},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, args, scopes := submitTokenBuckets(&tt.setting, 1, 2, 3)
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("keys = %q, want %q", keys, tt.wantKeys)
+			}
+			if !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+			if !slices.Equal(scopes, tt.wantScopes) {
+				t.Errorf("scopes = %q, want %q", scopes, tt.wantScopes)
+			}
+		})
+	}
+}
+
+func TestTakeTokens(t *testing.T) {
+	keys := []string{"interval", "user", "problem"}
+	scopes := []string{SubmitLimitScopeInterval, SubmitLimitScopeUser, SubmitLimitScopeProblem}
+	tests := []struct {
+		name    string
+		keys    []string
+		result  any
+		err     error
+		want    *SubmitRateLimit
+		wantErr bool
+	}{
+		{"没有令牌桶时不访问 Redis", nil, nil, errors.New("unexpected eval"), &SubmitRateLimit{}, false},
+		{"允许提交", keys, []any{int64(0), int64(0)}, nil, &SubmitRateLimit{}, false},
+		{"触发用户次数限制", keys, []any{int64(1500), int64(2)}, nil, &SubmitRateLimit{RetryAfter: 1500 * time.Millisecond, Scope: SubmitLimitScopeUser}, false},
+		{"桶下标越界时不返回规则", keys, []any{int64(200), int64(9)}, nil, &SubmitRateLimit{RetryAfter: 200 * time.Millisecond}, false},
+		{"脚本返回格式异常时放行", keys, []any{int64(1)}, nil, &SubmitRateLimit{}, false},
+		{"Redis 错误", keys, nil, errors.New("connection refused"), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdb := &evalRedis{result: tt.result, err: tt.err}
+			s := &SubmissionServiceImpl{rdb: rdb}
+			got, err := s.takeTokens(context.Background(), tt.keys, nil, scopes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("takeTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && *got != *tt.want {
+				t.Errorf("takeTokens() = %+v, want %+v", *got, *tt.want)
+			}
+			if len(tt.keys) > 0 && !slices.Equal(rdb.keys, tt.keys) {
+				t.Errorf("eval keys = %q, want %q", rdb.keys, tt.keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/constants"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/logger"
+	"gorm.io/gorm"
+)
+
+const (
+	customRunKey           = "run:%s"
+	runIntervalBucketKey   = "run:competition:%d:user:%d:interval"
+	runLimitBucketKey      = "run:competition:%d:user:%d:limit"
+	customRunEventInterval = 3 * time.Second // 订阅运行结果时的兜底轮询间隔
+)
+
+var (
+	ErrCustomRunNotFound  = errors.New("custom run not found")
+	ErrCustomRunStdinSize = errors.New("stdin too large")
+)
+
+// TakeRunToken 按比赛设置对自定义输入运行进行令牌桶限流, 与正式提交的限流互相独立
+func (s *SubmissionServiceImpl) TakeRunToken(ctx context.Context, competitionID, userID uint64) (*SubmitRateLimit, error) {
+	setting, err := loadCompetitionSetting(ctx, s.db, s.rdb, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("TakeRunToken failed: %w", err)
+	}
+
+	var (
+		keys   []string
+		args   []any
+		scopes []string
+	)
+	if setting.RunInterval > 0 {
+		keys = append(keys, fmt.Sprintf(runIntervalBucketKey, competitionID, userID))
+		args = append(args, "1", strconv.FormatInt(int64(setting.RunInterval)*1000, 10))
+		scopes = append(scopes, SubmitLimitScopeInterval)
+	}
+	if windowMs := setting.RunWindowMs(); windowMs > 0 && setting.RunLimit > 0 {
+		keys = append(keys, fmt.Sprintf(runLimitBucketKey, competitionID, userID))
+		args = append(args, strconv.Itoa(setting.RunLimit), strconv.FormatInt(max(windowMs/int64(setting.RunLimit), 1), 10))
+		scopes = append(scopes, SubmitLimitScopeUser)
+	}
+
+	limit, err := s.takeTokens(ctx, keys, args, scopes)
+	if err != nil {
+		return nil, fmt.Errorf("TakeRunToken failed: %w", err)
+	}
+	return limit, nil
+}
+
+// CreateCustomRun 创建自定义输入运行, 运行记录仅保存在 Redis 中, 任务投递到独立的低优先级队列
+func (s *SubmissionServiceImpl) CreateCustomRun(ctx context.Context, param *model.CreateCustomRunParam) (string, error) {
+	if len(param.Stdin) > model.MaxCustomRunStdin {
+		return "", fmt.Errorf("CreateCustomRun failed: %w", ErrCustomRunStdinSize)
+	}
+
+	var problem ojmodel.Problem
+	err := s.db.WithContext(ctx).Model(&ojmodel.Problem{}).
+		Joins("JOIN competition_problem cp ON cp.problem_id = problem.id").
+		Where("cp.competition_id = ?", param.CompetitionID).
+		Where("cp.problem_id = ?", param.ProblemID).
+		Where("cp.status = ?", ojmodel.CompetitionProblemStatusEnabled).
+		Select("problem.id", "problem.time_limit", "problem.memory_limit").
+		First(&problem).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("CreateCustomRun failed: %w", ErrCompetitionProblemNotFound)
+	} else if err != nil {
+		return "", fmt.Errorf("CreateCustomRun failed at select from problem: %w", err)
+	}
+
+	language, err := loadLanguage(ctx, s.db, s.rdb, param.Language)
+	if err != nil {
+		return "", fmt.Errorf("CreateCustomRun failed at load language: %w", err)
+	}
+
+	runIDBytes := make([]byte, 16)
+	if _, err = rand.Read(runIDBytes); err != nil {
+		return "", fmt.Errorf("CreateCustomRun failed at generate run id: %w", err)
+	}
+	runID := hex.EncodeToString(runIDBytes)
+
+	run := model.CustomRun{
+		RunID:         runID,
+		CompetitionID: param.CompetitionID,
+		ProblemID:     param.ProblemID,
+		UserID:        param.Operator,
+		Language:      param.Language,
+		Status:        model.CustomRunStatusPending,
+		TimeUsed:      -1,
+		MemoryUsed:    -1,
+		CreatedAt:     time.Now(),
+	}
+	runBytes, err := json.Marshal(run)
+	if err != nil {
+		return "", fmt.Errorf("CreateCustomRun failed at marshal run: %w", err)
+	}
+	err = s.rdb.Set(ctx, fmt.Sprintf(customRunKey, runID), runBytes, model.CustomRunTTL).Err()
+	if err != nil {
+		return "", fmt.Errorf("CreateCustomRun failed at save run: %w", err)
+	}
+
+	requestID, _ := ctx.Value("request_id").(string)
+	task := model.CustomRunTask{
+		RunID:       runID,
+		ProblemID:   param.ProblemID,
+		Code:        param.Code,
+		Language:    param.Language,
+		Stdin:       param.Stdin,
+		TimeLimit:   int(float64(problem.TimeLimit) * language.TimeMultiplier),
+		MemoryLimit: int(float64(problem.MemoryLimit) * language.MemoryMultiplier),
+		RequestID:   requestID,
+	}
+	val, err := json.Marshal(task)
+	if err != nil {
+		return "", fmt.Errorf("CreateCustomRun failed at marshal task: %w", err)
+	}
+	_, _, err = s.kafka.Produce(ctx, &sarama.ProducerMessage{
+		Topic: constants.CustomRunTopic,
+		Key:   sarama.StringEncoder(runID),
+		Value: sarama.ByteEncoder(val),
+	})
+	if err != nil {
+		return "", fmt.Errorf("CreateCustomRun failed at produce message: %w", err)
+	}
+	return runID, nil
+}
+
+// GetCustomRun 获取自定义输入运行记录, 只能获取自己在当前比赛中的记录
+func (s *SubmissionServiceImpl) GetCustomRun(ctx context.Context, competitionID, userID uint64, runID string) (*model.CustomRun, error) {
+	runBytes, err := s.rdb.Get(ctx, fmt.Sprintf(customRunKey, runID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("GetCustomRun failed: %w", ErrCustomRunNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("GetCustomRun failed at get run: %w", err)
+	}
+
+	var run model.CustomRun
+	if err = json.Unmarshal(runBytes, &run); err != nil {
+		return nil, fmt.Errorf("GetCustomRun failed at unmarshal run: %w", err)
+	}
+	if run.UserID != userID || run.CompetitionID != competitionID {
+		return nil, fmt.Errorf("GetCustomRun failed: %w", ErrCustomRunNotFound)
+	}
+	return &run, nil
+}
+
+// SubscribeCustomRun 订阅自定义输入运行结果, 订阅后先推送当前记录, 运行完成或记录过期后关闭通道
+func (s *SubmissionServiceImpl) SubscribeCustomRun(ctx context.Context, competitionID, userID uint64, runID string) chan *model.CustomRun {
+	ch := make(chan *model.CustomRun, 1)
+	uc, ok := s.rdb.(redis.UniversalClient)
+	if !ok {
+		s.log.ErrorContext(ctx, "SubscribeCustomRun: redis cmdable not universal client")
+		close(ch)
+		return ch
+	}
+	go func() {
+		pubsub := uc.Subscribe(ctx, fmt.Sprintf(constants.RedisPubSubCustomRunEventKey, runID))
+		ticker := time.NewTicker(customRunEventInterval)
+		defer pubsub.Close()
+		defer ticker.Stop()
+		defer close(ch)
+
+		lastStatus := model.CustomRunStatus(-1)
+		// push 推送状态变化, 返回 false 表示需要结束订阅
+		push := func() bool {
+			run, err := s.GetCustomRun(ctx, competitionID, userID, runID)
+			if err != nil {
+				if !errors.Is(err, ErrCustomRunNotFound) {
+					s.log.ErrorContext(ctx, "SubscribeCustomRun: get custom run failed", logger.Error(err))
+					return true
+				}
+				return false
+			}
+			if run.Status != lastStatus {
+				lastStatus = run.Status
+				select {
+				case ch <- run:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return run.Status != model.CustomRunStatusFinished
+		}
+
+		if !push() {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				s.log.InfoContext(ctx, "SubscribeCustomRun: client closed")
+				return
+			case _, ok := <-pubsub.Channel():
+				if !ok || !push() {
+					return
+				}
+			case <-ticker.C:
+				if !push() {
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
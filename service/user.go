@@ -31,8 +31,8 @@ type UserService interface {
 	GetRoleByID(ctx context.Context, userID uint64) (ojmodel.UserRole, error)
 	// GetUserList 获取用户列表
 	GetUserList(ctx context.Context, param *model.GetUserListParam) ([]ojmodel.User, int, error)
-	// AddUsersToCompetition 添加用户到比赛名单
-	AddUsersToCompetition(ctx context.Context, competitionID uint64, userMap map[uint64]*ojmodel.User, startTime time.Time) (int64, error)
+	// AddUsersToCompetition 添加用户到比赛名单, category 与 unofficial 指定选手分类与是否打星
+	AddUsersToCompetition(ctx context.Context, competitionID uint64, userMap map[uint64]*ojmodel.User, startTime time.Time, category string, unofficial bool) (int64, error)
 	// GetUserListByUsernameList 获取用户列表, 根据学号全匹配, 仅返回正常用户
 	GetUserListByUsernameList(ctx context.Context, usernameList []string) ([]ojmodel.User, error)
 	// GetUserListByIDList 获取用户列表, 根据ID列表, 仅返回正常用户
@@ -53,6 +53,14 @@ type UserService interface {
 	GetCompetitionUserList(ctx context.Context, param *model.GetCompetitionUserListParam) ([]ojmodel.CompetitionUser, int, error)
 	// CreateUser 创建用户
 	CreateUser(ctx context.Context, username, realname string, role *ojmodel.UserRole) error
+	// SetCompetitionUserCategory 设置比赛选手分类
+	SetCompetitionUserCategory(ctx context.Context, param *model.SetCompetitionUserCategoryParam) error
+	// GetCompetitionUserCategories 获取比赛选手分类, 未设置分类的选手不在结果中
+	GetCompetitionUserCategories(ctx context.Context, competitionID uint64) (map[uint64]model.CompetitionUserCategory, error)
+	// UpdateUserNickname 更新用户昵称
+	UpdateUserNickname(ctx context.Context, userID uint64, nickname string) error
+	// GetUserStatistics 获取用户各比赛的提交统计, 比赛期间与赛后补题分开计算
+	GetUserStatistics(ctx context.Context, userID uint64) (*model.UserStatistics, error)
 }
 
 type UserServiceImpl struct {
@@ -126,8 +134,8 @@ func (s *UserServiceImpl) GetUserList(ctx context.Context, param *model.GetUserL
 	return users, int(total), nil
 }
 
-// AddUsersToCompetition 添加用户到比赛名单
-func (s *UserServiceImpl) AddUsersToCompetition(ctx context.Context, competitionID uint64, userMap map[uint64]*ojmodel.User, startTime time.Time) (int64, error) {
+// AddUsersToCompetition 添加用户到比赛名单, category 与 unofficial 指定选手分类与是否打星
+func (s *UserServiceImpl) AddUsersToCompetition(ctx context.Context, competitionID uint64, userMap map[uint64]*ojmodel.User, startTime time.Time, category string, unofficial bool) (int64, error) {
 	competitionUser := transform.SliceFromMap(userMap, func(userID uint64, user *ojmodel.User) ojmodel.CompetitionUser {
 		return ojmodel.CompetitionUser{
 			CompetitionID: competitionID,
@@ -138,11 +146,34 @@ func (s *UserServiceImpl) AddUsersToCompetition(ctx context.Context, competition
 			StartTime:     startTime,
 		}
 	})
-	res := s.db.WithContext(ctx).Create(&competitionUser)
-	if res.Error != nil {
-		return 0, fmt.Errorf("AddUsersToCompetition failed: %w", res.Error)
+	// 默认分类的正式选手无需记录分类
+	if category == "" && !unofficial {
+		res := s.db.WithContext(ctx).Create(&competitionUser)
+		if res.Error != nil {
+			return 0, fmt.Errorf("AddUsersToCompetition failed: %w", res.Error)
+		}
+		deleteCompetitionUserNicknameCache(ctx, s.rdb, s.log, competitionID)
+		return res.RowsAffected, nil
 	}
-	return res.RowsAffected, nil
+
+	var rowsAffected int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Create(&competitionUser)
+		if res.Error != nil {
+			return res.Error
+		}
+		rowsAffected = res.RowsAffected
+		userIDList := transform.SliceFromSlice(competitionUser, func(i int, cu ojmodel.CompetitionUser) uint64 {
+			return cu.UserID
+		})
+		return upsertCompetitionUserCategory(tx, competitionID, userIDList, category, unofficial)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("AddUsersToCompetition failed: %w", err)
+	}
+	s.deleteCompetitionUserCategoryCache(ctx, competitionID)
+	deleteCompetitionUserNicknameCache(ctx, s.rdb, s.log, competitionID)
+	return rowsAffected, nil
 }
 
 // GetUserListByUsernameList 获取用户列表, 根据学号全匹配, 仅返回正常用户
@@ -340,6 +371,17 @@ func (s *UserServiceImpl) GetCompetitionUserList(ctx context.Context, param *mod
 	if param.Status != nil {
 		query = query.Where("status = ?", param.Status.Int8())
 	}
+	if param.Category != nil {
+		categoryQuery := s.db.Model(&model.CompetitionUserCategory{}).
+			Where("competition_id = ?", param.CompetitionID).
+			Select("user_id")
+		if *param.Category == "" {
+			// 默认分类包含未设置分类的选手
+			query = query.Where("user_id NOT IN (?)", categoryQuery.Where("category <> ?", ""))
+		} else {
+			query = query.Where("user_id IN (?)", categoryQuery.Where("category = ?", *param.Category))
+		}
+	}
 
 	err := query.Count(&total).Error
 	if err != nil {
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/pkg404/gotools/retry"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const competitionUserNicknameKey = "competition:%d:user:nickname"
+
+// loadCompetitionUserNicknames 获取比赛选手昵称, 优先读取 Redis 缓存, 未设置昵称的选手不在结果中
+func loadCompetitionUserNicknames(ctx context.Context, db *gorm.DB, rdb redis.Cmdable, competitionID uint64) (map[uint64]string, error) {
+	nicknameKey := fmt.Sprintf(competitionUserNicknameKey, competitionID)
+
+	nicknameMap := make(map[uint64]string)
+	nicknameBytes, err := rdb.Get(ctx, nicknameKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(nicknameBytes, &nicknameMap); err == nil {
+			return nicknameMap, nil
+		}
+	}
+
+	var profiles []model.UserProfile
+	err = db.WithContext(ctx).
+		Table("user_profile p").
+		Select("p.user_id, p.nickname").
+		Joins("JOIN competition_user cu ON cu.user_id = p.user_id").
+		Where("cu.competition_id = ?", competitionID).
+		Where("p.nickname <> ''").
+		Scan(&profiles).Error
+	if err != nil {
+		return nil, fmt.Errorf("loadCompetitionUserNicknames failed at select from user_profile: %w", err)
+	}
+	for _, profile := range profiles {
+		nicknameMap[profile.UserID] = profile.Nickname
+	}
+
+	if nicknameBytes, err = json.Marshal(nicknameMap); err == nil {
+		rdb.Set(ctx, nicknameKey, nicknameBytes, 8*time.Hour)
+	}
+	return nicknameMap, nil
+}
+
+// loadUserNicknames 获取给定用户的昵称, 未设置昵称的用户不在结果中
+func loadUserNicknames(ctx context.Context, db *gorm.DB, userIDs []uint64) (map[uint64]string, error) {
+	nicknameMap := make(map[uint64]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return nicknameMap, nil
+	}
+
+	var profiles []model.UserProfile
+	err := db.WithContext(ctx).
+		Model(&model.UserProfile{}).
+		Select("user_id, nickname").
+		Where("user_id IN ?", userIDs).
+		Where("nickname <> ''").
+		Find(&profiles).Error
+	if err != nil {
+		return nil, fmt.Errorf("loadUserNicknames failed at select from user_profile: %w", err)
+	}
+	for _, profile := range profiles {
+		nicknameMap[profile.UserID] = profile.Nickname
+	}
+	return nicknameMap, nil
+}
+
+// UpdateUserNickname 更新用户昵称
+func (s *UserServiceImpl) UpdateUserNickname(ctx context.Context, userID uint64, nickname string) error {
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"nickname", "updated_at"}),
+	}).Create(&model.UserProfile{
+		UserID:   userID,
+		Nickname: nickname,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("UpdateUserNickname failed: %w", err)
+	}
+
+	// 昵称缓存按比赛存储, 删除该用户参加的所有比赛的缓存
+	var competitionIDs []uint64
+	err = s.db.WithContext(ctx).Model(&ojmodel.CompetitionUser{}).
+		Where("user_id = ?", userID).
+		Pluck("competition_id", &competitionIDs).Error
+	if err != nil {
+		return fmt.Errorf("UpdateUserNickname failed at select from competition_user: %w", err)
+	}
+	deleteCompetitionUserNicknameCache(ctx, s.rdb, s.log, competitionIDs...)
+	return nil
+}
+
+// deleteCompetitionUserNicknameCache 删除比赛选手昵称缓存, 比赛名单或选手昵称变化后调用
+func deleteCompetitionUserNicknameCache(ctx context.Context, rdb redis.Cmdable, log loggerv2.Logger, competitionIDs ...uint64) {
+	if len(competitionIDs) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(competitionIDs))
+	for _, competitionID := range competitionIDs {
+		keys = append(keys, fmt.Sprintf(competitionUserNicknameKey, competitionID))
+	}
+	retryCtx := context.WithValue(context.Background(), loggerv2.FieldsKey, ctx.Value(loggerv2.FieldsKey))
+	retry.Do(retryCtx, func() error {
+		return rdb.Del(retryCtx, keys...).Err()
+	}, retry.WithAsync(true), retry.WithCallback(func(err error) {
+		if err != nil {
+			log.ErrorContext(retryCtx, "delete competition user nickname cache failed", logger.Error(err))
+		}
+	}))
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/model"
+)
+
+// userProblemStatistics 用户单题的已判题提交统计, 按是否为赛后补题分组
+type userProblemStatistics struct {
+	CompetitionID   uint64 `gorm:"column:competition_id"`
+	ProblemID       uint64 `gorm:"column:problem_id"`
+	Upsolve         bool   `gorm:"column:upsolve"`
+	SubmissionCount int    `gorm:"column:submission_count"`
+	AcceptedCount   int    `gorm:"column:accepted_count"`
+}
+
+// GetUserStatistics 获取用户各比赛的提交统计, 比赛期间与赛后补题分开计算
+func (s *UserServiceImpl) GetUserStatistics(ctx context.Context, userID uint64) (*model.UserStatistics, error) {
+	var rows []userProblemStatistics
+	err := s.db.WithContext(ctx).
+		Table("submission s").
+		Joins("LEFT JOIN submission_upsolve u ON u.submission_id = s.id").
+		Select("s.competition_id, s.problem_id, u.submission_id IS NOT NULL AS upsolve, COUNT(*) AS submission_count, SUM(s.result = ?) AS accepted_count", ojmodel.SubmissionResultAccepted).
+		Where("s.user_id = ?", userID).
+		Where("s.status = ?", ojmodel.SubmissionStatusJudged).
+		Where("s.result != ?", ojmodel.SubmissionResultUnjudged).
+		Group("s.competition_id, s.problem_id, upsolve").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("GetUserStatistics failed at select from submission: %w", err)
+	}
+
+	// 先汇总比赛期间的通过情况, 赛后补题只统计比赛期间未通过的题目
+	type problemKey struct{ competitionID, problemID uint64 }
+	solved := make(map[problemKey]struct{})
+	competitions := make(map[uint64]*model.UserCompetitionStatistics)
+	for _, row := range rows {
+		if _, ok := competitions[row.CompetitionID]; !ok {
+			competitions[row.CompetitionID] = &model.UserCompetitionStatistics{CompetitionID: row.CompetitionID}
+		}
+		if !row.Upsolve && row.AcceptedCount > 0 {
+			solved[problemKey{row.CompetitionID, row.ProblemID}] = struct{}{}
+		}
+	}
+	for _, row := range rows {
+		stat := competitions[row.CompetitionID]
+		if !row.Upsolve {
+			stat.SubmissionCount += row.SubmissionCount
+			if row.AcceptedCount > 0 {
+				stat.SolvedCount++
+			}
+			continue
+		}
+		stat.UpsolveSubmissionCount += row.SubmissionCount
+		if _, ok := solved[problemKey{row.CompetitionID, row.ProblemID}]; !ok && row.AcceptedCount > 0 {
+			stat.UpsolveSolvedCount++
+		}
+	}
+
+	competitionIDs := make([]uint64, 0, len(competitions))
+	for competitionID := range competitions {
+		competitionIDs = append(competitionIDs, competitionID)
+	}
+	if len(competitionIDs) != 0 {
+		var names []ojmodel.Competition
+		err = s.db.WithContext(ctx).Model(&ojmodel.Competition{}).
+			Where("id IN ?", competitionIDs).
+			Select("id", "name").
+			Find(&names).Error
+		if err != nil {
+			return nil, fmt.Errorf("GetUserStatistics failed at select from competition: %w", err)
+		}
+		for _, competition := range names {
+			competitions[competition.ID].CompetitionName = competition.Name
+		}
+	}
+
+	statistics := &model.UserStatistics{
+		UserID:       userID,
+		Competitions: make([]model.UserCompetitionStatistics, 0, len(competitions)),
+	}
+	for _, stat := range competitions {
+		statistics.SubmissionCount += stat.SubmissionCount
+		statistics.SolvedCount += stat.SolvedCount
+		statistics.UpsolveSubmissionCount += stat.UpsolveSubmissionCount
+		statistics.UpsolveSolvedCount += stat.UpsolveSolvedCount
+		statistics.Competitions = append(statistics.Competitions, *stat)
+	}
+	sort.Slice(statistics.Competitions, func(i, j int) bool {
+		return statistics.Competitions[i].CompetitionID > statistics.Competitions[j].CompetitionID
+	})
+	return statistics, nil
+}
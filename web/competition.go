@@ -1,6 +1,7 @@
 package web
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 	"github.com/to404hanga/online_judge_controller/pkg/gintool"
 	"github.com/to404hanga/online_judge_controller/pkg/pointer"
 	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/online_judge_controller/service/exporter"
 	"github.com/to404hanga/online_judge_controller/service/exporter/factory"
 	"github.com/to404hanga/online_judge_controller/web/jwt"
 	"github.com/to404hanga/pkg404/gotools/transform"
@@ -22,7 +24,10 @@ import (
 
 type CompetitionHandler struct {
 	competitionSvc service.CompetitionService
+	lifecycleSvc   service.CompetitionLifecycleService
+	templateSvc    service.CompetitionTemplateService
 	rankingSvc     service.RankingService
+	plagiarismSvc  service.PlagiarismService
 	userSvc        service.UserService
 	jwtHandler     jwt.Handler
 	log            loggerv2.Logger
@@ -30,10 +35,13 @@ type CompetitionHandler struct {
 
 var _ Handler = (*CompetitionHandler)(nil)
 
-func NewCompetitionHandler(competitionSvc service.CompetitionService, rankingSvc service.RankingService, userSvc service.UserService, jwtHandler jwt.Handler, log loggerv2.Logger) *CompetitionHandler {
+func NewCompetitionHandler(competitionSvc service.CompetitionService, lifecycleSvc service.CompetitionLifecycleService, templateSvc service.CompetitionTemplateService, rankingSvc service.RankingService, plagiarismSvc service.PlagiarismService, userSvc service.UserService, jwtHandler jwt.Handler, log loggerv2.Logger) *CompetitionHandler {
 	return &CompetitionHandler{
 		competitionSvc: competitionSvc,
+		lifecycleSvc:   lifecycleSvc,
+		templateSvc:    templateSvc,
 		rankingSvc:     rankingSvc,
+		plagiarismSvc:  plagiarismSvc,
 		userSvc:        userSvc,
 		jwtHandler:     jwtHandler,
 		log:            log,
@@ -61,6 +69,53 @@ func (h *CompetitionHandler) Register(r *gin.Engine) {
 	r.GET(constants.GetCompetitionPath, gintool.WrapHandler(h.GetCompetition, h.log))
 	r.GET(constants.CheckUserCompetitionProblemAcceptedPath, gintool.WrapCompetitionHandler(h.CheckUserCompetitionProblemAccepted, h.log))
 	r.GET(constants.TimeEventPath, gintool.WrapCompetitionSSEHandler(h.TimeEventHandler, h.log, time.Second*10))
+	r.PUT(constants.TransitCompetitionPath, gintool.WrapHandler(h.TransitCompetition, h.log))
+	r.GET(constants.GetCompetitionTransitionListPath, gintool.WrapHandler(h.GetCompetitionTransitionList, h.log))
+	r.POST(constants.CloneCompetitionPath, gintool.WrapHandler(h.CloneCompetition, h.log))
+	r.GET(constants.GetCompetitionSettingPath, gintool.WrapHandler(h.GetCompetitionSetting, h.log))
+	r.PUT(constants.UpdateCompetitionSettingPath, gintool.WrapHandler(h.UpdateCompetitionSetting, h.log))
+	r.POST(constants.SaveCompetitionTemplatePath, gintool.WrapHandler(h.SaveCompetitionTemplate, h.log))
+	r.GET(constants.GetCompetitionTemplateListPath, gintool.WrapHandler(h.GetCompetitionTemplateList, h.log))
+	r.DELETE(constants.DeleteCompetitionTemplatePath, gintool.WrapHandler(h.DeleteCompetitionTemplate, h.log))
+	r.POST(constants.CreateCompetitionFromTemplatePath, gintool.WrapHandler(h.CreateCompetitionFromTemplate, h.log))
+	r.PUT(constants.ReorderCompetitionProblemPath, gintool.WrapHandler(h.ReorderCompetitionProblem, h.log))
+	r.POST(constants.DisqualifyCompetitionUserPath, gintool.WrapHandler(h.DisqualifyCompetitionUser, h.log))
+	r.POST(constants.ReinstateCompetitionUserPath, gintool.WrapHandler(h.ReinstateCompetitionUser, h.log))
+	r.GET(constants.GetCompetitionDisqualificationListPath, gintool.WrapHandler(h.GetCompetitionDisqualificationList, h.log))
+	r.PUT(constants.OverrideSubmissionResultPath, gintool.WrapHandler(h.OverrideSubmissionResult, h.log))
+	r.POST(constants.AddCompetitionTimeAdjustmentPath, gintool.WrapHandler(h.AddCompetitionTimeAdjustment, h.log))
+	r.GET(constants.GetCompetitionAdjustmentListPath, gintool.WrapHandler(h.GetCompetitionAdjustmentList, h.log))
+	r.GET(constants.GetCompetitionRankingHistoryPath, gintool.WrapCompetitionHandler(h.GetCompetitionRankingHistory, h.log))
+	r.GET(constants.GetCompetitionRankTrendPath, gintool.WrapCompetitionHandler(h.GetCompetitionRankTrend, h.log))
+	r.GET(constants.GetCompetitionRankingDiffPath, gintool.WrapCompetitionHandler(h.GetCompetitionRankingDiff, h.log))
+	r.GET(constants.RankingEventPath, gintool.WrapCompetitionSSEHandler(h.RankingEventHandler, h.log, time.Second*10))
+	r.POST(constants.CreateCompetitionAnnouncementPath, gintool.WrapHandler(h.CreateCompetitionAnnouncement, h.log))
+	r.GET(constants.GetCompetitionAnnouncementListPath, gintool.WrapHandler(h.GetCompetitionAnnouncementList, h.log))
+	r.GET(constants.LiveScoreboardPath, h.LiveScoreboardHandler)
+	r.GET(constants.GetPublicCompetitionRankingListPath, gintool.WrapPublicHandler(h.GetPublicCompetitionRankingList, h.log))
+	r.GET(constants.GetCompetitionProblemStatisticsPath, gintool.WrapHandler(h.GetCompetitionProblemStatistics, h.log))
+	r.GET(constants.UserGetCompetitionProblemStatisticsPath, gintool.WrapCompetitionHandler(h.UserGetCompetitionProblemStatistics, h.log))
+	r.POST(constants.RebuildCompetitionProblemStatisticsPath, gintool.WrapHandler(h.RebuildCompetitionProblemStatistics, h.log))
+	r.GET(constants.GetCompetitionFirstBloodListPath, gintool.WrapCompetitionHandler(h.GetCompetitionFirstBloodList, h.log))
+	r.GET(constants.FirstBloodEventPath, gintool.WrapCompetitionSSEHandler(h.FirstBloodEventHandler, h.log, time.Second*10))
+	r.POST(constants.CreatePlagiarismTaskPath, gintool.WrapHandler(h.CreatePlagiarismTask, h.log))
+	r.GET(constants.GetPlagiarismTaskListPath, gintool.WrapHandler(h.GetPlagiarismTaskList, h.log))
+	r.GET(constants.GetPlagiarismPairListPath, gintool.WrapHandler(h.GetPlagiarismPairList, h.log))
+	r.GET(constants.GetPlagiarismPairDiffPath, gintool.WrapHandler(h.GetPlagiarismPairDiff, h.log))
+	r.POST(constants.ReviewPlagiarismPairPath, gintool.WrapHandler(h.ReviewPlagiarismPair, h.log))
+	r.GET(constants.GetCompetitionUpsolveRankingListPath, gintool.WrapCompetitionHandler(h.GetCompetitionUpsolveRankingList, h.log))
+}
+
+// lifecycleErrorCode 将比赛生命周期相关错误映射为响应码
+func lifecycleErrorCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidCompetitionTransition), errors.Is(err, service.ErrCompetitionFieldNotEditable):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrCompetitionStateConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 func (h *CompetitionHandler) CreateCompetition(c *gin.Context, param *model.CreateCompetitionParam) {
@@ -133,10 +188,62 @@ func (h *CompetitionHandler) UpdateCompetition(c *gin.Context, param *model.Upda
 		return
 	}
 
+	// 检查当前比赛状态下是否允许修改对应字段
+	fields := make([]string, 0, 3)
+	if param.Name != nil {
+		fields = append(fields, model.CompetitionFieldName)
+	}
+	if param.StartTime != nil {
+		fields = append(fields, model.CompetitionFieldStartTime)
+	}
+	if param.EndTime != nil {
+		fields = append(fields, model.CompetitionFieldEndTime)
+	}
+	err = h.lifecycleSvc.CheckCompetitionEditable(ctx, param.ID, fields...)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    lifecycleErrorCode(err),
+			Message: fmt.Sprintf("UpdateCompetition failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "UpdateCompetition CheckCompetitionEditable failed", logger.Error(err))
+		return
+	}
+
+	// 发布、取消发布与删除均通过状态流转完成, 删除即草稿直接归档;
+	// 状态流转在修改字段之前校验, 避免流转被拒绝时字段已被部分修改
+	var transitTo *model.CompetitionState
 	if param.Status != nil {
-		if *param.Status != int8(ojmodel.CompetitionStatusUnpublished) &&
-			*param.Status != int8(ojmodel.CompetitionStatusPublished) &&
-			*param.Status != int8(ojmodel.CompetitionStatusDeleted) {
+		state, err := h.lifecycleSvc.GetCompetitionState(ctx, param.ID)
+		if err != nil {
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    http.StatusInternalServerError,
+				Message: fmt.Sprintf("UpdateCompetition failed: %s", err.Error()),
+			})
+			h.log.ErrorContext(ctx, "UpdateCompetition GetCompetitionState failed", logger.Error(err))
+			return
+		}
+		switch ojmodel.CompetitionStatus(*param.Status) {
+		case ojmodel.CompetitionStatusPublished:
+			// 已发布的比赛再次发布视为无操作
+			if state == model.CompetitionStateDraft {
+				transitTo = pointer.ToPtr(model.CompetitionStateScheduled)
+			}
+		case ojmodel.CompetitionStatusUnpublished:
+			if state != model.CompetitionStateDraft {
+				transitTo = pointer.ToPtr(model.CompetitionStateDraft)
+			}
+		case ojmodel.CompetitionStatusDeleted:
+			if state != model.CompetitionStateDraft {
+				gintool.GinResponse(c, &gintool.Response{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf("Competition in state %s cannot be deleted", state.String()),
+				})
+				h.log.ErrorContext(ctx, "UpdateCompetition competition cannot be deleted",
+					logger.String("state", state.String()))
+				return
+			}
+			transitTo = pointer.ToPtr(model.CompetitionStateArchived)
+		default:
 			gintool.GinResponse(c, &gintool.Response{
 				Code:    http.StatusBadRequest,
 				Message: "Status must be Unpublished, Published, or Deleted",
@@ -145,6 +252,18 @@ func (h *CompetitionHandler) UpdateCompetition(c *gin.Context, param *model.Upda
 				logger.Int8("status", *param.Status))
 			return
 		}
+		param.Status = nil
+	}
+	if transitTo != nil {
+		err = h.lifecycleSvc.CheckCompetitionTransition(ctx, param.ID, *transitTo)
+		if err != nil {
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    lifecycleErrorCode(err),
+				Message: fmt.Sprintf("UpdateCompetition failed: %s", err.Error()),
+			})
+			h.log.ErrorContext(ctx, "UpdateCompetition CheckCompetitionTransition failed", logger.Error(err))
+			return
+		}
 	}
 
 	err = h.competitionSvc.UpdateCompetition(ctx, param)
@@ -156,6 +275,18 @@ func (h *CompetitionHandler) UpdateCompetition(c *gin.Context, param *model.Upda
 		h.log.ErrorContext(ctx, "UpdateCompetition failed", logger.Error(err))
 		return
 	}
+
+	if transitTo != nil {
+		err = h.lifecycleSvc.TransitCompetition(ctx, param.ID, *transitTo, param.Operator, "UpdateCompetition")
+		if err != nil {
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    lifecycleErrorCode(err),
+				Message: fmt.Sprintf("UpdateCompetition failed: %s", err.Error()),
+			})
+			h.log.ErrorContext(ctx, "UpdateCompetition TransitCompetition failed", logger.Error(err))
+			return
+		}
+	}
 	gintool.GinResponse(c, &gintool.Response{
 		Code:    http.StatusOK,
 		Message: "success",
@@ -167,7 +298,17 @@ func (h *CompetitionHandler) AddCompetitionProblem(c *gin.Context, param *model.
 		logger.Uint64("competition_id", param.CompetitionID),
 		logger.Slice("problem_ids", param.ProblemIDs))
 
-	err := h.competitionSvc.AddCompetitionProblem(ctx, param)
+	err := h.lifecycleSvc.CheckCompetitionEditable(ctx, param.CompetitionID, model.CompetitionFieldProblems)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    lifecycleErrorCode(err),
+			Message: fmt.Sprintf("AddCompetitionProblem failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "AddCompetitionProblem CheckCompetitionEditable failed", logger.Error(err))
+		return
+	}
+
+	err = h.competitionSvc.AddCompetitionProblem(ctx, param)
 	if err != nil {
 		gintool.GinResponse(c, &gintool.Response{
 			Code:    http.StatusInternalServerError,
@@ -187,7 +328,17 @@ func (h *CompetitionHandler) RemoveCompetitionProblem(c *gin.Context, param *mod
 		logger.Uint64("competition_id", param.CompetitionID),
 		logger.Slice("problem_ids", param.ProblemIDs))
 
-	err := h.competitionSvc.RemoveCompetitionProblem(ctx, param)
+	err := h.lifecycleSvc.CheckCompetitionEditable(ctx, param.CompetitionID, model.CompetitionFieldProblems)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    lifecycleErrorCode(err),
+			Message: fmt.Sprintf("RemoveCompetitionProblem failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "RemoveCompetitionProblem CheckCompetitionEditable failed", logger.Error(err))
+		return
+	}
+
+	err = h.competitionSvc.RemoveCompetitionProblem(ctx, param)
 	if err != nil {
 		gintool.GinResponse(c, &gintool.Response{
 			Code:    http.StatusInternalServerError,
@@ -207,7 +358,17 @@ func (h *CompetitionHandler) EnableCompetitionProblem(c *gin.Context, param *mod
 		logger.Uint64("competition_id", param.CompetitionID),
 		logger.Slice("problem_ids", param.ProblemIDs))
 
-	err := h.competitionSvc.EnableCompetitionProblem(ctx, param)
+	err := h.lifecycleSvc.CheckCompetitionEditable(ctx, param.CompetitionID, model.CompetitionFieldProblems)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    lifecycleErrorCode(err),
+			Message: fmt.Sprintf("EnableCompetitionProblem failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "EnableCompetitionProblem CheckCompetitionEditable failed", logger.Error(err))
+		return
+	}
+
+	err = h.competitionSvc.EnableCompetitionProblem(ctx, param)
 	if err != nil {
 		gintool.GinResponse(c, &gintool.Response{
 			Code:    http.StatusInternalServerError,
@@ -227,7 +388,17 @@ func (h *CompetitionHandler) DisableCompetitionProblem(c *gin.Context, param *mo
 		logger.Uint64("competition_id", param.CompetitionID),
 		logger.Slice("problem_ids", param.ProblemIDs))
 
-	err := h.competitionSvc.DisableCompetitionProblem(ctx, param)
+	err := h.lifecycleSvc.CheckCompetitionEditable(ctx, param.CompetitionID, model.CompetitionFieldProblems)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    lifecycleErrorCode(err),
+			Message: fmt.Sprintf("DisableCompetitionProblem failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "DisableCompetitionProblem CheckCompetitionEditable failed", logger.Error(err))
+		return
+	}
+
+	err = h.competitionSvc.DisableCompetitionProblem(ctx, param)
 	if err != nil {
 		gintool.GinResponse(c, &gintool.Response{
 			Code:    http.StatusInternalServerError,
@@ -242,6 +413,40 @@ func (h *CompetitionHandler) DisableCompetitionProblem(c *gin.Context, param *mo
 	})
 }
 
+func (h *CompetitionHandler) ReorderCompetitionProblem(c *gin.Context, param *model.ReorderCompetitionProblemParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Slice("problem_ids", param.ProblemIDs))
+
+	err := h.lifecycleSvc.CheckCompetitionEditable(ctx, param.CompetitionID, model.CompetitionFieldProblems)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    lifecycleErrorCode(err),
+			Message: fmt.Sprintf("ReorderCompetitionProblem failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "ReorderCompetitionProblem CheckCompetitionEditable failed", logger.Error(err))
+		return
+	}
+
+	err = h.competitionSvc.ReorderCompetitionProblem(ctx, param)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidProblemOrder) {
+			code = http.StatusBadRequest
+		}
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    code,
+			Message: fmt.Sprintf("ReorderCompetitionProblem failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "ReorderCompetitionProblem failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
 func (h *CompetitionHandler) StartCompetition(c *gin.Context, param *model.StartCompetitionParam) {
 	start := time.Now()
 	code := http.StatusOK
@@ -289,12 +494,49 @@ func (h *CompetitionHandler) StartCompetition(c *gin.Context, param *model.Start
 		h.log.ErrorContext(ctx, "CheckCompetitionTime failed", logger.Error(err))
 		return
 	}
+	// 比赛结束后允许补题时仍可进入比赛
+	upsolve := false
 	if !ok {
+		upsolve, err = h.competitionSvc.CheckCompetitionUpsolve(ctx, param.CompetitionID)
+		if err != nil {
+			code = http.StatusInternalServerError
+			reason = "check_competition_upsolve_error"
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    http.StatusInternalServerError,
+				Message: fmt.Sprintf("CheckCompetitionUpsolve failed: %s", err.Error()),
+			})
+			h.log.ErrorContext(ctx, "CheckCompetitionUpsolve failed", logger.Error(err))
+			return
+		}
+		if !upsolve {
+			code = http.StatusForbidden
+			reason = "not_in_competition_time"
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    http.StatusForbidden,
+				Message: "不在比赛时间内",
+			})
+			return
+		}
+	}
+
+	// 检查比赛状态, 到达开始时间的比赛在此自动流转为进行中
+	state, err := h.lifecycleSvc.GetCompetitionState(ctx, param.CompetitionID)
+	if err != nil {
+		code = http.StatusInternalServerError
+		reason = "get_competition_state_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionState failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionState failed", logger.Error(err))
+		return
+	}
+	if !upsolve && state != model.CompetitionStateRunning && state != model.CompetitionStateFrozen {
 		code = http.StatusForbidden
-		reason = "not_in_competition_time"
+		reason = "competition_not_running"
 		gintool.GinResponse(c, &gintool.Response{
 			Code:    http.StatusForbidden,
-			Message: "不在比赛时间内",
+			Message: fmt.Sprintf("比赛当前状态为%s, 无法进入", state.String()),
 		})
 		return
 	}
@@ -331,7 +573,7 @@ func (h *CompetitionHandler) GetCompetitionRankingList(c *gin.Context, param *mo
 	ctx := loggerv2.ContextWithFields(c.Request.Context(),
 		logger.Uint64("competition_id", param.CompetitionID))
 
-	rankingList, total, err := h.rankingSvc.GetCompetitionRankingList(ctx, param.CompetitionID, param.Page, param.PageSize)
+	rankingList, total, err := h.rankingSvc.GetCompetitionRankingList(ctx, param.CompetitionID, param.Page, param.PageSize, param.Category)
 	if err != nil {
 		code = http.StatusInternalServerError
 		reason = "get_competition_ranking_list_error"
@@ -342,36 +584,72 @@ func (h *CompetitionHandler) GetCompetitionRankingList(c *gin.Context, param *mo
 		h.log.ErrorContext(ctx, "GetCompetitionRankingList failed", logger.Error(err))
 		return
 	}
+	problemList, err := h.competitionSvc.UserGetCompetitionProblemList(ctx, param.CompetitionID)
+	if err != nil {
+		code = http.StatusInternalServerError
+		reason = "user_get_competition_problem_list_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionRankingList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "UserGetCompetitionProblemList failed", logger.Error(err))
+		return
+	}
+	display, err := h.rankingSvc.GetRankingDisplay(ctx, param.CompetitionID, false)
+	if err != nil {
+		code = http.StatusInternalServerError
+		reason = "get_ranking_display_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionRankingList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetRankingDisplay failed", logger.Error(err))
+		return
+	}
+	display.ApplyRankings(rankingList)
+	// 版本号只用于增量更新, 获取失败时客户端退化为轮询完整排行榜
+	version, err := h.rankingSvc.GetRankingVersion(ctx, param.CompetitionID)
+	if err != nil {
+		h.log.WarnContext(ctx, "GetRankingVersion failed", logger.Error(err))
+	}
+	// 题目统计只作为表头展示, 获取失败不影响排行榜
+	statistics, err := h.rankingSvc.GetCompetitionProblemStatistics(ctx, param.CompetitionID, false)
+	if err != nil {
+		h.log.WarnContext(ctx, "GetCompetitionProblemStatistics failed", logger.Error(err))
+	}
+	display.ApplyStatistics(statistics)
 	gintool.GinResponse(c, &gintool.Response{
 		Code:    http.StatusOK,
 		Message: "success",
 		Data: model.GetCompetitionRankingListResponse{
-			List:     rankingList,
-			Total:    total,
-			Page:     param.Page,
-			PageSize: param.PageSize,
+			Version:    version,
+			Problems:   problemList,
+			Statistics: statistics,
+			List:       rankingList,
+			Total:      total,
+			Page:       param.Page,
+			PageSize:   param.PageSize,
 		},
 	})
 }
 
-// 暂时弃用
+// GetCompetitionFastestSolverList 已弃用, 请使用 GetCompetitionFirstBloodList
 func (h *CompetitionHandler) GetCompetitionFastestSolverList(c *gin.Context, param *model.GetCompetitionFastestSolverListParam) {
 	ctx := loggerv2.ContextWithFields(c.Request.Context(),
 		logger.Uint64("competition_id", param.CompetitionID))
 
 	if len(param.ProblemIDs) == 0 {
-		// TODO: 实现用户获取比赛题目接口后将其覆盖
-		problemList, err := h.competitionSvc.GetCompetitionProblemList(ctx, param.CompetitionID)
+		problemList, err := h.competitionSvc.UserGetCompetitionProblemList(ctx, param.CompetitionID)
 		if err != nil {
 			gintool.GinResponse(c, &gintool.Response{
 				Code:    http.StatusInternalServerError,
-				Message: fmt.Sprintf("GetCompetitionProblemList failed: %s", err.Error()),
+				Message: fmt.Sprintf("UserGetCompetitionProblemList failed: %s", err.Error()),
 			})
-			h.log.ErrorContext(ctx, "GetCompetitionProblemList failed", logger.Error(err))
+			h.log.ErrorContext(ctx, "UserGetCompetitionProblemList failed", logger.Error(err))
 			return
 		}
-		param.ProblemIDs = transform.SliceFromSlice(problemList, func(i int, problem ojmodel.CompetitionProblem) uint64 {
-			return problem.ID
+		param.ProblemIDs = transform.SliceFromSlice(problemList, func(i int, problem model.CompetitionProblemItem) uint64 {
+			return problem.ProblemID
 		})
 	}
 
@@ -403,9 +681,35 @@ func (h *CompetitionHandler) ExportCompetitionData(c *gin.Context, param *model.
 		h.log.ErrorContext(ctx, "Unknown exporter type", logger.Int8("export_type", int8(param.ExportType)))
 		return
 	}
+	if param.SplitByCategory && exporterType != factory.XLSXRankingExporter {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusBadRequest,
+			Message: "split by category is only supported by xlsx ranking exporter",
+		})
+		h.log.ErrorContext(ctx, "split by category not supported", logger.String("export_type", string(exporterType)))
+		return
+	}
 	ctx = loggerv2.ContextWithFields(ctx, logger.String("export_type", string(exporterType)))
 
-	filepath, err := h.rankingSvc.Export(ctx, param.CompetitionID, exporterType)
+	opts := &exporter.Options{
+		Category:            param.Category,
+		SplitByCategory:     param.SplitByCategory,
+		IncludeDisqualified: param.IncludeDisqualified,
+	}
+	if param.ApplyDisplayMode {
+		setting, err := h.competitionSvc.GetCompetitionSetting(ctx, param.CompetitionID)
+		if err != nil {
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    http.StatusInternalServerError,
+				Message: fmt.Sprintf("GetCompetitionSetting failed: %s", err.Error()),
+			})
+			h.log.ErrorContext(ctx, "GetCompetitionSetting failed", logger.Error(err))
+			return
+		}
+		opts.DisplayMode = setting.DisplayMode
+	}
+
+	filepath, err := h.rankingSvc.Export(ctx, param.CompetitionID, exporterType, opts)
 	if err != nil {
 		gintool.GinResponse(c, &gintool.Response{
 			Code:    http.StatusInternalServerError,
@@ -443,7 +747,13 @@ func (h *CompetitionHandler) UpdateScore(c *gin.Context, param *model.UpdateScor
 	ctx := loggerv2.ContextWithFields(c.Request.Context(),
 		logger.Uint64("competition_id", param.CompetitionID))
 
-	err := h.rankingSvc.UpdateUserScore(ctx, param.CompetitionID, param.ProblemID, param.UserID, *param.IsAccepted, param.SubmissionTime, param.StartTime)
+	result := ojmodel.SubmissionResultWrongAnswer
+	if param.Result != nil {
+		result = *param.Result
+	} else if *param.IsAccepted {
+		result = ojmodel.SubmissionResultAccepted
+	}
+	err := h.rankingSvc.UpdateUserScore(ctx, param.CompetitionID, param.ProblemID, param.UserID, result, param.SubmissionTime, param.StartTime)
 	if err != nil {
 		gintool.GinResponse(c, &gintool.Response{
 			Code:    http.StatusInternalServerError,
@@ -622,6 +932,15 @@ func (h *CompetitionHandler) GetCompetition(c *gin.Context, param *model.GetComp
 		h.log.ErrorContext(ctx, "GetCompetition failed", logger.Error(err))
 		return
 	}
+	state, err := h.lifecycleSvc.GetCompetitionState(ctx, param.CompetitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetition failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetition failed", logger.Error(err))
+		return
+	}
 	gintool.GinResponse(c, &gintool.Response{
 		Code:    http.StatusOK,
 		Message: "success",
@@ -629,6 +948,7 @@ func (h *CompetitionHandler) GetCompetition(c *gin.Context, param *model.GetComp
 			Competition:     competition,
 			CreatorRealname: creator.Realname,
 			UpdaterRealname: updater.Realname,
+			State:           state,
 		},
 	})
 }
@@ -770,3 +1090,56 @@ func (h *CompetitionHandler) TimeEventHandler(c *gin.Context, param *model.TimeE
 	}()
 	return ch
 }
+
+func (h *CompetitionHandler) TransitCompetition(c *gin.Context, param *model.TransitCompetitionParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.String("state", param.State.String()))
+
+	err := h.lifecycleSvc.TransitCompetition(ctx, param.CompetitionID, *param.State, param.Operator, param.Reason)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    lifecycleErrorCode(err),
+			Message: fmt.Sprintf("TransitCompetition failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "TransitCompetition failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *CompetitionHandler) GetCompetitionTransitionList(c *gin.Context, param *model.GetCompetitionTransitionListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+	h.log.DebugContext(ctx, "GetCompetitionTransitionList param")
+
+	state, err := h.lifecycleSvc.GetCompetitionState(ctx, param.CompetitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionTransitionList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionState failed", logger.Error(err))
+		return
+	}
+	transitions, err := h.lifecycleSvc.GetCompetitionTransitionList(ctx, param.CompetitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionTransitionList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionTransitionList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetCompetitionTransitionListResponse{
+			State: state,
+			List:  transitions,
+			Total: len(transitions),
+		},
+	})
+}
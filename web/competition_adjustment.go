@@ -0,0 +1,91 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// adjustmentErrorCode 将人工改判与罚时调整相关错误映射为响应码
+func adjustmentErrorCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrSubmissionNotFound), errors.Is(err, service.ErrCompetitionUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrSubmissionNotJudged), errors.Is(err, service.ErrSubmissionUnchanged):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *CompetitionHandler) OverrideSubmissionResult(c *gin.Context, param *model.OverrideSubmissionResultParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("submission_id", param.SubmissionID),
+		logger.Int8("result", param.Result.Int8()),
+		logger.String("reason", param.Reason))
+
+	err := h.rankingSvc.OverrideSubmissionResult(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    adjustmentErrorCode(err),
+			Message: fmt.Sprintf("OverrideSubmissionResult failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "OverrideSubmissionResult failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *CompetitionHandler) AddCompetitionTimeAdjustment(c *gin.Context, param *model.AddCompetitionTimeAdjustmentParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("user_id", param.UserID),
+		logger.Int("minutes", param.Minutes),
+		logger.String("reason", param.Reason))
+
+	err := h.rankingSvc.AddCompetitionTimeAdjustment(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    adjustmentErrorCode(err),
+			Message: fmt.Sprintf("AddCompetitionTimeAdjustment failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "AddCompetitionTimeAdjustment failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *CompetitionHandler) GetCompetitionAdjustmentList(c *gin.Context, param *model.GetCompetitionAdjustmentListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	overrides, adjustments, err := h.rankingSvc.GetCompetitionAdjustmentList(ctx, param.CompetitionID, param.UserID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionAdjustmentList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionAdjustmentList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetCompetitionAdjustmentListResponse{
+			VerdictOverrides: overrides,
+			TimeAdjustments:  adjustments,
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+func (h *CompetitionHandler) CreateCompetitionAnnouncement(c *gin.Context, param *model.CreateCompetitionAnnouncementParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	announcement, err := h.competitionSvc.CreateCompetitionAnnouncement(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("CreateCompetitionAnnouncement failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "CreateCompetitionAnnouncement failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    announcement,
+	})
+}
+
+func (h *CompetitionHandler) GetCompetitionAnnouncementList(c *gin.Context, param *model.GetCompetitionAnnouncementListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	list, err := h.competitionSvc.GetCompetitionAnnouncementList(ctx, param.CompetitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionAnnouncementList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionAnnouncementList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetCompetitionAnnouncementListResponse{
+			List:  list,
+			Total: len(list),
+		},
+	})
+}
@@ -0,0 +1,89 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// disqualificationErrorCode 将取消资格相关错误映射为响应码
+func disqualificationErrorCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrCompetitionUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUserAlreadyDisqualified), errors.Is(err, service.ErrUserNotDisqualified):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *CompetitionHandler) DisqualifyCompetitionUser(c *gin.Context, param *model.DisqualifyCompetitionUserParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("user_id", param.UserID),
+		logger.String("reason", param.Reason))
+
+	err := h.rankingSvc.DisqualifyCompetitionUser(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    disqualificationErrorCode(err),
+			Message: fmt.Sprintf("DisqualifyCompetitionUser failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "DisqualifyCompetitionUser failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *CompetitionHandler) ReinstateCompetitionUser(c *gin.Context, param *model.ReinstateCompetitionUserParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("user_id", param.UserID))
+
+	err := h.rankingSvc.ReinstateCompetitionUser(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    disqualificationErrorCode(err),
+			Message: fmt.Sprintf("ReinstateCompetitionUser failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "ReinstateCompetitionUser failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *CompetitionHandler) GetCompetitionDisqualificationList(c *gin.Context, param *model.GetCompetitionDisqualificationListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	list, err := h.rankingSvc.GetCompetitionDisqualificationList(ctx, param.CompetitionID, param.Active)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionDisqualificationList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionDisqualificationList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetCompetitionDisqualificationListResponse{
+			List:  list,
+			Total: len(list),
+		},
+	})
+}
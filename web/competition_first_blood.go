@@ -0,0 +1,100 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// GetCompetitionFirstBloodList 获取比赛各题的首个通过者、通过时间与提交次数
+func (h *CompetitionHandler) GetCompetitionFirstBloodList(c *gin.Context, param *model.GetCompetitionFirstBloodListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	list, err := h.rankingSvc.GetCompetitionFirstBloodList(ctx, param.CompetitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionFirstBloodList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionFirstBloodList failed", logger.Error(err))
+		return
+	}
+	display, err := h.rankingSvc.GetRankingDisplay(ctx, param.CompetitionID, false)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionFirstBloodList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetRankingDisplay failed", logger.Error(err))
+		return
+	}
+	for i := range list {
+		display.Apply(&list[i].UserID, &list[i].Username, &list[i].Realname)
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetCompetitionFirstBloodListResponse{
+			List:  list,
+			Total: len(list),
+		},
+	})
+}
+
+// FirstBloodEventHandler 通过 SSE 推送首个通过者的变化, 每条事件为 JSON 格式的 model.FirstBlood
+func (h *CompetitionHandler) FirstBloodEventHandler(c *gin.Context, param *model.FirstBloodEventParam) chan string {
+	start := time.Now()
+	firstBloodEventActiveConnections.Inc()
+	firstBloodEventConnectionsTotal.WithLabelValues("open").Inc()
+
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	ch := make(chan string, 1)
+	fbCh := h.rankingSvc.SubscribeFirstBlood(ctx, param.CompetitionID)
+	go func() {
+		closeReason := "closed"
+		defer close(ch)
+		defer func() {
+			firstBloodEventActiveConnections.Dec()
+			firstBloodEventConnectionDurationSeconds.WithLabelValues(closeReason).Observe(time.Since(start).Seconds())
+		}()
+		for {
+			select {
+			case <-c.Done():
+				h.log.InfoContext(c.Request.Context(), "FirstBloodEventHandler client closed")
+				closeReason = "client_closed"
+				firstBloodEventConnectionsTotal.WithLabelValues("client_closed").Inc()
+				return
+			case fb, ok := <-fbCh:
+				if !ok {
+					h.log.InfoContext(c.Request.Context(), "FirstBloodEventHandler first blood channel closed")
+					closeReason = "event_channel_closed"
+					firstBloodEventConnectionsTotal.WithLabelValues("event_channel_closed").Inc()
+					return
+				}
+				if fb.UserID != 0 {
+					display, err := h.rankingSvc.GetRankingDisplay(ctx, param.CompetitionID, false)
+					if err != nil {
+						h.log.ErrorContext(ctx, "FirstBloodEventHandler get ranking display failed", logger.Error(err))
+						continue
+					}
+					display.Apply(&fb.UserID, &fb.Username, &fb.Realname)
+				}
+				fbBytes, err := json.Marshal(fb)
+				if err != nil {
+					h.log.ErrorContext(ctx, "FirstBloodEventHandler marshal first blood failed", logger.Error(err))
+					continue
+				}
+				ch <- string(fbBytes)
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,136 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// checkRankingHistoryAvailable 历史排行榜包含封榜后的提交, 仅在定榜后开放
+func (h *CompetitionHandler) checkRankingHistoryAvailable(c *gin.Context, competitionID uint64) bool {
+	return h.checkCompetitionFinalized(c, competitionID, "ranking history")
+}
+
+// checkCompetitionFinalized 检查比赛是否已定榜, 未定榜时写入 403 响应, feature 为响应中的功能名称
+func (h *CompetitionHandler) checkCompetitionFinalized(c *gin.Context, competitionID uint64, feature string) bool {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", competitionID))
+
+	state, err := h.lifecycleSvc.GetCompetitionState(ctx, competitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionState failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionState failed", logger.Error(err))
+		return false
+	}
+	if state < model.CompetitionStateFinalized {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusForbidden,
+			Message: fmt.Sprintf("%s is available after the competition is finalized", feature),
+		})
+		h.log.WarnContext(ctx, fmt.Sprintf("%s is not available", feature), logger.String("state", state.String()))
+		return false
+	}
+	return true
+}
+
+func (h *CompetitionHandler) GetCompetitionRankingHistory(c *gin.Context, param *model.GetCompetitionRankingHistoryParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Int("minute", param.Minute))
+
+	if !h.checkRankingHistoryAvailable(c, param.CompetitionID) {
+		return
+	}
+
+	rankingList, total, minute, err := h.rankingSvc.GetCompetitionRankingHistory(ctx, param.CompetitionID, param.Minute, param.Page, param.PageSize, param.Category)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCompetitionNotFound) {
+			code = http.StatusNotFound
+		}
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    code,
+			Message: fmt.Sprintf("GetCompetitionRankingHistory failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionRankingHistory failed", logger.Error(err))
+		return
+	}
+	display, err := h.rankingSvc.GetRankingDisplay(ctx, param.CompetitionID, false)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionRankingHistory failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetRankingDisplay failed", logger.Error(err))
+		return
+	}
+	display.ApplyRankings(rankingList)
+	problems, err := h.competitionSvc.UserGetCompetitionProblemList(ctx, param.CompetitionID)
+	if err != nil {
+		h.log.WarnContext(ctx, "GetCompetitionRankingHistory get problem list failed", logger.Error(err))
+	}
+
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetCompetitionRankingHistoryResponse{
+			Minute:   minute,
+			Problems: problems,
+			List:     rankingList,
+			Total:    total,
+			Page:     param.Page,
+			PageSize: param.PageSize,
+		},
+	})
+}
+
+func (h *CompetitionHandler) GetCompetitionRankTrend(c *gin.Context, param *model.GetCompetitionRankTrendParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Int("top", param.Top),
+		logger.Int("interval", param.Interval))
+
+	if !h.checkRankingHistoryAvailable(c, param.CompetitionID) {
+		return
+	}
+
+	trend, err := h.rankingSvc.GetCompetitionRankTrend(ctx, param.CompetitionID, param.UserID, param.Top, param.Interval)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCompetitionNotFound) {
+			code = http.StatusNotFound
+		}
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    code,
+			Message: fmt.Sprintf("GetCompetitionRankTrend failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionRankTrend failed", logger.Error(err))
+		return
+	}
+	display, err := h.rankingSvc.GetRankingDisplay(ctx, param.CompetitionID, false)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionRankTrend failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetRankingDisplay failed", logger.Error(err))
+		return
+	}
+	for i := range trend.Series {
+		display.Apply(&trend.Series[i].UserID, &trend.Series[i].Username, &trend.Series[i].Realname)
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    trend,
+	})
+}
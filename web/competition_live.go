@@ -0,0 +1,241 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/constants"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	liveScoreboardClockInterval = 30 * time.Second // 时钟推送间隔, 同时充当心跳防止代理断开空闲连接
+	liveScoreboardWriteTimeout  = 10 * time.Second
+)
+
+// LiveScoreboardHandler 实时排行榜 WebSocket 接口, 推送排行榜差异、比赛公告与比赛时钟.
+// 携带比赛 token 时以选手身份连接, 否则为只读的公开连接; 该路径不在 checkCompetitionPath 中, 鉴权在升级连接前完成
+func (h *CompetitionHandler) LiveScoreboardHandler(c *gin.Context) {
+	var param model.LiveScoreboardParam
+	if err := c.ShouldBindQuery(&param); err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		h.log.ErrorContext(c.Request.Context(), "LiveScoreboardHandler bind query failed", logger.Error(err))
+		return
+	}
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	hello, ok := h.authLiveScoreboard(c, ctx, &param)
+	if !ok {
+		return
+	}
+
+	server := websocket.Server{
+		// 鉴权已在升级连接前完成, 大屏等客户端可能不携带 Origin, 此处不再校验
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			h.serveLiveScoreboard(ctx, ws, &param, hello)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// authLiveScoreboard 确定连接模式, 携带 token 时必须是该比赛的有效 token, 未携带时要求比赛开启公开排行榜
+func (h *CompetitionHandler) authLiveScoreboard(c *gin.Context, ctx context.Context, param *model.LiveScoreboardParam) (*model.LiveScoreboardHello, bool) {
+	token := param.Token
+	if token == "" {
+		token = c.GetHeader(constants.HeaderCompetitionTokenKey)
+	}
+	if token == "" {
+		token, _ = c.Cookie(constants.HeaderCompetitionTokenKey)
+	}
+
+	if token != "" {
+		claims, err := h.jwtHandler.ParseCompetitionToken(c, token)
+		if err != nil || claims.CompetitionID != param.CompetitionID {
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    http.StatusForbidden,
+				Message: "invalid competition token",
+			})
+			h.log.WarnContext(ctx, "LiveScoreboardHandler invalid competition token", logger.Error(err))
+			return nil, false
+		}
+		return &model.LiveScoreboardHello{
+			CompetitionID: param.CompetitionID,
+			Mode:          model.LiveScoreboardModeContestant,
+			UserID:        claims.UserId,
+		}, true
+	}
+
+	setting, err := h.competitionSvc.GetCompetitionSetting(ctx, param.CompetitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionSetting failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionSetting failed", logger.Error(err))
+		return nil, false
+	}
+	if !setting.PublicScoreboard {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusForbidden,
+			Message: service.ErrPublicScoreboardDisabled.Error(),
+		})
+		h.log.WarnContext(ctx, "LiveScoreboardHandler public scoreboard is disabled")
+		return nil, false
+	}
+	return &model.LiveScoreboardHello{
+		CompetitionID: param.CompetitionID,
+		Mode:          model.LiveScoreboardModePublic,
+	}, true
+}
+
+func (h *CompetitionHandler) serveLiveScoreboard(ctx context.Context, ws *websocket.Conn, param *model.LiveScoreboardParam, hello *model.LiveScoreboardHello) {
+	start := time.Now()
+	mode := string(hello.Mode)
+	liveScoreboardActiveConnections.WithLabelValues(mode).Inc()
+	liveScoreboardConnectionsTotal.WithLabelValues(mode, "open").Inc()
+
+	closeReason := "closed"
+	defer func() {
+		_ = ws.Close()
+		liveScoreboardActiveConnections.WithLabelValues(mode).Dec()
+		liveScoreboardConnectionsTotal.WithLabelValues(mode, closeReason).Inc()
+		liveScoreboardConnectionDurationSeconds.WithLabelValues(mode, closeReason).Observe(time.Since(start).Seconds())
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 实时排行榜只向客户端推送, 读取客户端消息仅用于感知连接断开
+	go func() {
+		defer cancel()
+		var discard string
+		for {
+			if err := websocket.Message.Receive(ws, &discard); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(typ model.LiveScoreboardMessageType, data any) bool {
+		msgBytes, err := json.Marshal(&model.LiveScoreboardMessage{Type: typ, Data: data})
+		if err != nil {
+			h.log.ErrorContext(ctx, "LiveScoreboardHandler marshal message failed", logger.Error(err))
+			return true
+		}
+		_ = ws.SetWriteDeadline(time.Now().Add(liveScoreboardWriteTimeout))
+		if err = websocket.Message.Send(ws, string(msgBytes)); err != nil {
+			h.log.InfoContext(ctx, "LiveScoreboardHandler send message failed", logger.Error(err))
+			return false
+		}
+		liveScoreboardMessagesTotal.WithLabelValues(string(typ)).Inc()
+		return true
+	}
+	sendClock := func() bool {
+		clock, err := h.liveClock(ctx, param.CompetitionID)
+		if err != nil {
+			h.log.ErrorContext(ctx, "LiveScoreboardHandler get clock failed", logger.Error(err))
+			return true
+		}
+		return send(model.LiveScoreboardMessageClock, clock)
+	}
+
+	// 先订阅再读取历史公告, 避免连接期间发布的公告丢失, 客户端按公告 ID 去重
+	announcementCh := h.competitionSvc.SubscribeCompetitionAnnouncement(ctx, param.CompetitionID)
+	rankingCh := h.rankingSvc.SubscribeRankingDiff(ctx, param.CompetitionID, param.Version)
+	endCh := h.competitionSvc.SubscribeCompetitionEndEvent(ctx, param.CompetitionID)
+
+	if !send(model.LiveScoreboardMessageHello, hello) || !sendClock() {
+		closeReason = "send_failed"
+		return
+	}
+	announcements, err := h.competitionSvc.GetCompetitionAnnouncementList(ctx, param.CompetitionID)
+	if err != nil {
+		h.log.ErrorContext(ctx, "GetCompetitionAnnouncementList failed", logger.Error(err))
+	}
+	for i := range announcements {
+		if !send(model.LiveScoreboardMessageAnnouncement, &announcements[i]) {
+			closeReason = "send_failed"
+			return
+		}
+	}
+
+	ticker := time.NewTicker(liveScoreboardClockInterval)
+	defer ticker.Stop()
+	for {
+		ok := true
+		select {
+		case <-ctx.Done():
+			closeReason = "client_closed"
+			return
+		case diff, chOk := <-rankingCh:
+			if !chOk {
+				closeReason = "event_channel_closed"
+				send(model.LiveScoreboardMessageClose, nil)
+				return
+			}
+			// 展示模式可能在比赛中调整, 每次推送前重新获取; 无法确定展示模式时不推送, 避免泄露选手信息
+			display, err := h.rankingSvc.GetRankingDisplay(ctx, param.CompetitionID, hello.Mode == model.LiveScoreboardModePublic)
+			if err != nil {
+				h.log.ErrorContext(ctx, "GetRankingDisplay failed", logger.Error(err))
+				continue
+			}
+			display.ApplyRankingDiff(diff)
+			ok = send(model.LiveScoreboardMessageRanking, diff)
+		case announcement, chOk := <-announcementCh:
+			if !chOk {
+				closeReason = "event_channel_closed"
+				send(model.LiveScoreboardMessageClose, nil)
+				return
+			}
+			ok = send(model.LiveScoreboardMessageAnnouncement, announcement)
+		case _, chOk := <-endCh:
+			// 比赛结束后仍需推送揭榜与定榜带来的排行榜变化, 只停止监听结束事件
+			if !chOk {
+				endCh = nil
+			}
+			ok = sendClock()
+		case <-ticker.C:
+			ok = sendClock()
+		}
+		if !ok {
+			closeReason = "send_failed"
+			return
+		}
+	}
+}
+
+// liveClock 获取比赛时钟
+func (h *CompetitionHandler) liveClock(ctx context.Context, competitionID uint64) (*model.LiveClock, error) {
+	competition, err := h.competitionSvc.GetCompetition(ctx, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := h.lifecycleSvc.GetCompetitionState(ctx, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	clock := &model.LiveClock{
+		ServerTime: now,
+		StartTime:  competition.StartTime,
+		EndTime:    competition.EndTime,
+		State:      state,
+	}
+	if now.Before(competition.EndTime) {
+		clock.Remaining = int64(competition.EndTime.Sub(now).Seconds())
+	}
+	return clock, nil
+}
@@ -0,0 +1,142 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/pkg/textdiff"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// plagiarismErrorCode 将查重相关错误映射为响应码
+func plagiarismErrorCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrCompetitionProblemNotFound),
+		errors.Is(err, service.ErrPlagiarismTaskNotFound),
+		errors.Is(err, service.ErrPlagiarismPairNotFound),
+		errors.Is(err, service.ErrCompetitionUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPlagiarismTaskRunning):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrPlagiarismUserNotInPair), errors.Is(err, service.ErrPlagiarismPairNotConfirmed):
+		return http.StatusBadRequest
+	case errors.Is(err, textdiff.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CreatePlagiarismTask 创建比赛题目的查重任务, 任务在后台执行, 通过 GetPlagiarismTaskList 查询进度
+func (h *CompetitionHandler) CreatePlagiarismTask(c *gin.Context, param *model.CreatePlagiarismTaskParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("problem_id", param.ProblemID))
+
+	taskID, err := h.plagiarismSvc.CreatePlagiarismTask(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    plagiarismErrorCode(err),
+			Message: fmt.Sprintf("CreatePlagiarismTask failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "CreatePlagiarismTask failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    model.CreatePlagiarismTaskResponse{TaskID: taskID},
+	})
+}
+
+func (h *CompetitionHandler) GetPlagiarismTaskList(c *gin.Context, param *model.GetPlagiarismTaskListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	list, err := h.plagiarismSvc.GetPlagiarismTaskList(ctx, param.CompetitionID, param.ProblemID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetPlagiarismTaskList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetPlagiarismTaskList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetPlagiarismTaskListResponse{
+			List:  list,
+			Total: len(list),
+		},
+	})
+}
+
+func (h *CompetitionHandler) GetPlagiarismPairList(c *gin.Context, param *model.GetPlagiarismPairListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("task_id", param.TaskID))
+
+	list, total, err := h.plagiarismSvc.GetPlagiarismPairList(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetPlagiarismPairList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetPlagiarismPairList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetPlagiarismPairListResponse{
+			Total:    total,
+			List:     list,
+			Page:     param.Page,
+			PageSize: param.PageSize,
+		},
+	})
+}
+
+func (h *CompetitionHandler) GetPlagiarismPairDiff(c *gin.Context, param *model.GetPlagiarismPairDiffParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("pair_id", param.PairID))
+
+	resp, err := h.plagiarismSvc.GetPlagiarismPairDiff(ctx, param.PairID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    plagiarismErrorCode(err),
+			Message: fmt.Sprintf("GetPlagiarismPairDiff failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetPlagiarismPairDiff failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    resp,
+	})
+}
+
+// ReviewPlagiarismPair 复核可疑代码对, 确认抄袭时可通过 disqualify_user_ids 直接取消相关选手的比赛资格
+func (h *CompetitionHandler) ReviewPlagiarismPair(c *gin.Context, param *model.ReviewPlagiarismPairParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("pair_id", param.PairID),
+		logger.Int8("status", int8(param.Status)))
+
+	err := h.plagiarismSvc.ReviewPlagiarismPair(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    plagiarismErrorCode(err),
+			Message: fmt.Sprintf("ReviewPlagiarismPair failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "ReviewPlagiarismPair failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
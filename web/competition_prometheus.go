@@ -144,6 +144,97 @@ var (
 			Help:      "TimeEventHandler active connections.",
 		},
 	)
+	rankingEventConnectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "competition",
+			Name:      "ranking_event_connections_total",
+			Help:      "RankingEventHandler connections total.",
+		},
+		[]string{"reason"},
+	)
+	rankingEventConnectionDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "competition",
+			Name:      "ranking_event_connection_duration_seconds",
+			Help:      "RankingEventHandler connection duration in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"reason"},
+	)
+	rankingEventActiveConnections = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "competition",
+			Name:      "ranking_event_active_connections",
+			Help:      "RankingEventHandler active connections.",
+		},
+	)
+	firstBloodEventConnectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "competition",
+			Name:      "first_blood_event_connections_total",
+			Help:      "FirstBloodEventHandler connections total.",
+		},
+		[]string{"reason"},
+	)
+	firstBloodEventConnectionDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "competition",
+			Name:      "first_blood_event_connection_duration_seconds",
+			Help:      "FirstBloodEventHandler connection duration in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"reason"},
+	)
+	firstBloodEventActiveConnections = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "competition",
+			Name:      "first_blood_event_active_connections",
+			Help:      "FirstBloodEventHandler active connections.",
+		},
+	)
+	liveScoreboardConnectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "competition",
+			Name:      "live_scoreboard_connections_total",
+			Help:      "LiveScoreboardHandler connections total.",
+		},
+		[]string{"mode", "reason"},
+	)
+	liveScoreboardConnectionDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "competition",
+			Name:      "live_scoreboard_connection_duration_seconds",
+			Help:      "LiveScoreboardHandler connection duration in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"mode", "reason"},
+	)
+	liveScoreboardActiveConnections = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "competition",
+			Name:      "live_scoreboard_active_connections",
+			Help:      "LiveScoreboardHandler active connections.",
+		},
+		[]string{"mode"},
+	)
+	liveScoreboardMessagesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "competition",
+			Name:      "live_scoreboard_messages_total",
+			Help:      "LiveScoreboardHandler messages sent total.",
+		},
+		[]string{"type"},
+	)
 )
 
 func init() {
@@ -163,5 +254,15 @@ func init() {
 		timeEventConnectionsTotal,
 		timeEventConnectionDurationSeconds,
 		timeEventActiveConnections,
+		rankingEventConnectionsTotal,
+		rankingEventConnectionDurationSeconds,
+		rankingEventActiveConnections,
+		firstBloodEventConnectionsTotal,
+		firstBloodEventConnectionDurationSeconds,
+		firstBloodEventActiveConnections,
+		liveScoreboardConnectionsTotal,
+		liveScoreboardConnectionDurationSeconds,
+		liveScoreboardActiveConnections,
+		liveScoreboardMessagesTotal,
 	)
 }
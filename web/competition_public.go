@@ -0,0 +1,45 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// publicRankingErrorCode 将公开排行榜相关错误映射为响应码
+func publicRankingErrorCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrPublicScoreboardDisabled):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetPublicCompetitionRankingList 公开排行榜, 无需比赛 token, 供家长、教练与其他班级观看
+func (h *CompetitionHandler) GetPublicCompetitionRankingList(c *gin.Context, param *model.GetPublicCompetitionRankingListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	resp, err := h.rankingSvc.GetPublicCompetitionRankingList(ctx, param.CompetitionID, param.Page, param.PageSize, param.Category)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    publicRankingErrorCode(err),
+			Message: fmt.Sprintf("GetPublicCompetitionRankingList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetPublicCompetitionRankingList failed", logger.Error(err))
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=10")
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    resp,
+	})
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+func (h *CompetitionHandler) GetCompetitionRankingDiff(c *gin.Context, param *model.GetCompetitionRankingDiffParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Int64("version", param.Version))
+
+	diff, err := h.rankingSvc.GetCompetitionRankingDiff(ctx, param.CompetitionID, param.Version)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionRankingDiff failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionRankingDiff failed", logger.Error(err))
+		return
+	}
+	display, err := h.rankingSvc.GetRankingDisplay(ctx, param.CompetitionID, false)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionRankingDiff failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetRankingDisplay failed", logger.Error(err))
+		return
+	}
+	display.ApplyRankingDiff(diff)
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    diff,
+	})
+}
+
+// RankingEventHandler 通过 SSE 推送排行榜差异, 每条事件为 JSON 格式的 model.RankingDiff
+func (h *CompetitionHandler) RankingEventHandler(c *gin.Context, param *model.RankingEventParam) chan string {
+	start := time.Now()
+	rankingEventActiveConnections.Inc()
+	rankingEventConnectionsTotal.WithLabelValues("open").Inc()
+
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Int64("version", param.Version),
+	)
+
+	ch := make(chan string, 1)
+	diffCh := h.rankingSvc.SubscribeRankingDiff(ctx, param.CompetitionID, param.Version)
+	go func() {
+		closeReason := "closed"
+		defer close(ch)
+		defer func() {
+			rankingEventActiveConnections.Dec()
+			rankingEventConnectionDurationSeconds.WithLabelValues(closeReason).Observe(time.Since(start).Seconds())
+		}()
+		for {
+			select {
+			case <-c.Done():
+				h.log.InfoContext(c.Request.Context(), "RankingEventHandler client closed")
+				closeReason = "client_closed"
+				rankingEventConnectionsTotal.WithLabelValues("client_closed").Inc()
+				return
+			case diff, ok := <-diffCh:
+				if !ok {
+					h.log.InfoContext(c.Request.Context(), "RankingEventHandler ranking diff channel closed")
+					closeReason = "event_channel_closed"
+					rankingEventConnectionsTotal.WithLabelValues("event_channel_closed").Inc()
+					return
+				}
+				display, err := h.rankingSvc.GetRankingDisplay(ctx, param.CompetitionID, false)
+				if err != nil {
+					h.log.ErrorContext(ctx, "RankingEventHandler get ranking display failed", logger.Error(err))
+					continue
+				}
+				display.ApplyRankingDiff(diff)
+				diffBytes, err := json.Marshal(diff)
+				if err != nil {
+					h.log.ErrorContext(ctx, "RankingEventHandler marshal ranking diff failed", logger.Error(err))
+					continue
+				}
+				ch <- string(diffBytes)
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,87 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// GetCompetitionProblemStatistics 管理员查看比赛各题的实时统计, 不受封榜影响
+func (h *CompetitionHandler) GetCompetitionProblemStatistics(c *gin.Context, param *model.GetCompetitionProblemStatisticsParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	list, err := h.rankingSvc.GetCompetitionProblemStatistics(ctx, param.CompetitionID, true)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionProblemStatistics failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionProblemStatistics failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetCompetitionProblemStatisticsResponse{
+			List:  list,
+			Total: len(list),
+		},
+	})
+}
+
+// UserGetCompetitionProblemStatistics 选手查看比赛各题统计, 封榜期间返回封榜时的统计
+func (h *CompetitionHandler) UserGetCompetitionProblemStatistics(c *gin.Context, param *model.UserGetCompetitionProblemStatisticsParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	list, err := h.rankingSvc.GetCompetitionProblemStatistics(ctx, param.CompetitionID, false)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("UserGetCompetitionProblemStatistics failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "UserGetCompetitionProblemStatistics failed", logger.Error(err))
+		return
+	}
+	display, err := h.rankingSvc.GetRankingDisplay(ctx, param.CompetitionID, false)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("UserGetCompetitionProblemStatistics failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetRankingDisplay failed", logger.Error(err))
+		return
+	}
+	display.ApplyStatistics(list)
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetCompetitionProblemStatisticsResponse{
+			List:  list,
+			Total: len(list),
+		},
+	})
+}
+
+// RebuildCompetitionProblemStatistics 从 MySQL 重新计算比赛各题统计, 用于判题服务直接修改数据后的修复
+func (h *CompetitionHandler) RebuildCompetitionProblemStatistics(c *gin.Context, param *model.RebuildCompetitionProblemStatisticsParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	err := h.rankingSvc.RebuildCompetitionProblemStatistics(ctx, param.CompetitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("RebuildCompetitionProblemStatistics failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "RebuildCompetitionProblemStatistics failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
@@ -0,0 +1,194 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// templateErrorCode 将比赛复制与模板相关错误映射为响应码
+func templateErrorCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrCompetitionNotFound), errors.Is(err, service.ErrCompetitionTemplateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrProblemUnavailable):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *CompetitionHandler) CloneCompetition(c *gin.Context, param *model.CloneCompetitionParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.String("start_time", param.StartTime.GoString()))
+	h.log.DebugContext(ctx, "CloneCompetition param")
+
+	competitionID, err := h.competitionSvc.CloneCompetition(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    templateErrorCode(err),
+			Message: fmt.Sprintf("CloneCompetition failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "CloneCompetition failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.CloneCompetitionResponse{
+			CompetitionID: competitionID,
+		},
+	})
+}
+
+func (h *CompetitionHandler) GetCompetitionSetting(c *gin.Context, param *model.GetCompetitionSettingParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	setting, err := h.competitionSvc.GetCompetitionSetting(ctx, param.CompetitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionSetting failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionSetting failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    setting,
+	})
+}
+
+func (h *CompetitionHandler) UpdateCompetitionSetting(c *gin.Context, param *model.UpdateCompetitionSettingParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	// 比赛开始后修改罚时会影响已有排名, 仅允许在开始前修改; 公开排行榜等展示设置可随时修改
+	if param.PenaltyMinutes != nil {
+		err := h.lifecycleSvc.CheckCompetitionEditable(ctx, param.CompetitionID, model.CompetitionFieldSetting)
+		if err != nil {
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    lifecycleErrorCode(err),
+				Message: fmt.Sprintf("UpdateCompetitionSetting failed: %s", err.Error()),
+			})
+			h.log.ErrorContext(ctx, "UpdateCompetitionSetting CheckCompetitionEditable failed", logger.Error(err))
+			return
+		}
+	}
+
+	err := h.competitionSvc.UpdateCompetitionSetting(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("UpdateCompetitionSetting failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "UpdateCompetitionSetting failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *CompetitionHandler) SaveCompetitionTemplate(c *gin.Context, param *model.SaveCompetitionTemplateParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.String("name", param.Name))
+
+	err := h.templateSvc.SaveCompetitionTemplate(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    templateErrorCode(err),
+			Message: fmt.Sprintf("SaveCompetitionTemplate failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "SaveCompetitionTemplate failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *CompetitionHandler) GetCompetitionTemplateList(c *gin.Context, param *model.GetCompetitionTemplateListParam) {
+	fields := []logger.Field{
+		logger.Int("page", param.Page),
+		logger.Int("page_size", param.PageSize),
+	}
+	if param.Name != "" {
+		fields = append(fields, logger.String("name", param.Name))
+	}
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), fields...)
+	h.log.DebugContext(ctx, "GetCompetitionTemplateList param")
+
+	templates, total, err := h.templateSvc.GetCompetitionTemplateList(ctx, param.Name, param.Page, param.PageSize)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionTemplateList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionTemplateList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetCompetitionTemplateListResponse{
+			List:     templates,
+			Total:    total,
+			Page:     param.Page,
+			PageSize: param.PageSize,
+		},
+	})
+}
+
+func (h *CompetitionHandler) DeleteCompetitionTemplate(c *gin.Context, param *model.DeleteCompetitionTemplateParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("template_id", param.TemplateID))
+
+	err := h.templateSvc.DeleteCompetitionTemplate(ctx, param.TemplateID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    templateErrorCode(err),
+			Message: fmt.Sprintf("DeleteCompetitionTemplate failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "DeleteCompetitionTemplate failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *CompetitionHandler) CreateCompetitionFromTemplate(c *gin.Context, param *model.CreateCompetitionFromTemplateParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("template_id", param.TemplateID),
+		logger.String("start_time", param.StartTime.GoString()))
+	h.log.DebugContext(ctx, "CreateCompetitionFromTemplate param")
+
+	competitionID, err := h.templateSvc.CreateCompetitionFromTemplate(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    templateErrorCode(err),
+			Message: fmt.Sprintf("CreateCompetitionFromTemplate failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "CreateCompetitionFromTemplate failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.CloneCompetitionResponse{
+			CompetitionID: competitionID,
+		},
+	})
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// GetCompetitionUpsolveRankingList 包含赛后补题的排行榜, 会暴露封榜后的结果, 仅在定榜后开放
+func (h *CompetitionHandler) GetCompetitionUpsolveRankingList(c *gin.Context, param *model.GetCompetitionUpsolveRankingListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	if !h.checkCompetitionFinalized(c, param.CompetitionID, "upsolve ranking") {
+		return
+	}
+
+	rankingList, total, err := h.rankingSvc.GetCompetitionUpsolveRankingList(ctx, param.CompetitionID, param.Page, param.PageSize, param.Category)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCompetitionNotFound) {
+			code = http.StatusNotFound
+		}
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    code,
+			Message: fmt.Sprintf("GetCompetitionUpsolveRankingList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionUpsolveRankingList failed", logger.Error(err))
+		return
+	}
+	display, err := h.rankingSvc.GetRankingDisplay(ctx, param.CompetitionID, false)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionUpsolveRankingList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetRankingDisplay failed", logger.Error(err))
+		return
+	}
+	display.ApplyRankings(rankingList)
+	problems, err := h.competitionSvc.UserGetCompetitionProblemList(ctx, param.CompetitionID)
+	if err != nil {
+		h.log.WarnContext(ctx, "GetCompetitionUpsolveRankingList get problem list failed", logger.Error(err))
+	}
+
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetCompetitionUpsolveRankingListResponse{
+			Problems: problems,
+			List:     rankingList,
+			Total:    total,
+			Page:     param.Page,
+			PageSize: param.PageSize,
+		},
+	})
+}
@@ -114,3 +114,21 @@ func (h *RedisJWTHandler) GetUserClaims(ctx *gin.Context) (*CompetitionClaims, e
 	}
 	return &uc, nil
 }
+
+// ParseCompetitionToken 校验比赛 token 并返回其中的选手信息, 用于不经过 CheckCompetition 中间件的接口
+func (h *RedisJWTHandler) ParseCompetitionToken(ctx *gin.Context, tokenStr string) (*CompetitionClaims, error) {
+	var uc CompetitionClaims
+	token, err := jwt.ParseWithClaims(tokenStr, &uc, func(t *jwt.Token) (any, error) {
+		return h.jwtKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || !token.Valid {
+		return nil, errors.New("token invalid")
+	}
+	if err = h.CheckSession(ctx, uc.Ssid); err != nil {
+		return nil, err
+	}
+	return &uc, nil
+}
@@ -13,6 +13,7 @@ type Handler interface {
 
 	JwtKey() []byte
 	GetUserClaims(ctx *gin.Context) (*CompetitionClaims, error)
+	ParseCompetitionToken(ctx *gin.Context, tokenStr string) (*CompetitionClaims, error)
 }
 
 type CompetitionClaims struct {
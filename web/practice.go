@@ -0,0 +1,300 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	ojmodel "github.com/to404hanga/online_judge_common/model"
+	"github.com/to404hanga/online_judge_controller/constants"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// PracticeHandler 比赛之外的练习, 使用普通用户身份而不是比赛 token, 提交的 competition_id 为 0
+type PracticeHandler struct {
+	practiceSvc   service.PracticeService
+	submissionSvc service.SubmissionService
+	languageSvc   service.LanguageService
+	log           loggerv2.Logger
+}
+
+var _ Handler = (*PracticeHandler)(nil)
+
+func NewPracticeHandler(practiceSvc service.PracticeService, submissionSvc service.SubmissionService, languageSvc service.LanguageService, log loggerv2.Logger) *PracticeHandler {
+	return &PracticeHandler{
+		practiceSvc:   practiceSvc,
+		submissionSvc: submissionSvc,
+		languageSvc:   languageSvc,
+		log:           log,
+	}
+}
+
+func (h *PracticeHandler) Register(r *gin.Engine) {
+	r.GET(constants.GetPracticeProblemListPath, gintool.WrapHandler(h.GetPracticeProblemList, h.log))
+	r.GET(constants.GetPracticeProblemPath, gintool.WrapHandler(h.GetPracticeProblem, h.log))
+	r.POST(constants.SubmitPracticeProblemPath, gintool.WrapHandler(h.SubmitPracticeProblem, h.log))
+	r.GET(constants.GetPracticeSubmissionListPath, gintool.WrapHandler(h.GetPracticeSubmissionList, h.log))
+	r.GET(constants.GetPracticeSubmissionDetailPath, gintool.WrapHandler(h.GetPracticeSubmissionDetail, h.log))
+	r.GET(constants.GetPracticeRankingListPath, gintool.WrapHandler(h.GetPracticeRankingList, h.log))
+}
+
+// practiceErrorCode 将练习相关错误映射为响应码
+func practiceErrorCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrPracticeProblemNotFound), errors.Is(err, service.ErrSubmissionNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *PracticeHandler) GetPracticeProblemList(c *gin.Context, param *model.GetPracticeProblemListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("operator", param.Operator))
+
+	list, total, err := h.practiceSvc.GetPracticeProblemList(ctx, param.Operator, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetPracticeProblemList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetPracticeProblemList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetPracticeProblemListResponse{
+			List:     list,
+			Total:    total,
+			Page:     param.Page,
+			PageSize: param.PageSize,
+		},
+	})
+}
+
+func (h *PracticeHandler) GetPracticeProblem(c *gin.Context, param *model.GetPracticeProblemParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("problem_id", param.ProblemID))
+
+	problem, err := h.practiceSvc.GetPracticeProblem(ctx, param.ProblemID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    practiceErrorCode(err),
+			Message: fmt.Sprintf("GetPracticeProblem failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetPracticeProblem failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    problem,
+	})
+}
+
+// SubmitPracticeProblem 提交练习题目, 与比赛提交共用语言限制、判题中检查与提交流程, 不计入任何比赛排行榜
+func (h *PracticeHandler) SubmitPracticeProblem(c *gin.Context, param *model.SubmitPracticeProblemParam) {
+	code := http.StatusOK
+	reason := "ok"
+	defer func() {
+		submitPracticeProblemRequestsTotal.WithLabelValues(strconv.Itoa(code), reason).Inc()
+	}()
+
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("problem_id", param.ProblemID),
+		logger.Int8("language", param.Language))
+
+	_, err := h.practiceSvc.GetPracticeProblem(ctx, param.ProblemID)
+	if err != nil {
+		code = practiceErrorCode(err)
+		reason = "practice_problem_not_found"
+		if code == http.StatusInternalServerError {
+			reason = "get_practice_problem_error"
+			h.log.ErrorContext(ctx, "SubmitPracticeProblem failed", logger.Error(err))
+		}
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    code,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err = h.languageSvc.CheckSubmitLimit(ctx, model.PracticeCompetitionID, param.ProblemID, param.Language, len(param.Code))
+	if err != nil {
+		code = submitLimitErrorCode(err)
+		switch code {
+		case http.StatusBadRequest:
+			reason = "language_not_allowed"
+		case http.StatusRequestEntityTooLarge:
+			reason = "code_too_large"
+		default:
+			reason = "check_submit_limit_error"
+			h.log.ErrorContext(ctx, "SubmitPracticeProblem failed", logger.Error(err))
+		}
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    code,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	latestSubmission, err := h.submissionSvc.GetLatestSubmission(ctx, model.PracticeCompetitionID, param.ProblemID, param.Operator)
+	if err != nil {
+		code = http.StatusInternalServerError
+		reason = "get_latest_submission_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		h.log.ErrorContext(ctx, "SubmitPracticeProblem failed", logger.Error(err))
+		return
+	}
+	// 如果最近的一次提交还没判题完毕, 禁止提交
+	if latestSubmission != nil && latestSubmission.ID != 0 && *latestSubmission.Status != ojmodel.SubmissionStatusJudged {
+		code = http.StatusForbidden
+		reason = "latest_submission_not_judged"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusForbidden,
+			Message: "You have submitted this problem, please wait for the result",
+		})
+		return
+	}
+
+	limit, err := h.submissionSvc.TakeSubmitToken(ctx, model.PracticeCompetitionID, param.ProblemID, param.Operator)
+	if err != nil {
+		code = http.StatusInternalServerError
+		reason = "take_submit_token_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		h.log.ErrorContext(ctx, "SubmitPracticeProblem failed", logger.Error(err))
+		return
+	}
+	if limit.RetryAfter > 0 {
+		code = http.StatusTooManyRequests
+		reason = "rate_limited"
+		retryAfter := int64(math.Ceil(limit.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusTooManyRequests,
+			Message: fmt.Sprintf("提交过于频繁, 请在 %d 秒后重试", retryAfter),
+			Data: model.SubmitRateLimitedResponse{
+				RetryAfter: limit.RetryAfter.Milliseconds(),
+				Scope:      limit.Scope,
+			},
+		})
+		return
+	}
+
+	// 接下来需要调用其他服务，ctx 携带 request_id 进行传递
+	ctx = context.WithValue(ctx, "request_id", c.GetHeader(constants.HeaderRequestIDKey))
+	submitParam := &model.SubmitCompetitionProblemParam{
+		Code:      param.Code,
+		Language:  param.Language,
+		ProblemID: param.ProblemID,
+	}
+	submitParam.SetOperator(param.Operator)
+	submitParam.SetCompetitionID(model.PracticeCompetitionID)
+	err = h.submissionSvc.SubmitCompetitionProblem(ctx, submitParam)
+	if err != nil {
+		code = http.StatusInternalServerError
+		reason = "submit_practice_problem_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		h.log.ErrorContext(ctx, "SubmitPracticeProblem failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *PracticeHandler) GetPracticeSubmissionList(c *gin.Context, param *model.GetPracticeSubmissionListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("operator", param.Operator))
+
+	competitionID := model.PracticeCompetitionID
+	list, total, err := h.submissionSvc.GetSubmissionList(ctx, &model.GetSubmissionListParam{
+		CompetitionID: &competitionID,
+		UserID:        &param.Operator,
+		ProblemID:     param.ProblemID,
+		Page:          param.Page,
+		PageSize:      param.PageSize,
+	})
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetPracticeSubmissionList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetPracticeSubmissionList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetSubmissionListResponse{
+			Total:    total,
+			List:     list,
+			Page:     param.Page,
+			PageSize: param.PageSize,
+		},
+	})
+}
+
+// GetPracticeSubmissionDetail 查看自己的练习提交详情, 失败提交的代码可能已被清理, 此时 cleaned 为 true
+func (h *PracticeHandler) GetPracticeSubmissionDetail(c *gin.Context, param *model.GetPracticeSubmissionDetailParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("submission_id", param.SubmissionID))
+
+	detail, err := h.submissionSvc.GetSubmissionDetail(ctx, param.SubmissionID)
+	if err == nil && (detail.UserID != param.Operator || detail.CompetitionID != model.PracticeCompetitionID) {
+		// 不暴露他人提交是否存在
+		err = fmt.Errorf("GetPracticeSubmissionDetail failed: %w", service.ErrSubmissionNotFound)
+	}
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    practiceErrorCode(err),
+			Message: fmt.Sprintf("GetPracticeSubmissionDetail failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetPracticeSubmissionDetail failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    detail,
+	})
+}
+
+func (h *PracticeHandler) GetPracticeRankingList(c *gin.Context, param *model.GetPracticeRankingListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("operator", param.Operator))
+
+	list, total, err := h.practiceSvc.GetPracticeRankingList(ctx, param.Page, param.PageSize)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetPracticeRankingList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetPracticeRankingList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetPracticeRankingListResponse{
+			List:     list,
+			Total:    total,
+			Page:     param.Page,
+			PageSize: param.PageSize,
+		},
+	})
+}
@@ -1,14 +1,62 @@
 package web
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/event"
 )
 
+const shutdownTimeout = 30 * time.Second // 收到退出信号后等待进行中的请求完成的最长时间
+
 type GinServer struct {
-	Engine *gin.Engine
-	Addr   string
+	Engine    *gin.Engine
+	Addr      string
+	Consumers []event.Consumer
 }
 
+// Start 启动消费者与 HTTP 服务, 收到 SIGINT 或 SIGTERM 后停止接收请求, 等待进行中的请求与消息处理完成后返回
 func (s *GinServer) Start() error {
-	return s.Engine.Run(s.Addr)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, consumer := range s.Consumers {
+		if err := consumer.Start(ctx); err != nil {
+			return err
+		}
+	}
+
+	server := &http.Server{
+		Addr:    s.Addr,
+		Handler: s.Engine,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err = server.Shutdown(shutdownCtx)
+	}
+	stop()
+	for _, consumer := range s.Consumers {
+		if closeErr := consumer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
@@ -2,6 +2,8 @@ package web
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
@@ -22,15 +24,19 @@ const SubmissionBucket = "submission"
 type SubmissionHandler struct {
 	submissionSvc  service.SubmissionService
 	competitionSvc service.CompetitionService
+	lifecycleSvc   service.CompetitionLifecycleService
+	languageSvc    service.LanguageService
 	log            loggerv2.Logger
 }
 
 var _ Handler = (*SubmissionHandler)(nil)
 
-func NewSubmissionHandler(submissionSvc service.SubmissionService, competitionSvc service.CompetitionService, log loggerv2.Logger) *SubmissionHandler {
+func NewSubmissionHandler(submissionSvc service.SubmissionService, competitionSvc service.CompetitionService, lifecycleSvc service.CompetitionLifecycleService, languageSvc service.LanguageService, log loggerv2.Logger) *SubmissionHandler {
 	return &SubmissionHandler{
 		submissionSvc:  submissionSvc,
 		competitionSvc: competitionSvc,
+		lifecycleSvc:   lifecycleSvc,
+		languageSvc:    languageSvc,
 		log:            log,
 	}
 }
@@ -38,6 +44,23 @@ func NewSubmissionHandler(submissionSvc service.SubmissionService, competitionSv
 func (h *SubmissionHandler) Register(r *gin.Engine) {
 	r.POST(constants.SubmitCompetitionProblemPath, gintool.WrapCompetitionHandler(h.SubmitCompetitionProblem, h.log))
 	r.GET(constants.GetLatestSubmissionPath, gintool.WrapCompetitionHandler(h.GetLatestSubmission, h.log))
+	r.GET(constants.GetSubmissionListPath, gintool.WrapHandler(h.GetSubmissionList, h.log))
+	r.GET(constants.GetSubmissionDetailPath, gintool.WrapHandler(h.GetSubmissionDetail, h.log))
+	r.GET(constants.DownloadSubmissionCodePath, gintool.WrapHandler(h.DownloadSubmissionCode, h.log))
+	r.GET(constants.UserGetSubmissionListPath, gintool.WrapCompetitionHandler(h.UserGetSubmissionList, h.log))
+	r.GET(constants.UserGetSubmissionDetailPath, gintool.WrapCompetitionHandler(h.UserGetSubmissionDetail, h.log))
+	r.GET(constants.GetLanguageListPath, gintool.WrapHandler(h.GetLanguageList, h.log))
+	r.PUT(constants.SaveLanguagePath, gintool.WrapHandler(h.SaveLanguage, h.log))
+	r.GET(constants.GetCompetitionProblemSubmitSettingPath, gintool.WrapHandler(h.GetCompetitionProblemSubmitSetting, h.log))
+	r.PUT(constants.UpdateCompetitionProblemSubmitSettingPath, gintool.WrapHandler(h.UpdateCompetitionProblemSubmitSetting, h.log))
+	r.GET(constants.UserGetSubmitLimitPath, gintool.WrapCompetitionHandler(h.UserGetSubmitLimit, h.log))
+	r.POST(constants.CreateCustomRunPath, gintool.WrapCompetitionHandler(h.CreateCustomRun, h.log))
+	r.GET(constants.GetCustomRunPath, gintool.WrapCompetitionHandler(h.GetCustomRun, h.log))
+	r.GET(constants.GetJudgeReportPath, gintool.WrapHandler(h.GetJudgeReport, h.log))
+	r.GET(constants.UserGetJudgeReportPath, gintool.WrapCompetitionHandler(h.UserGetJudgeReport, h.log))
+	r.GET(constants.GetSubmissionDiffPath, gintool.WrapHandler(h.GetSubmissionDiff, h.log))
+	r.GET(constants.UserGetSubmissionDiffPath, gintool.WrapCompetitionHandler(h.UserGetSubmissionDiff, h.log))
+	r.GET(constants.CustomRunEventPath, gintool.WrapCompetitionSSEHandler(h.CustomRunEventHandler, h.log, time.Second*10))
 }
 
 func (h *SubmissionHandler) SubmitCompetitionProblem(c *gin.Context, param *model.SubmitCompetitionProblemParam) {
@@ -67,12 +90,60 @@ func (h *SubmissionHandler) SubmitCompetitionProblem(c *gin.Context, param *mode
 		h.log.ErrorContext(ctx, "SubmitCompetitionProblem failed", logger.Error(err))
 		return
 	}
+	if ok {
+		// 比赛时间来自缓存, 管理员提前结束或撤回比赛时以比赛状态为准
+		state, err := h.lifecycleSvc.GetCompetitionState(ctx, param.CompetitionID)
+		if err != nil {
+			code = http.StatusInternalServerError
+			reason = "get_competition_state_error"
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			})
+			h.log.ErrorContext(ctx, "SubmitCompetitionProblem failed", logger.Error(err))
+			return
+		}
+		ok = state.AcceptsSubmission()
+	}
 	if !ok {
-		code = http.StatusForbidden
-		reason = "not_in_competition_time"
+		// 比赛结束后允许补题时按赛后补题提交处理, 不计入正式排行榜
+		param.Upsolve, err = h.competitionSvc.CheckCompetitionUpsolve(ctx, param.CompetitionID)
+		if err != nil {
+			code = http.StatusInternalServerError
+			reason = "check_competition_upsolve_error"
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			})
+			h.log.ErrorContext(ctx, "SubmitCompetitionProblem failed", logger.Error(err))
+			return
+		}
+		if !param.Upsolve {
+			code = http.StatusForbidden
+			reason = "not_in_competition_time"
+			gintool.GinResponse(c, &gintool.Response{
+				Code:    http.StatusForbidden,
+				Message: "不在比赛时间内, 禁止提交",
+			})
+			return
+		}
+	}
+
+	err = h.languageSvc.CheckSubmitLimit(ctx, param.CompetitionID, param.ProblemID, param.Language, len(param.Code))
+	if err != nil {
+		code = submitLimitErrorCode(err)
+		switch code {
+		case http.StatusBadRequest:
+			reason = "language_not_allowed"
+		case http.StatusRequestEntityTooLarge:
+			reason = "code_too_large"
+		default:
+			reason = "check_submit_limit_error"
+			h.log.ErrorContext(ctx, "SubmitCompetitionProblem failed", logger.Error(err))
+		}
 		gintool.GinResponse(c, &gintool.Response{
-			Code:    http.StatusForbidden,
-			Message: "不在比赛时间内, 禁止提交",
+			Code:    code,
+			Message: err.Error(),
 		})
 		return
 	}
@@ -99,6 +170,56 @@ func (h *SubmissionHandler) SubmitCompetitionProblem(c *gin.Context, param *mode
 		return
 	}
 
+	duplicateMode, duplicate, err := h.submissionSvc.CheckDuplicateSubmission(ctx, param)
+	if err != nil {
+		code = http.StatusInternalServerError
+		reason = "check_duplicate_submission_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		h.log.ErrorContext(ctx, "SubmitCompetitionProblem failed", logger.Error(err))
+		return
+	}
+	if duplicate != nil && duplicateMode == model.DuplicateSubmitModeReject {
+		code = http.StatusConflict
+		reason = "duplicate_submission"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("代码与提交 %d 相同, 禁止重复提交", duplicate.SubmissionID),
+			Data:    model.SubmitCompetitionProblemResponse{Duplicate: duplicate},
+		})
+		return
+	}
+
+	limit, err := h.submissionSvc.TakeSubmitToken(ctx, param.CompetitionID, param.ProblemID, param.Operator)
+	if err != nil {
+		code = http.StatusInternalServerError
+		reason = "take_submit_token_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		h.log.ErrorContext(ctx, "SubmitCompetitionProblem failed", logger.Error(err))
+		return
+	}
+	if limit.RetryAfter > 0 {
+		code = http.StatusTooManyRequests
+		reason = "rate_limited"
+		retryAfter := int64(math.Ceil(limit.RetryAfter.Seconds()))
+		submitRateLimitedTotal.WithLabelValues(strconv.FormatUint(param.CompetitionID, 10), limit.Scope).Inc()
+		c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusTooManyRequests,
+			Message: fmt.Sprintf("提交过于频繁, 请在 %d 秒后重试", retryAfter),
+			Data: model.SubmitRateLimitedResponse{
+				RetryAfter: limit.RetryAfter.Milliseconds(),
+				Scope:      limit.Scope,
+			},
+		})
+		return
+	}
+
 	// 接下来需要调用其他服务，ctx 携带 request_id 进行传递
 	ctx = context.WithValue(ctx, "request_id", c.GetHeader(constants.HeaderRequestIDKey))
 	err = h.submissionSvc.SubmitCompetitionProblem(ctx, param)
@@ -113,9 +234,19 @@ func (h *SubmissionHandler) SubmitCompetitionProblem(c *gin.Context, param *mode
 		return
 	}
 
+	// 提示模式下仍然提交, 在响应中告知选手代码与之前的提交相同
+	if duplicate != nil {
+		reason = "duplicate_submission_warned"
+	} else if param.Upsolve {
+		reason = "upsolve"
+	}
 	gintool.GinResponse(c, &gintool.Response{
 		Code:    http.StatusOK,
 		Message: "success",
+		Data: model.SubmitCompetitionProblemResponse{
+			Duplicate: duplicate,
+			Upsolve:   param.Upsolve,
+		},
 	})
 }
 
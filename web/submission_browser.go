@@ -0,0 +1,157 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/pkg/textdiff"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// submissionErrorCode 将提交查询相关错误映射为响应码
+func submissionErrorCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrSubmissionNotFound), errors.Is(err, service.ErrJudgeReportNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrSubmissionCodeCleaned):
+		return http.StatusGone
+	case errors.Is(err, textdiff.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetSubmissionList 管理员按比赛、用户、题目、语言、结果与时间范围查询提交列表
+func (h *SubmissionHandler) GetSubmissionList(c *gin.Context, param *model.GetSubmissionListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("operator", param.Operator))
+
+	list, total, err := h.submissionSvc.GetSubmissionList(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetSubmissionList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetSubmissionList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetSubmissionListResponse{
+			Total:    total,
+			List:     list,
+			Page:     param.Page,
+			PageSize: param.PageSize,
+		},
+	})
+}
+
+// GetSubmissionDetail 管理员查看提交详情, 包含代码、错误输出、运行时间与内存
+func (h *SubmissionHandler) GetSubmissionDetail(c *gin.Context, param *model.GetSubmissionDetailParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("submission_id", param.SubmissionID))
+
+	detail, err := h.submissionSvc.GetSubmissionDetail(ctx, param.SubmissionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    submissionErrorCode(err),
+			Message: fmt.Sprintf("GetSubmissionDetail failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetSubmissionDetail failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    detail,
+	})
+}
+
+// DownloadSubmissionCode 管理员以附件形式下载提交的源代码, 代码已被清理时返回 410
+func (h *SubmissionHandler) DownloadSubmissionCode(c *gin.Context, param *model.DownloadSubmissionCodeParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("submission_id", param.SubmissionID))
+
+	detail, err := h.submissionSvc.GetSubmissionDetail(ctx, param.SubmissionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    submissionErrorCode(err),
+			Message: fmt.Sprintf("DownloadSubmissionCode failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "DownloadSubmissionCode failed", logger.Error(err))
+		return
+	}
+	if detail.Cleaned {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusGone,
+			Message: "代码已被清理",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("%d_%d_%d%s", detail.CompetitionID, detail.UserID, detail.ID, model.SubmissionFileExt(detail.Language))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(detail.Code))
+}
+
+// UserGetSubmissionList 选手查询自己在比赛中的全部提交, 可按题目筛选
+func (h *SubmissionHandler) UserGetSubmissionList(c *gin.Context, param *model.UserGetSubmissionListParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("competition_id", param.CompetitionID))
+
+	list, total, err := h.submissionSvc.GetSubmissionList(ctx, &model.GetSubmissionListParam{
+		CompetitionID: &param.CompetitionID,
+		UserID:        &param.Operator,
+		ProblemID:     param.ProblemID,
+		Page:          param.Page,
+		PageSize:      param.PageSize,
+	})
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("UserGetSubmissionList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "UserGetSubmissionList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetSubmissionListResponse{
+			Total:    total,
+			List:     list,
+			Page:     param.Page,
+			PageSize: param.PageSize,
+		},
+	})
+}
+
+// UserGetSubmissionDetail 选手查看自己的提交详情, 失败提交的代码可能已被清理, 此时 cleaned 为 true
+func (h *SubmissionHandler) UserGetSubmissionDetail(c *gin.Context, param *model.UserGetSubmissionDetailParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("submission_id", param.SubmissionID))
+
+	detail, err := h.submissionSvc.GetSubmissionDetail(ctx, param.SubmissionID)
+	if err == nil && (detail.UserID != param.Operator || detail.CompetitionID != param.CompetitionID) {
+		// 不暴露他人提交是否存在
+		err = fmt.Errorf("UserGetSubmissionDetail failed: %w", service.ErrSubmissionNotFound)
+	}
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    submissionErrorCode(err),
+			Message: fmt.Sprintf("UserGetSubmissionDetail failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "UserGetSubmissionDetail failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    detail,
+	})
+}
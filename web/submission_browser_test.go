@@ -0,0 +1,32 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/to404hanga/online_judge_controller/pkg/textdiff"
+	"github.com/to404hanga/online_judge_controller/service"
+)
+
+func TestSubmissionErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"提交不存在", fmt.Errorf("GetSubmissionDetail failed: %w", service.ErrSubmissionNotFound), http.StatusNotFound},
+		{"判题报告不存在", service.ErrJudgeReportNotFound, http.StatusNotFound},
+		{"代码已清理", fmt.Errorf("diffSubmission failed: %w", service.ErrSubmissionCodeCleaned), http.StatusGone},
+		{"代码超过对比上限", fmt.Errorf("diffSubmission failed: %w", fmt.Errorf("%w: 2001 lines", textdiff.ErrTooLarge)), http.StatusRequestEntityTooLarge},
+		{"其他错误", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := submissionErrorCode(tt.err); got != tt.want {
+				t.Errorf("submissionErrorCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
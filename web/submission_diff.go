@@ -0,0 +1,61 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// GetSubmissionDiff 管理员对比两次提交的代码, 任一代码已被清理时返回 410, 代码超过对比上限时返回 413
+func (h *SubmissionHandler) GetSubmissionDiff(c *gin.Context, param *model.GetSubmissionDiffParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("left_submission_id", param.LeftSubmissionID),
+		logger.Uint64("right_submission_id", param.RightSubmissionID))
+
+	diff, err := h.submissionSvc.GetSubmissionDiff(ctx, param.LeftSubmissionID, param.RightSubmissionID, &param.SubmissionDiffOption)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    submissionErrorCode(err),
+			Message: fmt.Sprintf("GetSubmissionDiff failed: %s", err.Error()),
+		})
+		if submissionErrorCode(err) == http.StatusInternalServerError {
+			h.log.ErrorContext(ctx, "GetSubmissionDiff failed", logger.Error(err))
+		}
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    diff,
+	})
+}
+
+// UserGetSubmissionDiff 选手对比自己在比赛中的两次提交, 任一代码已被清理时返回 410, 代码超过对比上限时返回 413
+func (h *SubmissionHandler) UserGetSubmissionDiff(c *gin.Context, param *model.UserGetSubmissionDiffParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("left_submission_id", param.LeftSubmissionID),
+		logger.Uint64("right_submission_id", param.RightSubmissionID))
+
+	diff, err := h.submissionSvc.UserGetSubmissionDiff(ctx, param.CompetitionID, param.Operator, param.LeftSubmissionID, param.RightSubmissionID, &param.SubmissionDiffOption)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    submissionErrorCode(err),
+			Message: fmt.Sprintf("UserGetSubmissionDiff failed: %s", err.Error()),
+		})
+		if submissionErrorCode(err) == http.StatusInternalServerError {
+			h.log.ErrorContext(ctx, "UserGetSubmissionDiff failed", logger.Error(err))
+		}
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    diff,
+	})
+}
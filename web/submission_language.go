@@ -0,0 +1,130 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// submitLimitErrorCode 将提交限制相关错误映射为响应码
+func submitLimitErrorCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrLanguageNotAllowed):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrCodeTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *SubmissionHandler) GetLanguageList(c *gin.Context, param *model.GetLanguageListParam) {
+	ctx := c.Request.Context()
+
+	list, err := h.languageSvc.GetLanguageList(ctx)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetLanguageList failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetLanguageList failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data: model.GetLanguageListResponse{
+			List:  list,
+			Total: len(list),
+		},
+	})
+}
+
+// SaveLanguage 新增或覆盖语言定义, 语言编号需与判题服务一致
+func (h *SubmissionHandler) SaveLanguage(c *gin.Context, param *model.SaveLanguageParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Int8("language", *param.ID))
+
+	err := h.languageSvc.SaveLanguage(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("SaveLanguage failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "SaveLanguage failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *SubmissionHandler) GetCompetitionProblemSubmitSetting(c *gin.Context, param *model.GetCompetitionProblemSubmitSettingParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("problem_id", param.ProblemID))
+
+	setting, err := h.languageSvc.GetCompetitionProblemSubmitSetting(ctx, param.CompetitionID, param.ProblemID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetCompetitionProblemSubmitSetting failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionProblemSubmitSetting failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    setting,
+	})
+}
+
+func (h *SubmissionHandler) UpdateCompetitionProblemSubmitSetting(c *gin.Context, param *model.UpdateCompetitionProblemSubmitSettingParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("problem_id", param.ProblemID))
+
+	err := h.languageSvc.UpdateCompetitionProblemSubmitSetting(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("UpdateCompetitionProblemSubmitSetting failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "UpdateCompetitionProblemSubmitSetting failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+// UserGetSubmitLimit 选手获取题目允许的语言与代码大小上限
+func (h *SubmissionHandler) UserGetSubmitLimit(c *gin.Context, param *model.UserGetSubmitLimitParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("problem_id", param.ProblemID))
+
+	limit, err := h.languageSvc.GetSubmitLimit(ctx, param.CompetitionID, param.ProblemID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("UserGetSubmitLimit failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "UserGetSubmitLimit failed", logger.Error(err))
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    limit,
+	})
+}
@@ -22,6 +22,51 @@ var (
 		},
 		[]string{"code", "reason", "language"},
 	)
+	submitRateLimitedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "submission",
+			Name:      "submit_rate_limited_total",
+			Help:      "SubmitCompetitionProblem requests rejected by rate limit.",
+		},
+		[]string{"competition_id", "scope"},
+	)
+	submitPracticeProblemRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "submission",
+			Name:      "submit_practice_problem_requests_total",
+			Help:      "SubmitPracticeProblem requests total.",
+		},
+		[]string{"code", "reason"},
+	)
+	customRunRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "submission",
+			Name:      "custom_run_requests_total",
+			Help:      "CreateCustomRun requests total.",
+		},
+		[]string{"code", "reason"},
+	)
+	customRunRateLimitedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "submission",
+			Name:      "custom_run_rate_limited_total",
+			Help:      "CreateCustomRun requests rejected by rate limit.",
+		},
+		[]string{"competition_id", "scope"},
+	)
+	customRunEventDurationSeconds = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "online_judge_controller",
+			Subsystem: "submission",
+			Name:      "custom_run_event_duration_seconds",
+			Help:      "CustomRunEvent SSE connection duration in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		},
+	)
 	getLatestSubmissionRequestsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "online_judge_controller",
@@ -47,6 +92,11 @@ func init() {
 	prometheus.MustRegister(
 		submitCompetitionProblemRequestsTotal,
 		submitCompetitionProblemDurationSeconds,
+		submitRateLimitedTotal,
+		submitPracticeProblemRequestsTotal,
+		customRunRequestsTotal,
+		customRunRateLimitedTotal,
+		customRunEventDurationSeconds,
 		getLatestSubmissionRequestsTotal,
 		getLatestSubmissionDurationSeconds,
 	)
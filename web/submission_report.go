@@ -0,0 +1,60 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// GetJudgeReport 管理员查看提交的完整判题报告
+func (h *SubmissionHandler) GetJudgeReport(c *gin.Context, param *model.GetJudgeReportParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("submission_id", param.SubmissionID))
+
+	report, err := h.submissionSvc.GetJudgeReport(ctx, param.SubmissionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    submissionErrorCode(err),
+			Message: fmt.Sprintf("GetJudgeReport failed: %s", err.Error()),
+		})
+		if !errors.Is(err, service.ErrJudgeReportNotFound) {
+			h.log.ErrorContext(ctx, "GetJudgeReport failed", logger.Error(err))
+		}
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    report,
+	})
+}
+
+// UserGetJudgeReport 选手查看自己提交的判题报告, 可见范围由比赛设置决定
+func (h *SubmissionHandler) UserGetJudgeReport(c *gin.Context, param *model.UserGetJudgeReportParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("submission_id", param.SubmissionID))
+
+	report, err := h.submissionSvc.UserGetJudgeReport(ctx, param.CompetitionID, param.Operator, param.SubmissionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    submissionErrorCode(err),
+			Message: fmt.Sprintf("UserGetJudgeReport failed: %s", err.Error()),
+		})
+		if submissionErrorCode(err) == http.StatusInternalServerError {
+			h.log.ErrorContext(ctx, "UserGetJudgeReport failed", logger.Error(err))
+		}
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    report,
+	})
+}
@@ -0,0 +1,191 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/constants"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/online_judge_controller/service"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+// customRunErrorCode 将自定义输入运行相关错误映射为响应码
+func customRunErrorCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrCustomRunNotFound), errors.Is(err, service.ErrCompetitionProblemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrCustomRunStdinSize):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CreateCustomRun 使用自定义输入运行代码, 不计入提交次数, 结果通过 GetCustomRun 轮询或 CustomRunEvent 订阅
+func (h *SubmissionHandler) CreateCustomRun(c *gin.Context, param *model.CreateCustomRunParam) {
+	code := http.StatusOK
+	reason := "ok"
+	defer func() {
+		customRunRequestsTotal.WithLabelValues(strconv.Itoa(code), reason).Inc()
+	}()
+
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.Uint64("problem_id", param.ProblemID),
+		logger.Int8("language", param.Language))
+
+	ok, err := h.competitionSvc.CheckCompetitionTime(ctx, param.CompetitionID)
+	if err != nil {
+		code = http.StatusInternalServerError
+		reason = "check_competition_time_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		h.log.ErrorContext(ctx, "CreateCustomRun failed", logger.Error(err))
+		return
+	}
+	if !ok {
+		code = http.StatusForbidden
+		reason = "not_in_competition_time"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusForbidden,
+			Message: "不在比赛时间内, 禁止运行",
+		})
+		return
+	}
+
+	err = h.languageSvc.CheckSubmitLimit(ctx, param.CompetitionID, param.ProblemID, param.Language, len(param.Code))
+	if err != nil {
+		code = submitLimitErrorCode(err)
+		switch code {
+		case http.StatusBadRequest:
+			reason = "language_not_allowed"
+		case http.StatusRequestEntityTooLarge:
+			reason = "code_too_large"
+		default:
+			reason = "check_submit_limit_error"
+			h.log.ErrorContext(ctx, "CreateCustomRun failed", logger.Error(err))
+		}
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    code,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	limit, err := h.submissionSvc.TakeRunToken(ctx, param.CompetitionID, param.Operator)
+	if err != nil {
+		code = http.StatusInternalServerError
+		reason = "take_run_token_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		h.log.ErrorContext(ctx, "CreateCustomRun failed", logger.Error(err))
+		return
+	}
+	if limit.RetryAfter > 0 {
+		code = http.StatusTooManyRequests
+		reason = "rate_limited"
+		retryAfter := int64(math.Ceil(limit.RetryAfter.Seconds()))
+		customRunRateLimitedTotal.WithLabelValues(strconv.FormatUint(param.CompetitionID, 10), limit.Scope).Inc()
+		c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusTooManyRequests,
+			Message: fmt.Sprintf("运行过于频繁, 请在 %d 秒后重试", retryAfter),
+			Data: model.SubmitRateLimitedResponse{
+				RetryAfter: limit.RetryAfter.Milliseconds(),
+				Scope:      limit.Scope,
+			},
+		})
+		return
+	}
+
+	ctx = context.WithValue(ctx, "request_id", c.GetHeader(constants.HeaderRequestIDKey))
+	runID, err := h.submissionSvc.CreateCustomRun(ctx, param)
+	if err != nil {
+		code = customRunErrorCode(err)
+		reason = "create_custom_run_error"
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    code,
+			Message: fmt.Sprintf("CreateCustomRun failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "CreateCustomRun failed", logger.Error(err))
+		return
+	}
+
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    model.CreateCustomRunResponse{RunID: runID},
+	})
+}
+
+func (h *SubmissionHandler) GetCustomRun(c *gin.Context, param *model.GetCustomRunParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.String("run_id", param.RunID))
+
+	run, err := h.submissionSvc.GetCustomRun(ctx, param.CompetitionID, param.Operator, param.RunID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    customRunErrorCode(err),
+			Message: fmt.Sprintf("GetCustomRun failed: %s", err.Error()),
+		})
+		if !errors.Is(err, service.ErrCustomRunNotFound) {
+			h.log.ErrorContext(ctx, "GetCustomRun failed", logger.Error(err))
+		}
+		return
+	}
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    run,
+	})
+}
+
+// CustomRunEventHandler 通过 SSE 推送自定义输入运行的状态变化, 每条事件为 JSON 格式的 model.CustomRun, 运行完成后关闭
+func (h *SubmissionHandler) CustomRunEventHandler(c *gin.Context, param *model.CustomRunEventParam) chan string {
+	start := time.Now()
+	ctx := loggerv2.ContextWithFields(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.String("run_id", param.RunID))
+
+	ch := make(chan string, 1)
+	runCh := h.submissionSvc.SubscribeCustomRun(ctx, param.CompetitionID, param.Operator, param.RunID)
+	go func() {
+		defer close(ch)
+		defer func() {
+			customRunEventDurationSeconds.Observe(time.Since(start).Seconds())
+		}()
+		for {
+			select {
+			case <-c.Done():
+				h.log.InfoContext(c.Request.Context(), "CustomRunEventHandler client closed")
+				return
+			case run, ok := <-runCh:
+				if !ok {
+					return
+				}
+				runBytes, err := json.Marshal(run)
+				if err != nil {
+					h.log.ErrorContext(ctx, "CustomRunEventHandler marshal custom run failed", logger.Error(err))
+					continue
+				}
+				ch <- string(runBytes)
+			}
+		}
+	}()
+	return ch
+}
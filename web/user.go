@@ -47,6 +47,10 @@ func (h *UserHandler) Register(r *gin.Engine) {
 	r.PUT(constants.UpdatePasswordPath, gintool.WrapHandler(h.UpdatePassword, h.log))
 	r.GET(constants.GetCompetitionUserListPath, gintool.WrapHandler(h.GetCompetitionUserList, h.log))
 	r.POST(constants.CreateUserPath, gintool.WrapHandler(h.CreateUser, h.log))
+	r.PUT(constants.SetCompetitionUserCategoryPath, gintool.WrapHandler(h.SetCompetitionUserCategory, h.log))
+	r.PUT(constants.UpdateUserNicknamePath, gintool.WrapHandler(h.UpdateUserNickname, h.log))
+	r.GET(constants.GetUserStatisticsPath, gintool.WrapHandler(h.GetUserStatistics, h.log))
+	r.GET(constants.UserGetStatisticsPath, gintool.WrapCompetitionHandler(h.UserGetStatistics, h.log))
 }
 
 func (h *UserHandler) GetUserList(c *gin.Context, param *model.GetUserListParam) {
@@ -256,7 +260,7 @@ func (h *UserHandler) AddUsersToCompetition(c *gin.Context, param *model.AddUser
 		return
 	}
 
-	rowsAffected, err := h.userSvc.AddUsersToCompetition(ctx, param.CompetitionID, userMap, competition.StartTime)
+	rowsAffected, err := h.userSvc.AddUsersToCompetition(ctx, param.CompetitionID, userMap, competition.StartTime, param.Category, param.Unofficial)
 	if err != nil {
 		gintool.GinResponse(c, &gintool.Response{
 			Code:    http.StatusInternalServerError,
@@ -518,6 +522,9 @@ func (h *UserHandler) GetCompetitionUserList(c *gin.Context, param *model.GetCom
 	if param.Status != nil {
 		fields = append(fields, logger.Int8("status", param.Status.Int8()))
 	}
+	if param.Category != nil {
+		fields = append(fields, logger.String("category", *param.Category))
+	}
 
 	ctx := loggerv2.WithFieldsToContext(c.Request.Context(), fields...)
 	h.log.DebugContext(ctx, "GetCompetitionUserList")
@@ -531,13 +538,30 @@ func (h *UserHandler) GetCompetitionUserList(c *gin.Context, param *model.GetCom
 		h.log.ErrorContext(ctx, "GetCompetitionUserList failed", logger.Error(err))
 		return
 	}
+	categoryMap, err := h.userSvc.GetCompetitionUserCategories(ctx, param.CompetitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "internal error",
+		})
+		h.log.ErrorContext(ctx, "GetCompetitionUserList get user category failed", logger.Error(err))
+		return
+	}
+	itemList := transform.SliceFromSlice(userList, func(i int, user ojmodel.CompetitionUser) model.CompetitionUserItem {
+		category := categoryMap[user.UserID]
+		return model.CompetitionUserItem{
+			CompetitionUser: user,
+			Category:        category.Category,
+			Unofficial:      category.Unofficial,
+		}
+	})
 
 	gintool.GinResponse(c, &gintool.Response{
 		Code:    http.StatusOK,
 		Message: "success",
 		Data: model.GetCompetitionUserListResponse{
 			Total:    total,
-			List:     userList,
+			List:     itemList,
 			Page:     param.Page,
 			PageSize: param.PageSize,
 		},
@@ -566,3 +590,66 @@ func (h *UserHandler) CreateUser(c *gin.Context, param *model.CreateUserParam) {
 		Message: "success",
 	})
 }
+
+func (h *UserHandler) SetCompetitionUserCategory(c *gin.Context, param *model.SetCompetitionUserCategoryParam) {
+	ctx := loggerv2.WithFieldsToContext(c.Request.Context(),
+		logger.Uint64("competition_id", param.CompetitionID),
+		logger.String("category", param.Category),
+		logger.Bool("unofficial", param.Unofficial),
+	)
+
+	exist, err := h.checkCompetitionExist(ctx, param.CompetitionID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		h.log.ErrorContext(ctx, "SetCompetitionUserCategory check competition exist failed", logger.Error(err))
+		return
+	}
+	if !exist {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusBadRequest,
+			Message: "competition not found",
+		})
+		h.log.ErrorContext(ctx, "SetCompetitionUserCategory competition not found")
+		return
+	}
+
+	err = h.userSvc.SetCompetitionUserCategory(ctx, param)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "internal error",
+		})
+		h.log.ErrorContext(ctx, "SetCompetitionUserCategory failed", logger.Error(err))
+		return
+	}
+
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+func (h *UserHandler) UpdateUserNickname(c *gin.Context, param *model.UpdateUserNicknameParam) {
+	ctx := loggerv2.WithFieldsToContext(c.Request.Context(),
+		logger.Uint64("user_id", param.UserID),
+		logger.String("nickname", param.Nickname),
+	)
+
+	err := h.userSvc.UpdateUserNickname(ctx, param.UserID, param.Nickname)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "internal error",
+		})
+		h.log.ErrorContext(ctx, "UpdateUserNickname failed", logger.Error(err))
+		return
+	}
+
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
@@ -0,0 +1,53 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/online_judge_controller/model"
+	"github.com/to404hanga/online_judge_controller/pkg/gintool"
+	"github.com/to404hanga/pkg404/logger"
+	loggerv2 "github.com/to404hanga/pkg404/logger/v2"
+)
+
+func (h *UserHandler) GetUserStatistics(c *gin.Context, param *model.GetUserStatisticsParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("user_id", param.UserID))
+
+	statistics, err := h.userSvc.GetUserStatistics(ctx, param.UserID)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("GetUserStatistics failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "GetUserStatistics failed", logger.Error(err))
+		return
+	}
+
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    statistics,
+	})
+}
+
+// UserGetStatistics 选手获取自己在全部比赛中的提交统计
+func (h *UserHandler) UserGetStatistics(c *gin.Context, param *model.UserGetStatisticsParam) {
+	ctx := loggerv2.ContextWithFields(c.Request.Context(), logger.Uint64("user_id", param.Operator))
+
+	statistics, err := h.userSvc.GetUserStatistics(ctx, param.Operator)
+	if err != nil {
+		gintool.GinResponse(c, &gintool.Response{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("UserGetStatistics failed: %s", err.Error()),
+		})
+		h.log.ErrorContext(ctx, "UserGetStatistics failed", logger.Error(err))
+		return
+	}
+
+	gintool.GinResponse(c, &gintool.Response{
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    statistics,
+	})
+}